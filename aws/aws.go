@@ -2,22 +2,137 @@ package aws
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"iter"
 	"log"
 	"os"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go/middleware"
 )
 
 type (
 	Config struct {
 		Profile string
 		Region  string
+		// Optional endpoint used by every client instead of the AWS one, e.g.,
+		// "http://localhost:8000" for DynamoDB Local
+		Endpoint string
+		Retry    *RetryConfig // Optional, the SDK defaults are used when nil
+		// Optional hook receiving every request input, e.g., DebugJSON(os.Stderr)
+		Debug DebugHook
+		// Optional hook receiving the latency, retries, items and capacity of
+		// every request
+		Metrics MetricsHook
+		// Optional S3 storage for large DynamoDB attribute values
+		Offload *OffloadConfig
+		// Optional client-side encryption of DynamoDB attributes
+		Encryption *EncryptionConfig
+		// Optional signing or encryption of pagination cursors
+		Cursor *CursorConfig
+		// Optional read-through cache of DynamoDB gets and queries
+		Cache *CacheConfig
+		// Optional audit trail of DynamoDB puts, updates and deletes
+		Audit *AuditConfig
+		// Encoding of the items written by Put, BatchWrite, BulkWrite and
+		// TransactWrite, and of the keys, condition, update and statement
+		// values sent with them, so times match the stored ones
+		Marshal MarshalOptions
+		// Optional defaults and bandwidth cap of S3 transfers
+		Transfer *TransferConfig
 	}
 
 	DynamoDB interface {
-		Query(ctx context.Context, opts QueryOptions) ([]map[string]types.AttributeValue, error)
+		Table(name string) *QueryBuilder
+		Query(ctx context.Context, opts QueryOptions) (*QueryResult, error)
+		QueryPage(ctx context.Context, opts QueryOptions) (*QueryResult, error)
+		QueryAll(ctx context.Context, opts QueryOptions, maxItems int) (*QueryResult, error)
+		QueryMany(ctx context.Context, opts QueryOptions, partitionValues []any, many ...QueryManyOptions) (*QueryResult, error)
+		Explain(ctx context.Context, opts QueryOptions) (*QueryPlan, error)
+		Count(ctx context.Context, opts QueryOptions) (*CountResult, error)
+		QueryItems(ctx context.Context, opts QueryOptions) iter.Seq2[Item, error]
+		Scan(ctx context.Context, opts ScanOptions) (*ScanResult, error)
+		ParallelScan(ctx context.Context, opts ParallelScanOptions) (*ScanResult, error)
+		ScanStream(ctx context.Context, opts ScanStreamOptions) (<-chan Item, <-chan error)
+		Get(ctx context.Context, opts GetOptions) (*GetResult, error)
+		Put(ctx context.Context, table string, item any, opts ...PutOptions) (*PutResult, error)
+		Delete(ctx context.Context, opts DeleteOptions) (*DeleteResult, error)
+		Update(ctx context.Context, opts UpdateOptions) (*UpdateResult, error)
+		Increment(ctx context.Context, table string, key Key, field string, delta int64) (int64, error)
+		RemoveAttributes(ctx context.Context, table string, key Key, fields ...string) error
+		BatchGet(ctx context.Context, opts BatchGetOptions) (*BatchGetResult, error)
+		BatchWrite(ctx context.Context, opts BatchWriteOptions) (*BatchWriteResult, error)
+		BulkWrite(ctx context.Context, opts BulkWriteOptions) (*BatchWriteResult, error)
+		CopyTable(ctx context.Context, src, dst string, opts ...CopyTableOptions) (*CopyTableResult, error)
+		DiffTables(ctx context.Context, a, b string, opts ...DiffTablesOptions) (*TableDiff, error)
+		TransactGet(ctx context.Context, opts TransactGetOptions) (*TransactGetResult, error)
+		TransactWrite(ctx context.Context, opts TransactWriteOptions) (*TransactWriteResult, error)
+		ExecuteStatement(ctx context.Context, opts ExecuteStatementOptions) (*StatementResult, error)
+		BatchExecuteStatement(ctx context.Context, statements []Statement) ([]BatchStatementResult, error)
+		ExecuteTransaction(ctx context.Context, statements []Statement) ([]Item, error)
+		CreateTable(ctx context.Context, opts CreateTableOptions) (*TableDescription, error)
+		DeleteTable(ctx context.Context, table string) error
+		Truncate(ctx context.Context, table string) (int, error)
+		DescribeTable(ctx context.Context, table string) (*TableDescription, error)
+		UpdateTable(ctx context.Context, opts UpdateTableOptions) (*TableDescription, error)
+		TableExists(ctx context.Context, table string) (bool, error)
+		WaitForTableActive(ctx context.Context, table string) (*TableDescription, error)
+		WaitForIndexActive(ctx context.Context, table, index string) error
+		CreateTableFromSchema(ctx context.Context, schema TableSchema) (*TableDescription, error)
+		VerifySchema(ctx context.Context, schema TableSchema) error
+		CreateBackup(ctx context.Context, table, name string) (*Backup, error)
+		ListBackups(ctx context.Context, opts ListBackupsOptions) ([]Backup, error)
+		RestoreTableFromBackup(ctx context.Context, opts RestoreTableOptions) (*TableDescription, error)
+		EnablePointInTimeRecovery(ctx context.Context, table string) error
+		DisablePointInTimeRecovery(ctx context.Context, table string) error
+		PointInTimeRecoveryStatus(ctx context.Context, table string) (*PointInTimeRecovery, error)
+		ExportTable(ctx context.Context, opts ExportTableOptions) (*Export, error)
+		DescribeExport(ctx context.Context, exportARN string) (*Export, error)
+		WaitForExport(ctx context.Context, exportARN string) (*Export, error)
+		ImportTable(ctx context.Context, opts ImportTableOptions) (*Import, error)
+		DescribeImport(ctx context.Context, importARN string) (*Import, error)
+		WaitForImport(ctx context.Context, importARN string) (*Import, error)
+		LoadFromS3(ctx context.Context, opts LoadOptions) (*LoadResult, error)
+		Seed(ctx context.Context, fixtures Fixtures, opts ...SeedOptions) (*SeedResult, error)
+		AddReplica(ctx context.Context, table, region string) error
+		RemoveReplica(ctx context.Context, table, region string) error
+		Replicas(ctx context.Context, table string) ([]Replica, error)
+		WaitForReplicas(ctx context.Context, table string) ([]Replica, error)
+		EnableTTL(ctx context.Context, table, attribute string) error
+		DisableTTL(ctx context.Context, table, attribute string) error
+	}
+
+	S3 interface {
+		CreateBucket(ctx context.Context, opts CreateBucketOptions) error
+		BucketExists(ctx context.Context, bucket string) (bool, error)
+		SetLifecycle(ctx context.Context, bucket string, rules []LifecycleRule) error
+		Lifecycle(ctx context.Context, bucket string) ([]LifecycleRule, error)
+		SetNotifications(ctx context.Context, bucket string, notifications []BucketNotification) error
+		Notifications(ctx context.Context, bucket string) ([]BucketNotification, error)
+		Put(ctx context.Context, opts PutObjectOptions) (*PutObjectResult, error)
+		Get(ctx context.Context, bucket, key string) (*Object, error)
+		Head(ctx context.Context, bucket, key string) (*ObjectInfo, error)
+		Delete(ctx context.Context, bucket, key string) error
+		Copy(ctx context.Context, opts CopyObjectOptions) (*CopyObjectResult, error)
+		Move(ctx context.Context, opts CopyObjectOptions) (*CopyObjectResult, error)
+		Tags(ctx context.Context, bucket, key string) (map[string]string, error)
+		SetTags(ctx context.Context, bucket, key string, tags map[string]string) error
+		SetMetadata(ctx context.Context, bucket, key string, metadata map[string]string) error
+		DeleteObjects(ctx context.Context, bucket string, keys []string) (*DeleteObjectsResult, error)
+		DeletePrefix(ctx context.Context, bucket, prefix string) (*DeleteObjectsResult, error)
+		List(ctx context.Context, bucket, prefix string, opts ...ListOptions) iter.Seq2[ObjectInfo, error]
+		ListPage(ctx context.Context, opts ListPageOptions) (*ListPageResult, error)
+		Upload(ctx context.Context, bucket, key string, body io.Reader, opts ...UploadOptions) (*UploadResult, error)
+		Download(ctx context.Context, bucket, key string, w io.Writer) (int64, error)
+		DownloadRange(ctx context.Context, bucket, key string, w io.Writer, offset, length int64) (int64, error)
+		GetRange(ctx context.Context, bucket, key string, offset, length int64) ([]byte, error)
+		Select(ctx context.Context, opts SelectOptions) iter.Seq2[json.RawMessage, error]
+		Sync(ctx context.Context, localDir, bucket, prefix string, opts ...SyncOptions) (*SyncResult, error)
+		PresignGet(ctx context.Context, bucket, key string, opts ...PresignOptions) (*PresignedRequest, error)
+		PresignPut(ctx context.Context, bucket, key string, opts ...PresignOptions) (*PresignedRequest, error)
+		PresignPost(ctx context.Context, bucket, key string, opts ...PresignPostOptions) (*PresignedPost, error)
 	}
 )
 
@@ -27,10 +142,23 @@ func load(config *Config) aws.Config {
 		os.Setenv("AWS_PROFILE", config.Profile)
 	}
 
-	cfg, err := awsconfig.LoadDefaultConfig(
-		context.TODO(),
+	opts := []func(*awsconfig.LoadOptions) error{
 		awsconfig.WithRegion(config.Region),
-	)
+	}
+	if config.Endpoint != "" {
+		opts = append(opts, awsconfig.WithBaseEndpoint(config.Endpoint))
+	}
+	if config.Retry != nil {
+		opts = append(opts, awsconfig.WithRetryer(config.Retry.retryer))
+	}
+	if config.Debug != nil {
+		opts = append(opts, awsconfig.WithAPIOptions([]func(*middleware.Stack) error{debugInputs(config.Debug)}))
+	}
+	if config.Metrics != nil {
+		opts = append(opts, awsconfig.WithAPIOptions([]func(*middleware.Stack) error{recordMetrics(config.Metrics)}))
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(context.TODO(), opts...)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,42 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// DebugHook receives the input of every request before it is sent, e.g.,
+// the *dynamodb.QueryInput of a Query, with the name of its operation.
+type DebugHook func(ctx context.Context, operation string, input any)
+
+// DebugJSON returns a hook writing each input to w as indented JSON.
+func DebugJSON(w io.Writer) DebugHook {
+	return func(_ context.Context, operation string, input any) {
+		out, err := json.MarshalIndent(input, "", "  ")
+		if err != nil {
+			fmt.Fprintf(w, "%s: %v\n", operation, err)
+			return
+		}
+		fmt.Fprintf(w, "%s: %s\n", operation, out)
+	}
+}
+
+// debugInputs returns an API option that passes the request inputs to the
+// hook.
+func debugInputs(hook DebugHook) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		operation := stack.ID()
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("Debug",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (
+				middleware.InitializeOutput, middleware.Metadata, error,
+			) {
+				hook(ctx, operation, in.Parameters)
+				return next.HandleInitialize(ctx, in)
+			},
+		), middleware.After)
+	}
+}
@@ -2,23 +2,44 @@ package aws
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"iter"
+	"slices"
+	"sync"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
-var defaultLimit = 100
+var (
+	defaultLimit    = 100
+	defaultMaxItems = 10000 // Safety cap of QueryAll
+	inLimit         = 100   // Max values of a single IN
+)
+
+// attributeTypes are the types accepted by the AttributeOfType operator.
+var attributeTypes = []expression.DynamoDBAttributeType{
+	expression.String, expression.StringSet, expression.Number, expression.NumberSet,
+	expression.Binary, expression.BinarySet, expression.Boolean, expression.Null,
+	expression.List, expression.Map,
+}
 
 const (
 	AND LogicalOperator = "AND"
 	OR  LogicalOperator = "OR"
 )
 
+const (
+	Ascending  SortOrder = "ASC"
+	Descending SortOrder = "DESC"
+)
+
 const (
 	Equal              WhereOperator = "="
 	NotEqual           WhereOperator = "!="
@@ -32,25 +53,58 @@ const (
 	BeginsWith         WhereOperator = "BEGINS_WITH"
 	AttributeExists    WhereOperator = "EXISTS"
 	AttributeNotExists WhereOperator = "NOT_EXISTS"
+	// Value is the type code, e.g., "S", "N", "L" or AttributeNumber
+	AttributeOfType WhereOperator = "ATTRIBUTE_TYPE"
+)
+
+const (
+	ReturnNone       ReturnValue = "NONE"
+	ReturnAllOld     ReturnValue = "ALL_OLD"
+	ReturnUpdatedOld ReturnValue = "UPDATED_OLD"
+	ReturnAllNew     ReturnValue = "ALL_NEW"
+	ReturnUpdatedNew ReturnValue = "UPDATED_NEW"
 )
 
 type (
+	// Item is a single DynamoDB item in its attribute value form
+	Item = map[string]types.AttributeValue
+
+	// Key identifies a single item by its partition and optional sort key,
+	// e.g., Key{"PK": "USER#1", "SK": "PROFILE"}
+	Key map[string]any
+
 	LogicalOperator string
 
 	QueryKeyValue struct {
 		Key      string
 		Value    any
-		Operator WhereOperator
+		Operator WhereOperator // Sort key only, defaults to Equal
+		// For BETWEEN operator, this holds the upper bound
+		Value2 any
 	}
 
 	QueryOptions struct {
 		Table     string
-		Index     string // GSI name, e.g., "YearGenreIndex"
-		Limit     int32  // Desired number of items per page
+		Index     string // Optional GSI/LSI name, e.g., "YearGenreIndex", queries the base table when empty
+		Limit     int32  // Desired number of items per page, defaults to 100
 		Cursor    string // Base64-encoded LastEvaluatedKey for pagination
 		Partition *QueryKeyValue
 		Sort      *QueryKeyValue
-		Where     *Where // Additional non-key filters
+		Where     *Where    // Additional non-key filters
+		Order     SortOrder // Sort key order, defaults to Ascending
+		Fields    []string  // Attributes to return, all when empty
+		// Encoding of time.Time key values, as in MarshalOptions, defaults to
+		// Config.Marshal.TimeFormat
+		TimeFormat TimeFormat
+		// Fetch the full items from the base table when the index only
+		// projects some attributes, e.g., KEYS_ONLY. Costs an extra read per
+		// item.
+		Hydrate bool
+		// Optional cap on the pages, scanned items and capacity read, failing
+		// with a *BudgetExceededError once reached
+		Budget *Budget
+		// Report the capacity used in the result
+		ReturnConsumedCapacity bool
 		// PartitionKey   string        // Partition key attribute, e.g., "year"
 		// PartitionValue any           // Value for partition key, e.g., 2020
 		// SortKey      string        // Optional: Sort key attribute, e.g., "genre"
@@ -58,12 +112,91 @@ type (
 		// SortValue    any           // Optional: Value for sort key, e.g., "Comedy"
 	}
 
+	QueryResult struct {
+		Items            []Item
+		LastEvaluatedKey Item              // Empty when there are no more pages
+		NextCursor       string            // Pass as QueryOptions.Cursor to read the next page
+		ConsumedCapacity *ConsumedCapacity // Set when ReturnConsumedCapacity is enabled
+		Count            int64             // Items returned, summed over the pages
+		ScannedCount     int64             // Items evaluated before the filters, summed over the pages
+		Pages            []PageCount       // Counts of each page read, in order
+	}
+
+	// PageCount is what the service reported for a single page. Comparing
+	// both counts shows how many items the filters discarded.
+	PageCount struct {
+		Count        int64 // Items matching the filters
+		ScannedCount int64 // Items evaluated before the filters were applied
+	}
+
+	CountResult struct {
+		Count            int64 // Items matching the key condition and filters
+		ScannedCount     int64 // Items evaluated before the filters were applied
+		ConsumedCapacity *ConsumedCapacity
+	}
+
+	ReturnValue string
+
+	SortOrder string
+
+	DeleteOptions struct {
+		Table        string
+		Key          Key
+		Condition    *Where      // Optional condition that must hold for the delete to succeed
+		ReturnValues ReturnValue // ReturnAllOld to get the deleted item back
+		// Report the capacity used in the result
+		ReturnConsumedCapacity bool
+	}
+
+	DeleteResult struct {
+		Attributes       Item // Populated based on DeleteOptions.ReturnValues, see Unmarshal
+		ConsumedCapacity *ConsumedCapacity
+	}
+
+	GetOptions struct {
+		Table          string
+		Key            Key
+		ConsistentRead bool
+		Fields         []string // Attributes to return, all when empty
+		// Report the capacity used in the result
+		ReturnConsumedCapacity bool
+	}
+
+	GetResult struct {
+		Item             Item
+		ConsumedCapacity *ConsumedCapacity
+	}
+
+	PutOptions struct {
+		// Optional condition that must hold for the write to succeed, e.g.,
+		// AttributeNotExists on the partition key for create-if-not-exists
+		Condition *Where
+		// Enables optimistic locking on this number attribute. The write only
+		// succeeds if the stored version matches the item's, and the version is
+		// incremented. Mismatches return DynamoDBErrVersionConflict.
+		VersionField string
+		// ReturnAllOld to get the replaced item back, if there was one
+		ReturnValues ReturnValue
+		// Reject items larger than this many bytes with an *ItemTooLargeError
+		// before sending them, e.g., ItemSizeLimit. Zero skips the check.
+		MaxItemSize int
+		// Report the capacity used in the result
+		ReturnConsumedCapacity bool
+	}
+
+	PutResult struct {
+		Attributes       Item  // Populated based on PutOptions.ReturnValues, see Unmarshal
+		Version          int64 // New version when VersionField is set
+		ConsumedCapacity *ConsumedCapacity
+	}
+
 	WhereOperator string
 
 	Where struct {
 		Conditions []WhereCondition
 		Groups     []Where
 		Operator   LogicalOperator
+		Not        bool // Negates the whole group
 	}
 
 	WhereCondition struct {
@@ -74,55 +207,705 @@ type (
 		Value2 any
 		// For IN operator, this should be a slice
 		Values []any
+		Not    bool // Negates the condition, e.g., NOT begins_with(...)
 	}
 )
 
 var (
-	DynamoDBErrBuildFilterExpression = errors.New("failed to build filter expression")
-	DynamoDBErrBuildUpdateExpression = errors.New("failed to build the update expression")
-	DynamoDBErrIndexNotSet           = errors.New("index not set")
-	DynamoDBErrQuery                 = errors.New("failed to perform query")
-	DynamoDBErrTableNotSet           = errors.New("table not set")
-	DynamoDBErrUnmarshal             = errors.New("failed to unmarshall items")
-	DynamoDBErrUpdateItem            = errors.New("failed to update item")
-	DynamoDBErrValueNotSet           = errors.New("key not set")
-	DynamoDBErrPartitionNotSet       = errors.New("partition not set")
+	DynamoDBErrBuildConditionExpression = errors.New("failed to build condition expression")
+	DynamoDBErrBuildFilterExpression    = errors.New("failed to build filter expression")
+	DynamoDBErrBuildProjection          = errors.New("failed to build projection expression")
+	DynamoDBErrBuildUpdateExpression    = errors.New("failed to build the update expression")
+	DynamoDBErrConditionFailed          = errors.New("condition check failed")
+	DynamoDBErrDeleteItem               = errors.New("failed to delete item")
+	DynamoDBErrEmptyValue               = errors.New("empty value not allowed")
+	DynamoDBErrGetItem                  = errors.New("failed to get item")
+	DynamoDBErrIndexNotSet              = errors.New("index not set")
+	DynamoDBErrItemNotFound             = errors.New("item not found")
+	DynamoDBErrItemNotSet               = errors.New("item not set")
+	DynamoDBErrMarshal                  = errors.New("failed to marshal item")
+	DynamoDBErrMaxItems                 = errors.New("more items than the max items")
+	DynamoDBErrPutItem                  = errors.New("failed to put item")
+	DynamoDBErrQuery                    = errors.New("failed to perform query")
+	DynamoDBErrReturnValues             = errors.New("return values not supported by the operation")
+	DynamoDBErrTableNotSet              = errors.New("table not set")
+	DynamoDBErrUnmarshal                = errors.New("failed to unmarshall items")
+	DynamoDBErrUpdateItem               = errors.New("failed to update item")
+	DynamoDBErrValueNotSet              = errors.New("key not set")
+	DynamoDBErrPartitionNotSet          = errors.New("partition not set")
 )
 
 type dynamodbService struct {
 	client *dynamodb.Client
+	s3     *s3.Client  // Reads S3 objects for LoadFromS3 and offloaded attributes
+	kms    *kms.Client // Data keys of encrypted items
+
+	offloadConfig *OffloadConfig
+	encryption    *EncryptionConfig
+	cursor        *CursorConfig
+	cache         *CacheConfig
+	audit         *AuditConfig
+	marshal       MarshalOptions
+
+	keys sync.Map // Key attribute names by table, see keyNames
 }
 
 func NewDynamoDB(config Config) DynamoDB {
-	awsConfig := load(&config)
-	client := dynamodb.NewFromConfig(awsConfig)
-	return &dynamodbService{client}
+	return newDynamoDBService(config, load(&config))
 }
 
-func (d *dynamodbService) Query(ctx context.Context, opts QueryOptions) ([]map[string]types.AttributeValue, error) {
-	// Validate
+func newDynamoDBService(config Config, awsConfig aws.Config) *dynamodbService {
+	cache := config.Cache
+	if cache != nil && cache.Cache == nil {
+		cache = &CacheConfig{Cache: NewMemoryCache(), TTL: cache.TTL}
+	}
+
+	return &dynamodbService{
+		client: dynamodb.NewFromConfig(awsConfig, func(o *dynamodb.Options) {
+			if config.Retry != nil {
+				o.APIOptions = append(o.APIOptions, config.Retry.operationRetries(o.Retryer))
+			}
+		}),
+		s3:            s3.NewFromConfig(awsConfig),
+		kms:           kms.NewFromConfig(awsConfig),
+		offloadConfig: config.Offload,
+		encryption:    config.Encryption,
+		cursor:        config.Cursor,
+		cache:         cache,
+		audit:         config.Audit,
+		marshal:       config.Marshal,
+	}
+}
+
+// Query reads up to Limit items matching the key condition and filters.
+func (d *dynamodbService) Query(ctx context.Context, opts QueryOptions) (*QueryResult, error) {
+	key := d.cacheKey(opts.Table, "Query", opts)
+	if result, ok := cachedResult[QueryResult](d, key); ok {
+		return result, nil
+	}
+
+	input, err := d.buildQueryInput(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = int32(defaultLimit)
+	}
+	input.Limit = aws.Int32(limit)
+	input.ReturnConsumedCapacity = capacityMode(opts.ReturnConsumedCapacity || opts.Budget.tracksCapacity())
+
+	// Keep reading pages until the limit is reached, asking only for the
+	// remaining items so the last evaluated key matches what is returned
+	result := &QueryResult{}
+	for {
+		input.Limit = aws.Int32(limit - int32(len(result.Items)))
+
+		if err := opts.Budget.check(); err != nil {
+			return nil, err
+		}
+		response, err := d.client.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", DynamoDBErrQuery, err)
+		}
+		if err := opts.Budget.spend(response.ScannedCount, response.ConsumedCapacity); err != nil {
+			return nil, err
+		}
+		if err := d.loadItems(ctx, opts.Table, response.Items...); err != nil {
+			return nil, err
+		}
+		items, capacity, err := d.hydrate(ctx, opts, response.Items)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Items = append(result.Items, items...)
+		result.LastEvaluatedKey = response.LastEvaluatedKey
+		result.ConsumedCapacity = addCapacity(result.ConsumedCapacity, consumed(response.ConsumedCapacity)...)
+		result.ConsumedCapacity = mergeCapacity(result.ConsumedCapacity, capacity)
+		result.addPage(response.Count, response.ScannedCount)
+
+		if len(response.LastEvaluatedKey) == 0 || int32(len(result.Items)) >= limit {
+			break
+		}
+		input.ExclusiveStartKey = response.LastEvaluatedKey
+	}
+
+	result.NextCursor, err = encodeCursor(result.LastEvaluatedKey, d.cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	storeResult(d, key, result)
+
+	return result, nil
+}
+
+// QueryPage sends a single query request and returns its page, which may
+// hold fewer than Limit items when filters drop some. Pass NextCursor back as
+// the cursor to read the next page.
+func (d *dynamodbService) QueryPage(ctx context.Context, opts QueryOptions) (*QueryResult, error) {
+	key := d.cacheKey(opts.Table, "QueryPage", opts)
+	if result, ok := cachedResult[QueryResult](d, key); ok {
+		return result, nil
+	}
+
+	input, err := d.buildQueryInput(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = int32(defaultLimit)
+	}
+	input.Limit = aws.Int32(limit)
+	input.ReturnConsumedCapacity = capacityMode(opts.ReturnConsumedCapacity || opts.Budget.tracksCapacity())
+
+	if err := opts.Budget.check(); err != nil {
+		return nil, err
+	}
+	response, err := d.client.Query(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrQuery, err)
+	}
+	if err := opts.Budget.spend(response.ScannedCount, response.ConsumedCapacity); err != nil {
+		return nil, err
+	}
+	if err := d.loadItems(ctx, opts.Table, response.Items...); err != nil {
+		return nil, err
+	}
+	items, capacity, err := d.hydrate(ctx, opts, response.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &QueryResult{
+		Items:            items,
+		LastEvaluatedKey: response.LastEvaluatedKey,
+		ConsumedCapacity: addCapacity(nil, consumed(response.ConsumedCapacity)...),
+	}
+	result.ConsumedCapacity = mergeCapacity(result.ConsumedCapacity, capacity)
+	result.addPage(response.Count, response.ScannedCount)
+	result.NextCursor, err = encodeCursor(response.LastEvaluatedKey, d.cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	storeResult(d, key, result)
+
+	return result, nil
+}
+
+// QueryAll reads every page of the query, with Limit as the page size. To
+// avoid loading a whole partition by mistake, reading stops after maxItems
+// items (10000 when zero) and DynamoDBErrMaxItems is returned along with the
+// items read so far and the cursor to continue from.
+func (d *dynamodbService) QueryAll(ctx context.Context, opts QueryOptions, maxItems int) (*QueryResult, error) {
+	input, err := d.buildQueryInput(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Limit > 0 {
+		input.Limit = aws.Int32(opts.Limit)
+	}
+	input.ReturnConsumedCapacity = capacityMode(opts.ReturnConsumedCapacity || opts.Budget.tracksCapacity())
+
+	if maxItems <= 0 {
+		maxItems = defaultMaxItems
+	}
+
+	key := d.cacheKey(opts.Table, fmt.Sprintf("QueryAll:%d", maxItems), opts)
+	if result, ok := cachedResult[QueryResult](d, key); ok {
+		return result, nil
+	}
+
+	result := &QueryResult{}
+	for {
+		if err := opts.Budget.check(); err != nil {
+			return nil, err
+		}
+		response, err := d.client.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", DynamoDBErrQuery, err)
+		}
+		if err := opts.Budget.spend(response.ScannedCount, response.ConsumedCapacity); err != nil {
+			return nil, err
+		}
+		if err := d.loadItems(ctx, opts.Table, response.Items...); err != nil {
+			return nil, err
+		}
+		items, capacity, err := d.hydrate(ctx, opts, response.Items)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Items = append(result.Items, items...)
+		result.LastEvaluatedKey = response.LastEvaluatedKey
+		result.ConsumedCapacity = addCapacity(result.ConsumedCapacity, consumed(response.ConsumedCapacity)...)
+		result.ConsumedCapacity = mergeCapacity(result.ConsumedCapacity, capacity)
+		result.addPage(response.Count, response.ScannedCount)
+
+		if len(response.LastEvaluatedKey) == 0 {
+			storeResult(d, key, result)
+			return result, nil
+		}
+		if len(result.Items) >= maxItems {
+			break
+		}
+		input.ExclusiveStartKey = response.LastEvaluatedKey
+	}
+
+	result.NextCursor, err = encodeCursor(result.LastEvaluatedKey, d.cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, DynamoDBErrMaxItems
+}
+
+// addPage records the counts reported for a page.
+func (r *QueryResult) addPage(count, scannedCount int32) {
+	r.Count += int64(count)
+	r.ScannedCount += int64(scannedCount)
+	r.Pages = append(r.Pages, PageCount{Count: int64(count), ScannedCount: int64(scannedCount)})
+}
+
+// Count returns the number of items matching the key condition and filters
+// without transferring them. All pages are read, starting from the cursor if
+// set, and Limit and Fields are ignored.
+func (d *dynamodbService) Count(ctx context.Context, opts QueryOptions) (*CountResult, error) {
+	opts.Fields = nil
+
+	input, err := d.buildQueryInput(opts)
+	if err != nil {
+		return nil, err
+	}
+	input.Select = types.SelectCount
+	input.ReturnConsumedCapacity = capacityMode(opts.ReturnConsumedCapacity || opts.Budget.tracksCapacity())
+
+	result := &CountResult{}
+	paginator := dynamodb.NewQueryPaginator(d.client, input)
+	for paginator.HasMorePages() {
+		if err := opts.Budget.check(); err != nil {
+			return nil, err
+		}
+		response, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", DynamoDBErrQuery, err)
+		}
+		if err := opts.Budget.spend(response.ScannedCount, response.ConsumedCapacity); err != nil {
+			return nil, err
+		}
+
+		result.Count += int64(response.Count)
+		result.ScannedCount += int64(response.ScannedCount)
+		result.ConsumedCapacity = addCapacity(result.ConsumedCapacity, consumed(response.ConsumedCapacity)...)
+	}
+
+	return result, nil
+}
+
+// QueryItems iterates over every item matching the key condition and filters,
+// starting from the cursor if set. Pages are read as the iteration goes, so
+// only one page is held in memory and Limit sets the page size. A failed read
+// is yielded as an error with a nil item and ends the iteration.
+func (d *dynamodbService) QueryItems(ctx context.Context, opts QueryOptions) iter.Seq2[Item, error] {
+	return func(yield func(Item, error) bool) {
+		input, err := d.buildQueryInput(opts)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if opts.Limit > 0 {
+			input.Limit = aws.Int32(opts.Limit)
+		}
+		input.ReturnConsumedCapacity = capacityMode(opts.Budget.tracksCapacity())
+
+		paginator := dynamodb.NewQueryPaginator(d.client, input)
+		for paginator.HasMorePages() {
+			if err := opts.Budget.check(); err != nil {
+				yield(nil, err)
+				return
+			}
+			response, err := paginator.NextPage(ctx)
+			if err != nil {
+				yield(nil, fmt.Errorf("%w: %w", DynamoDBErrQuery, err))
+				return
+			}
+			if err := opts.Budget.spend(response.ScannedCount, response.ConsumedCapacity); err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if err := d.loadItems(ctx, opts.Table, response.Items...); err != nil {
+				yield(nil, err)
+				return
+			}
+			items, _, err := d.hydrate(ctx, opts, response.Items)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Get fetches a single item by its primary key. DynamoDBErrItemNotFound is
+// returned when no item matches the key.
+func (d *dynamodbService) Get(ctx context.Context, opts GetOptions) (*GetResult, error) {
+	if opts.Table == "" {
+		return nil, DynamoDBErrTableNotSet
+	}
+
+	var cacheKey string
+	if !opts.ConsistentRead {
+		cacheKey = d.cacheKey(opts.Table, "Get", opts)
+	}
+	if result, ok := cachedResult[GetResult](d, cacheKey); ok {
+		return result, nil
+	}
+
+	key, err := opts.Key.marshal(d.marshal)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &dynamodb.GetItemInput{
+		TableName:              aws.String(opts.Table),
+		Key:                    key,
+		ConsistentRead:         aws.Bool(opts.ConsistentRead),
+		ReturnConsumedCapacity: capacityMode(opts.ReturnConsumedCapacity),
+	}
+
+	if len(opts.Fields) > 0 {
+		expr, err := expression.NewBuilder().WithProjection(buildProjection(opts.Fields)).Build()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", DynamoDBErrBuildProjection, err)
+		}
+
+		input.ProjectionExpression = expr.Projection()
+		input.ExpressionAttributeNames = expr.Names()
+	}
+
+	response, err := d.client.GetItem(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrGetItem, err)
+	}
+	if response.Item == nil {
+		return nil, DynamoDBErrItemNotFound
+	}
+	if err := d.loadItems(ctx, opts.Table, response.Item); err != nil {
+		return nil, err
+	}
+
+	result := &GetResult{
+		Item:             response.Item,
+		ConsumedCapacity: addCapacity(nil, consumed(response.ConsumedCapacity)...),
+	}
+	storeResult(d, cacheKey, result)
+
+	return result, nil
+}
+
+// Put writes a single item. The item can be a struct, a map, or an already
+// marshaled map of attribute values.
+func (d *dynamodbService) Put(ctx context.Context, table string, item any, opts ...PutOptions) (*PutResult, error) {
+	if table == "" {
+		return nil, DynamoDBErrTableNotSet
+	}
+	if item == nil {
+		return nil, DynamoDBErrItemNotSet
+	}
+	defer d.invalidate(table)
+
+	var o PutOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if err := checkReturnValues(o.ReturnValues, ReturnNone, ReturnAllOld); err != nil {
+		return nil, err
+	}
+
+	av, err := MarshalItem(item, d.marshal)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PutResult{}
+	if o.VersionField != "" {
+		var version int64
+		av, version, err = nextVersion(av, o.VersionField)
+		if err != nil {
+			return nil, err
+		}
+
+		o.Condition = versionCondition(o.Condition, o.VersionField, version)
+		result.Version = version + 1
+	}
+
+	av, err = d.storeItem(ctx, table, av)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkItemSize(av, o.MaxItemSize); err != nil {
+		return nil, err
+	}
+
+	var auditKey, before Item
+	if d.audited(table) {
+		if auditKey, err = d.auditKey(ctx, table, av); err != nil {
+			return nil, err
+		}
+		if before, err = d.beforeWrite(ctx, table, auditKey); err != nil {
+			return nil, err
+		}
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:              aws.String(table),
+		Item:                   av,
+		ReturnConsumedCapacity: capacityMode(o.ReturnConsumedCapacity),
+	}
+
+	if o.ReturnValues != "" {
+		input.ReturnValues = types.ReturnValue(o.ReturnValues)
+	}
+
+	if o.Condition != nil {
+		expr, err := d.buildConditionExpression(*o.Condition)
+		if err != nil {
+			return nil, err
+		}
+
+		input.ConditionExpression = expr.Condition()
+		input.ExpressionAttributeNames = expr.Names()
+		input.ExpressionAttributeValues = expr.Values()
+	}
+
+	response, err := d.client.PutItem(ctx, input)
+	if err != nil {
+		err = writeError(DynamoDBErrPutItem, err)
+		if o.VersionField != "" {
+			err = versionError(err)
+		}
+		return nil, err
+	}
+
+	if err := d.loadItems(ctx, table, response.Attributes); err != nil {
+		return nil, err
+	}
+
+	result.Attributes = response.Attributes
+	result.ConsumedCapacity = addCapacity(nil, consumed(response.ConsumedCapacity)...)
+
+	if err := d.afterWrite(ctx, AuditPut, table, auditKey, before, av); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// Delete removes a single item by its primary key.
+func (d *dynamodbService) Delete(ctx context.Context, opts DeleteOptions) (*DeleteResult, error) {
 	if opts.Table == "" {
 		return nil, DynamoDBErrTableNotSet
 	}
-	if opts.Index == "" {
-		return nil, DynamoDBErrIndexNotSet
+	if err := checkReturnValues(opts.ReturnValues, ReturnNone, ReturnAllOld); err != nil {
+		return nil, err
+	}
+	defer d.invalidate(opts.Table)
+
+	key, err := opts.Key.marshal(d.marshal)
+	if err != nil {
+		return nil, err
 	}
 
+	before, err := d.beforeWrite(ctx, opts.Table, key)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &dynamodb.DeleteItemInput{
+		TableName:              aws.String(opts.Table),
+		Key:                    key,
+		ReturnConsumedCapacity: capacityMode(opts.ReturnConsumedCapacity),
+	}
+
+	if opts.ReturnValues != "" {
+		input.ReturnValues = types.ReturnValue(opts.ReturnValues)
+	}
+
+	if opts.Condition != nil {
+		expr, err := d.buildConditionExpression(*opts.Condition)
+		if err != nil {
+			return nil, err
+		}
+
+		input.ConditionExpression = expr.Condition()
+		input.ExpressionAttributeNames = expr.Names()
+		input.ExpressionAttributeValues = expr.Values()
+	}
+
+	response, err := d.client.DeleteItem(ctx, input)
+	if err != nil {
+		return nil, writeError(DynamoDBErrDeleteItem, err)
+	}
+	if err := d.loadItems(ctx, opts.Table, response.Attributes); err != nil {
+		return nil, err
+	}
+
+	result := &DeleteResult{
+		Attributes:       response.Attributes,
+		ConsumedCapacity: addCapacity(nil, consumed(response.ConsumedCapacity)...),
+	}
+	if err := d.afterWrite(ctx, AuditDelete, opts.Table, key, before, nil); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// Unmarshal converts the returned item into out, a pointer to a struct or
+// map. DynamoDBErrItemNotFound is returned when no item was returned, e.g.,
+// when the put created the item.
+func (r *PutResult) Unmarshal(out any, opts ...MarshalOptions) error {
+	return unmarshalAttributes(r.Attributes, out, opts...)
+}
+
+// Unmarshal converts the returned item into out, a pointer to a struct or
+// map. DynamoDBErrItemNotFound is returned when no item was deleted.
+func (r *DeleteResult) Unmarshal(out any, opts ...MarshalOptions) error {
+	return unmarshalAttributes(r.Attributes, out, opts...)
+}
+
+func unmarshalAttributes(item Item, out any, opts ...MarshalOptions) error {
+	var o MarshalOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	if len(item) == 0 {
+		return DynamoDBErrItemNotFound
+	}
+	if err := attributevalue.UnmarshalMapWithOptions(item, out, o.decoderOptions); err != nil {
+		return fmt.Errorf("%w: %w", DynamoDBErrUnmarshal, err)
+	}
+
+	return nil
+}
+
+// checkReturnValues rejects return values the operation does not support.
+func checkReturnValues(value ReturnValue, supported ...ReturnValue) error {
+	if value == "" || slices.Contains(supported, value) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", DynamoDBErrReturnValues, value)
+}
+
+// marshal converts the key into attribute values the way MarshalItem stores
+// them with the options, so time.Time keys match. A key must have the
+// partition key and, for composite keys, the sort key.
+func (k Key) marshal(opts MarshalOptions) (Item, error) {
+	if len(k) == 0 || len(k) > 2 {
+		return nil, DynamoDBErrValueNotSet
+	}
+
+	av, err := attributevalue.MarshalMapWithOptions(map[string]any(k), opts.encoderOptions)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrMarshal, err)
+	}
+
+	return av, nil
+}
+
+// storeItem prepares a marshaled item for writing, encrypting and offloading
+// its attributes when configured.
+func (d *dynamodbService) storeItem(ctx context.Context, table string, item Item) (Item, error) {
+	item, err := d.encrypt(ctx, table, item)
+	if err != nil {
+		return nil, err
+	}
+	return d.offload(ctx, table, item)
+}
+
+// loadItems reverts storeItem on the items read, in place.
+func (d *dynamodbService) loadItems(ctx context.Context, table string, items ...Item) error {
+	if err := d.rehydrate(ctx, items...); err != nil {
+		return err
+	}
+	return d.decrypt(ctx, table, items...)
+}
+
+func buildProjection(fields []string) expression.ProjectionBuilder {
+	names := make([]expression.NameBuilder, len(fields))
+	for i, field := range fields {
+		names[i] = expression.Name(field)
+	}
+
+	return expression.NamesList(names[0], names[1:]...)
+}
+
+// writeError wraps a failed write with its sentinel error. Failed conditions
+// also wrap DynamoDBErrConditionFailed so callers can branch on them.
+func writeError(sentinel error, err error) error {
+	var conditionErr *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionErr) {
+		return fmt.Errorf("%w: %w: %w", sentinel, DynamoDBErrConditionFailed, err)
+	}
+
+	return fmt.Errorf("%w: %w", sentinel, err)
+}
+
+func (d *dynamodbService) buildConditionExpression(where Where) (expression.Expression, error) {
+	cond, err := d.buildFilterExpression(where)
+	if err != nil {
+		return expression.Expression{}, fmt.Errorf("%w: %w", DynamoDBErrBuildConditionExpression, err)
+	}
+
+	expr, err := expression.NewBuilder().WithCondition(cond).Build()
+	if err != nil {
+		return expression.Expression{}, fmt.Errorf("%w: %w", DynamoDBErrBuildConditionExpression, err)
+	}
+
+	return expr, nil
+}
+
+func (d *dynamodbService) buildQueryInput(opts QueryOptions) (*dynamodb.QueryInput, error) {
+	// Validate
+	if opts.Table == "" {
+		return nil, DynamoDBErrTableNotSet
+	}
 	if opts.Partition == nil || opts.Partition.Key == "" || opts.Partition.Value == nil {
 		return nil, DynamoDBErrPartitionNotSet
 	}
 
-	// Build key condition expression for GSI
-	keyEx := expression.Key(opts.Partition.Key).Equal(expression.Value(opts.Partition.Value))
+	if opts.TimeFormat == "" {
+		opts.TimeFormat = d.marshal.TimeFormat
+	}
+
+	// Build key condition expression for the table or index
+	partition := opts.TimeFormat.encode(opts.Partition.Value)
+	keyEx := expression.Key(opts.Partition.Key).Equal(expression.Value(partition))
 
 	if opts.Sort != nil && opts.Sort.Key != "" && opts.Sort.Value != nil {
-		// TODO: Support other operators and create its own function
-		switch opts.Sort.Operator {
-		case Equal:
-			keyEx = keyEx.And(expression.Key(opts.Sort.Key).Equal(expression.Value(opts.Sort.Value)))
-		default:
-			return nil, fmt.Errorf("unsupported sort key operator: %s", opts.Sort.Operator)
+		sort := *opts.Sort
+		sort.Value = opts.TimeFormat.encode(sort.Value)
+		sort.Value2 = opts.TimeFormat.encode(sort.Value2)
+
+		sortEx, err := d.buildSortKeyCondition(sort)
+		if err != nil {
+			return nil, err
 		}
+		keyEx = keyEx.And(sortEx)
 	}
 
 	builder := expression.NewBuilder().WithKeyCondition(keyEx)
@@ -136,46 +919,86 @@ func (d *dynamodbService) Query(ctx context.Context, opts QueryOptions) ([]map[s
 		builder = builder.WithFilter(filterExpr)
 	}
 
+	if len(opts.Fields) > 0 {
+		builder = builder.WithProjection(buildProjection(opts.Fields))
+	}
+
 	expr, err := builder.Build()
 	if err != nil {
 		return nil, err
 	}
 
+	startKey, err := decodeCursor(opts.Cursor, d.cursor)
+	if err != nil {
+		return nil, err
+	}
+
 	// Set up query input
 	input := &dynamodb.QueryInput{
 		TableName:                 aws.String(opts.Table),
-		IndexName:                 aws.String(opts.Index),
 		ExpressionAttributeNames:  expr.Names(),
 		ExpressionAttributeValues: expr.Values(),
 		KeyConditionExpression:    expr.KeyCondition(),
 		ProjectionExpression:      expr.Projection(),
-		Limit:                     aws.Int32(opts.Limit),
+		ExclusiveStartKey:         startKey,
+	}
+
+	if opts.Index != "" {
+		input.IndexName = aws.String(opts.Index)
+	}
+
+	if opts.Order == Descending {
+		input.ScanIndexForward = aws.Bool(false)
 	}
 
 	if expr.Filter() != nil {
 		input.FilterExpression = expr.Filter()
 	}
 
-	// Marshal with indentation for readability
-	out, err := json.MarshalIndent(input, "", "  ")
+	return input, nil
+}
+
+// value returns the operand of a condition or update value, encoded the way
+// MarshalItem stores it so times compare with the stored ones. Attribute
+// values are used as is.
+func (d *dynamodbService) value(v any) expression.ValueBuilder {
+	if _, ok := v.(types.AttributeValue); ok {
+		return expression.Value(v)
+	}
+
+	av, err := attributevalue.MarshalWithOptions(v, d.marshal.encoderOptions)
 	if err != nil {
-		panic(err)
+		// Left to the expression builder, which reports it on Build
+		return expression.Value(v)
 	}
-	fmt.Println(string(out))
+	return expression.Value(av)
+}
 
-	queryPaginator := dynamodb.NewQueryPaginator(d.client, input)
+func (d *dynamodbService) buildSortKeyCondition(sort QueryKeyValue) (expression.KeyConditionBuilder, error) {
+	key := expression.Key(sort.Key)
+	value := expression.Value(sort.Value)
 
-	var items []map[string]types.AttributeValue
-	for queryPaginator.HasMorePages() {
-		response, err := queryPaginator.NextPage(ctx)
-		if err != nil {
-			return nil, DynamoDBErrQuery
+	switch sort.Operator {
+	case "", Equal:
+		return key.Equal(value), nil
+	case LessThan:
+		return key.LessThan(value), nil
+	case LessThanEqual:
+		return key.LessThanEqual(value), nil
+	case GreaterThan:
+		return key.GreaterThan(value), nil
+	case GreaterThanEqual:
+		return key.GreaterThanEqual(value), nil
+	case Between:
+		if sort.Value2 == nil {
+			return expression.KeyConditionBuilder{}, errors.New("BETWEEN operator requires Value2")
 		}
-
-		items = append(items, response.Items...)
+		return key.Between(value, expression.Value(sort.Value2)), nil
+	case BeginsWith:
+		return key.BeginsWith(fmt.Sprint(sort.Value)), nil
+	default:
+		return expression.KeyConditionBuilder{}, fmt.Errorf("unsupported sort key operator: %s", sort.Operator)
 	}
-
-	return items, nil
 }
 
 func (d *dynamodbService) buildFilterExpression(where Where) (expression.ConditionBuilder, error) {
@@ -217,40 +1040,65 @@ func (d *dynamodbService) buildFilterExpression(where Where) (expression.Conditi
 		}
 	}
 
+	if where.Not {
+		result = result.Not()
+	}
+
 	return result, nil
 }
 
 func (d *dynamodbService) buildSingleCondition(cond WhereCondition) (expression.ConditionBuilder, error) {
+	condition, err := d.buildOperatorCondition(cond)
+	if err != nil || !cond.Not {
+		return condition, err
+	}
+
+	return condition.Not(), nil
+}
+
+func (d *dynamodbService) buildOperatorCondition(cond WhereCondition) (expression.ConditionBuilder, error) {
 	name := expression.Name(cond.Field)
 
 	switch cond.Operator {
 	case Equal:
-		return name.Equal(expression.Value(cond.Value)), nil
+		return name.Equal(d.value(cond.Value)), nil
 	case NotEqual:
-		return name.NotEqual(expression.Value(cond.Value)), nil
+		return name.NotEqual(d.value(cond.Value)), nil
 	case LessThan:
-		return name.LessThan(expression.Value(cond.Value)), nil
+		return name.LessThan(d.value(cond.Value)), nil
 	case LessThanEqual:
-		return name.LessThanEqual(expression.Value(cond.Value)), nil
+		return name.LessThanEqual(d.value(cond.Value)), nil
 	case GreaterThan:
-		return name.GreaterThan(expression.Value(cond.Value)), nil
+		return name.GreaterThan(d.value(cond.Value)), nil
 	case GreaterThanEqual:
-		return name.GreaterThanEqual(expression.Value(cond.Value)), nil
+		return name.GreaterThanEqual(d.value(cond.Value)), nil
 	case Between:
-		return name.Between(expression.Value(cond.Value), expression.Value(cond.Value2)), nil
+		return name.Between(d.value(cond.Value), d.value(cond.Value2)), nil
 	case In:
 		if len(cond.Values) == 0 {
 			return expression.ConditionBuilder{}, errors.New("IN operator requires non-empty Values slice")
 		}
 
-		// Convert first value separately, then spread the rest
-		firstValue := expression.Value(cond.Values[0])
-		additionalValues := make([]expression.OperandBuilder, len(cond.Values)-1)
-		for i, v := range cond.Values[1:] {
-			additionalValues[i] = expression.Value(v)
+		// DynamoDB allows up to 100 values per IN, so longer lists are split
+		// into chunks that are OR'd together
+		var result expression.ConditionBuilder
+		for chunk := range slices.Chunk(cond.Values, inLimit) {
+			// Convert first value separately, then spread the rest
+			firstValue := d.value(chunk[0])
+			additionalValues := make([]expression.OperandBuilder, len(chunk)-1)
+			for i, v := range chunk[1:] {
+				additionalValues[i] = d.value(v)
+			}
+
+			in := name.In(firstValue, additionalValues...)
+			if result.IsSet() {
+				result = result.Or(in)
+			} else {
+				result = in
+			}
 		}
 
-		return name.In(firstValue, additionalValues...), nil
+		return result, nil
 	case Contains:
 		return name.Contains(fmt.Sprint(cond.Value)), nil
 	case BeginsWith:
@@ -259,6 +1107,12 @@ func (d *dynamodbService) buildSingleCondition(cond WhereCondition) (expression.
 		return name.AttributeExists(), nil
 	case AttributeNotExists:
 		return name.AttributeNotExists(), nil
+	case AttributeOfType:
+		attributeType := expression.DynamoDBAttributeType(fmt.Sprint(cond.Value))
+		if !slices.Contains(attributeTypes, attributeType) {
+			return expression.ConditionBuilder{}, fmt.Errorf("unsupported attribute type: %s", attributeType)
+		}
+		return name.AttributeType(attributeType), nil
 	default:
 		return expression.ConditionBuilder{}, fmt.Errorf("unsupported operator: %s", cond.Operator)
 	}
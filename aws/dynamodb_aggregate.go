@@ -0,0 +1,134 @@
+package aws
+
+import (
+	"context"
+	"iter"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type (
+	AggregateOptions struct {
+		Sum    []string // Number attributes to total
+		MinMax []string // Number attributes to track the lowest and highest value of
+		// Optional attribute to aggregate each of its values apart, e.g.,
+		// "status". Items without it are grouped under "".
+		GroupBy string
+	}
+
+	// AggregateResult holds the totals of the items read. Attributes missing
+	// from an item or not holding a number are skipped for that item.
+	AggregateResult struct {
+		Count  int64
+		Sum    map[string]float64
+		Min    map[string]float64
+		Max    map[string]float64
+		Groups map[string]*AggregateResult // By GroupBy value, nil without GroupBy
+	}
+)
+
+// AggregateQuery reads every item of the query, one page at a time, and
+// aggregates them without keeping them in memory, e.g.,
+//
+//	result, err := AggregateQuery(ctx, ddb, opts, AggregateOptions{
+//		Sum:     []string{"total"},
+//		GroupBy: "status",
+//	})
+//	paid := result.Groups["PAID"].Sum["total"]
+func AggregateQuery(ctx context.Context, ddb DynamoDB, opts QueryOptions, agg AggregateOptions) (*AggregateResult, error) {
+	return AggregateItems(ddb.QueryItems(ctx, opts), agg)
+}
+
+// AggregateItems aggregates the items of an iterator, stopping at its first
+// error.
+func AggregateItems(items iter.Seq2[Item, error], opts AggregateOptions) (*AggregateResult, error) {
+	result := newAggregateResult(opts.GroupBy != "")
+
+	for item, err := range items {
+		if err != nil {
+			return nil, err
+		}
+
+		result.add(item, opts)
+		if opts.GroupBy != "" {
+			value := groupValue(item[opts.GroupBy])
+			group, ok := result.Groups[value]
+			if !ok {
+				group = newAggregateResult(false)
+				result.Groups[value] = group
+			}
+			group.add(item, opts)
+		}
+	}
+
+	return result, nil
+}
+
+// Average returns the sum of the attribute divided by the item count, 0 when
+// no item was read.
+func (r *AggregateResult) Average(field string) float64 {
+	if r.Count == 0 {
+		return 0
+	}
+	return r.Sum[field] / float64(r.Count)
+}
+
+func newAggregateResult(grouped bool) *AggregateResult {
+	result := &AggregateResult{
+		Sum: map[string]float64{},
+		Min: map[string]float64{},
+		Max: map[string]float64{},
+	}
+	if grouped {
+		result.Groups = map[string]*AggregateResult{}
+	}
+	return result
+}
+
+func (r *AggregateResult) add(item Item, opts AggregateOptions) {
+	r.Count++
+
+	for _, field := range opts.Sum {
+		if n, ok := numberValue(item[field]); ok {
+			r.Sum[field] += n
+		}
+	}
+
+	for _, field := range opts.MinMax {
+		n, ok := numberValue(item[field])
+		if !ok {
+			continue
+		}
+		if low, seen := r.Min[field]; !seen || n < low {
+			r.Min[field] = n
+		}
+		if high, seen := r.Max[field]; !seen || n > high {
+			r.Max[field] = n
+		}
+	}
+}
+
+func numberValue(av types.AttributeValue) (float64, bool) {
+	number, ok := av.(*types.AttributeValueMemberN)
+	if !ok {
+		return 0, false
+	}
+
+	n, err := strconv.ParseFloat(number.Value, 64)
+	return n, err == nil
+}
+
+// groupValue returns scalar values as text, and "" for any other value.
+func groupValue(av types.AttributeValue) string {
+	switch value := av.(type) {
+	case *types.AttributeValueMemberS:
+		return value.Value
+	case *types.AttributeValueMemberN:
+		return value.Value
+	case *types.AttributeValueMemberBOOL:
+		return strconv.FormatBool(value.Value)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,167 @@
+package aws
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	AuditPut    AuditOperation = "PUT"
+	AuditUpdate AuditOperation = "UPDATE"
+	AuditDelete AuditOperation = "DELETE"
+)
+
+type (
+	AuditOperation string
+
+	// AuditConfig records every Put, Update and Delete made through the
+	// client in an audit table, see AuditTableOptions for its schema. Each
+	// record holds the table, key, operation, actor, time, and the item
+	// before and after the write. The images are read with consistent reads
+	// around the write, so they may include concurrent writes by other
+	// clients. Batch and transactional writes are not recorded.
+	AuditConfig struct {
+		Table string
+		// Returns who is writing, defaults to the actor set with WithActor
+		Actor func(ctx context.Context) string
+	}
+
+	actorKey struct{}
+)
+
+var DynamoDBErrAudit = errors.New("failed to record audit trail")
+
+// WithActor returns a context whose writes are recorded as made by the actor,
+// e.g., a user ID.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor set with WithActor, empty when unset.
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// AuditTableOptions returns the schema of an audit table. Records of an item
+// share the partition key "pk", "<table>#<key>", and are sorted by time in
+// the sort key "sk".
+func AuditTableOptions(table string) CreateTableOptions {
+	return CreateTableOptions{
+		Table:        table,
+		PartitionKey: KeyAttribute{Name: "pk", Type: AttributeString},
+		SortKey:      &KeyAttribute{Name: "sk", Type: AttributeString},
+	}
+}
+
+// audited reports whether writes to the table are recorded. Writes to the
+// audit table itself are not.
+func (d *dynamodbService) audited(table string) bool {
+	return d.audit != nil && d.audit.Table != "" && table != d.audit.Table
+}
+
+// beforeWrite returns the stored item to record as the before image, nil
+// when the table is not audited.
+func (d *dynamodbService) beforeWrite(ctx context.Context, table string, key Item) (Item, error) {
+	if !d.audited(table) {
+		return nil, nil
+	}
+	return d.auditImage(ctx, table, key)
+}
+
+// afterWrite records a successful write. The after image of updates is read
+// back from the table.
+func (d *dynamodbService) afterWrite(ctx context.Context, operation AuditOperation, table string, key, before, after Item) error {
+	if !d.audited(table) {
+		return nil
+	}
+
+	if operation == AuditUpdate {
+		var err error
+		if after, err = d.auditImage(ctx, table, key); err != nil {
+			return err
+		}
+	}
+
+	return d.record(ctx, operation, table, key, before, after)
+}
+
+// auditImage reads the stored item, as is, for the audit record.
+func (d *dynamodbService) auditImage(ctx context.Context, table string, key Item) (Item, error) {
+	response, err := d.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(table),
+		Key:            key,
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrAudit, err)
+	}
+
+	return response.Item, nil
+}
+
+// auditKey returns the key of an item about to be put.
+func (d *dynamodbService) auditKey(ctx context.Context, table string, item Item) (Item, error) {
+	names, err := d.keyNames(ctx, table)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrAudit, err)
+	}
+
+	return itemKey(item, names), nil
+}
+
+func (d *dynamodbService) record(ctx context.Context, operation AuditOperation, table string, key, before, after Item) error {
+	actor := ActorFromContext(ctx)
+	if d.audit.Actor != nil {
+		actor = d.audit.Actor(ctx)
+	}
+
+	id, err := json.Marshal(encodeAttributeValue(&types.AttributeValueMemberM{Value: key}))
+	if err != nil {
+		return fmt.Errorf("%w: %w", DynamoDBErrAudit, err)
+	}
+
+	// The random suffix keeps records of the same instant apart
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Errorf("%w: %w", DynamoDBErrAudit, err)
+	}
+	now := time.Now().UTC()
+
+	entry := Item{
+		"pk":        &types.AttributeValueMemberS{Value: table + "#" + string(id)},
+		"sk":        &types.AttributeValueMemberS{Value: formatTime(now) + "#" + hex.EncodeToString(suffix)},
+		"table":     &types.AttributeValueMemberS{Value: table},
+		"key":       &types.AttributeValueMemberM{Value: key},
+		"operation": &types.AttributeValueMemberS{Value: string(operation)},
+		"at":        &types.AttributeValueMemberS{Value: now.Format(time.RFC3339Nano)},
+	}
+	if actor != "" {
+		entry["actor"] = &types.AttributeValueMemberS{Value: actor}
+	}
+	if before != nil {
+		entry["before"] = &types.AttributeValueMemberM{Value: before}
+	}
+	if after != nil {
+		entry["after"] = &types.AttributeValueMemberM{Value: after}
+	}
+
+	_, err = d.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(d.audit.Table),
+		Item:      entry,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", DynamoDBErrAudit, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type (
+	Backup struct {
+		ARN       string
+		Name      string
+		Table     string
+		Status    string // e.g., "CREATING" or "AVAILABLE"
+		Type      string // "USER", "SYSTEM" or "AWS_BACKUP"
+		SizeBytes int64
+		CreatedAt time.Time
+	}
+
+	ListBackupsOptions struct {
+		Table string    // Optional, lists the backups of all tables when empty
+		From  time.Time // Optional lower bound of the creation time
+		To    time.Time // Optional upper bound of the creation time
+	}
+
+	PointInTimeRecovery struct {
+		Enabled bool
+		// Restore window, zero when recovery is disabled
+		EarliestRestore time.Time
+		LatestRestore   time.Time
+	}
+
+	RestoreTableOptions struct {
+		BackupARN string
+		Table     string // Name of the new table, must not exist yet
+		// Optional overrides of the billing of the backed up table
+		BillingMode BillingMode
+		Throughput  *Throughput
+	}
+)
+
+var (
+	DynamoDBErrBackupNameNotSet = errors.New("backup name not set")
+	DynamoDBErrBackupNotSet     = errors.New("backup not set")
+	DynamoDBErrCreateBackup     = errors.New("failed to create backup")
+	DynamoDBErrDescribeBackups  = errors.New("failed to describe continuous backups")
+	DynamoDBErrListBackups      = errors.New("failed to list backups")
+	DynamoDBErrRestoreBackup    = errors.New("failed to restore table from backup")
+	DynamoDBErrUpdateRecovery   = errors.New("failed to update point in time recovery")
+)
+
+// CreateBackup takes an on-demand backup of the table.
+func (d *dynamodbService) CreateBackup(ctx context.Context, table, name string) (*Backup, error) {
+	if table == "" {
+		return nil, DynamoDBErrTableNotSet
+	}
+	if name == "" {
+		return nil, DynamoDBErrBackupNameNotSet
+	}
+
+	response, err := d.client.CreateBackup(ctx, &dynamodb.CreateBackupInput{
+		TableName:  aws.String(table),
+		BackupName: aws.String(name),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrCreateBackup, err)
+	}
+
+	details := response.BackupDetails
+	if details == nil {
+		return &Backup{Name: name, Table: table}, nil
+	}
+
+	return &Backup{
+		ARN:       aws.ToString(details.BackupArn),
+		Name:      aws.ToString(details.BackupName),
+		Table:     table,
+		Status:    string(details.BackupStatus),
+		Type:      string(details.BackupType),
+		SizeBytes: aws.ToInt64(details.BackupSizeBytes),
+		CreatedAt: aws.ToTime(details.BackupCreationDateTime),
+	}, nil
+}
+
+// ListBackups returns the backups matching the options, reading all pages.
+func (d *dynamodbService) ListBackups(ctx context.Context, opts ListBackupsOptions) ([]Backup, error) {
+	input := &dynamodb.ListBackupsInput{}
+	if opts.Table != "" {
+		input.TableName = aws.String(opts.Table)
+	}
+	if !opts.From.IsZero() {
+		input.TimeRangeLowerBound = aws.Time(opts.From)
+	}
+	if !opts.To.IsZero() {
+		input.TimeRangeUpperBound = aws.Time(opts.To)
+	}
+
+	var backups []Backup
+	for {
+		response, err := d.client.ListBackups(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", DynamoDBErrListBackups, err)
+		}
+
+		for _, summary := range response.BackupSummaries {
+			backups = append(backups, Backup{
+				ARN:       aws.ToString(summary.BackupArn),
+				Name:      aws.ToString(summary.BackupName),
+				Table:     aws.ToString(summary.TableName),
+				Status:    string(summary.BackupStatus),
+				Type:      string(summary.BackupType),
+				SizeBytes: aws.ToInt64(summary.BackupSizeBytes),
+				CreatedAt: aws.ToTime(summary.BackupCreationDateTime),
+			})
+		}
+
+		if response.LastEvaluatedBackupArn == nil {
+			break
+		}
+		input.ExclusiveStartBackupArn = response.LastEvaluatedBackupArn
+	}
+
+	return backups, nil
+}
+
+// RestoreTableFromBackup creates a new table from the backup. The table is
+// usually still being created when this returns, see WaitForTableActive.
+func (d *dynamodbService) RestoreTableFromBackup(ctx context.Context, opts RestoreTableOptions) (*TableDescription, error) {
+	if opts.BackupARN == "" {
+		return nil, DynamoDBErrBackupNotSet
+	}
+	if opts.Table == "" {
+		return nil, DynamoDBErrTableNotSet
+	}
+
+	input := &dynamodb.RestoreTableFromBackupInput{
+		BackupArn:       aws.String(opts.BackupARN),
+		TargetTableName: aws.String(opts.Table),
+	}
+	if opts.BillingMode != "" {
+		input.BillingModeOverride = types.BillingMode(opts.BillingMode)
+	}
+	if opts.Throughput != nil {
+		input.ProvisionedThroughputOverride = opts.Throughput.provisioned()
+	}
+
+	response, err := d.client.RestoreTableFromBackup(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrRestoreBackup, err)
+	}
+
+	return describeTable(response.TableDescription), nil
+}
+
+// EnablePointInTimeRecovery turns on continuous backups for the table.
+func (d *dynamodbService) EnablePointInTimeRecovery(ctx context.Context, table string) error {
+	return d.updatePointInTimeRecovery(ctx, table, true)
+}
+
+// DisablePointInTimeRecovery turns off continuous backups for the table.
+func (d *dynamodbService) DisablePointInTimeRecovery(ctx context.Context, table string) error {
+	return d.updatePointInTimeRecovery(ctx, table, false)
+}
+
+// PointInTimeRecoveryStatus reports whether continuous backups are enabled and
+// the window the table can be restored to.
+func (d *dynamodbService) PointInTimeRecoveryStatus(ctx context.Context, table string) (*PointInTimeRecovery, error) {
+	if table == "" {
+		return nil, DynamoDBErrTableNotSet
+	}
+
+	response, err := d.client.DescribeContinuousBackups(ctx, &dynamodb.DescribeContinuousBackupsInput{
+		TableName: aws.String(table),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrDescribeBackups, err)
+	}
+
+	recovery := &PointInTimeRecovery{}
+	if backups := response.ContinuousBackupsDescription; backups != nil && backups.PointInTimeRecoveryDescription != nil {
+		description := backups.PointInTimeRecoveryDescription
+		recovery.Enabled = description.PointInTimeRecoveryStatus == types.PointInTimeRecoveryStatusEnabled
+		recovery.EarliestRestore = aws.ToTime(description.EarliestRestorableDateTime)
+		recovery.LatestRestore = aws.ToTime(description.LatestRestorableDateTime)
+	}
+
+	return recovery, nil
+}
+
+func (d *dynamodbService) updatePointInTimeRecovery(ctx context.Context, table string, enabled bool) error {
+	if table == "" {
+		return DynamoDBErrTableNotSet
+	}
+
+	_, err := d.client.UpdateContinuousBackups(ctx, &dynamodb.UpdateContinuousBackupsInput{
+		TableName: aws.String(table),
+		PointInTimeRecoverySpecification: &types.PointInTimeRecoverySpecification{
+			PointInTimeRecoveryEnabled: aws.Bool(enabled),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", DynamoDBErrUpdateRecovery, err)
+	}
+
+	return nil
+}
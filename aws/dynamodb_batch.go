@@ -0,0 +1,305 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var (
+	batchGetLimit    = 100 // Max keys per BatchGetItem request
+	batchWriteLimit  = 25  // Max requests per BatchWriteItem request
+	batchMaxRetries  = 5
+	batchBaseBackoff = 50 * time.Millisecond
+	batchMaxBackoff  = 5 * time.Second
+)
+
+type (
+	BatchGetOptions struct {
+		Table          string
+		Keys           []Key // Any number of keys, sent in batches of 100
+		ConsistentRead bool
+		// Attributes to return, all when empty. Include the key attributes to
+		// match the items to their keys.
+		Fields     []string
+		MaxRetries int              // Retries for unprocessed keys, defaults to 5
+		Limiter    *CapacityLimiter // Optional read capacity budget
+		// Report the capacity used in the result
+		ReturnConsumedCapacity bool
+	}
+
+	BatchGetResult struct {
+		Items            []Item
+		ConsumedCapacity *ConsumedCapacity
+	}
+
+	BatchWriteOptions struct {
+		Table      string
+		Puts       []any // Structs, maps or attribute value maps to write
+		Deletes    []Key
+		MaxRetries int              // Retries for unprocessed items, defaults to 5
+		Limiter    *CapacityLimiter // Optional write capacity budget
+		// Reject puts larger than this many bytes with an *ItemTooLargeError
+		// before sending any batch, e.g., ItemSizeLimit. Zero skips the check.
+		MaxItemSize int
+		// Optional dead-letter hook receiving the writes of each batch that
+		// could not be applied. When set, a failed batch request no longer
+		// stops the other batches.
+		OnFailed func(failed []FailedWrite)
+		// Report the capacity used in the result
+		ReturnConsumedCapacity bool
+	}
+
+	BatchWriteResult struct {
+		Written          int    // Number of puts and deletes applied
+		FailedPuts       []Item // Items still unprocessed after all retries
+		FailedDeletes    []Item // Keys still unprocessed after all retries
+		ConsumedCapacity *ConsumedCapacity
+	}
+
+	// FailedWrite is a put or delete that could not be applied, e.g., to
+	// store in a dead-letter queue and replay later.
+	FailedWrite struct {
+		Table  string
+		Put    Item  // Item to write, nil for deletes
+		Delete Item  // Key to delete, nil for puts
+		Err    error // DynamoDBErrUnprocessed or the error of the batch request
+	}
+)
+
+var (
+	DynamoDBErrBatchGet            = errors.New("failed to batch get items")
+	DynamoDBErrBatchGetUnprocessed = errors.New("unprocessed keys remain after retries")
+	DynamoDBErrBatchWrite          = errors.New("failed to batch write items")
+	DynamoDBErrUnprocessed         = errors.New("unprocessed after retries")
+)
+
+// BatchGet fetches the items for the keys, splitting them into batches of 100
+// and retrying unprocessed keys with backoff. Items are returned in no
+// particular order and missing keys are skipped.
+func (d *dynamodbService) BatchGet(ctx context.Context, opts BatchGetOptions) (*BatchGetResult, error) {
+	if opts.Table == "" {
+		return nil, DynamoDBErrTableNotSet
+	}
+
+	keys := make([]Item, len(opts.Keys))
+	for i, key := range opts.Keys {
+		av, err := key.marshal(d.marshal)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = av
+	}
+
+	result := &BatchGetResult{}
+	for start := 0; start < len(keys); start += batchGetLimit {
+		end := min(start+batchGetLimit, len(keys))
+
+		if err := d.batchGet(ctx, opts, keys[start:end], result); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+func (d *dynamodbService) batchGet(ctx context.Context, opts BatchGetOptions, keys []Item, result *BatchGetResult) error {
+	maxRetries := opts.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = batchMaxRetries
+	}
+
+	request := map[string]types.KeysAndAttributes{
+		opts.Table: {
+			Keys:           keys,
+			ConsistentRead: aws.Bool(opts.ConsistentRead),
+		},
+	}
+
+	if len(opts.Fields) > 0 {
+		expr, err := expression.NewBuilder().WithProjection(buildProjection(opts.Fields)).Build()
+		if err != nil {
+			return fmt.Errorf("%w: %w", DynamoDBErrBuildProjection, err)
+		}
+
+		attributes := request[opts.Table]
+		attributes.ProjectionExpression = expr.Projection()
+		attributes.ExpressionAttributeNames = expr.Names()
+		request[opts.Table] = attributes
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := opts.Limiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		response, err := d.client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+			RequestItems:           request,
+			ReturnConsumedCapacity: capacityMode(opts.ReturnConsumedCapacity || opts.Limiter != nil),
+		})
+		if err != nil {
+			return fmt.Errorf("%w: %w", DynamoDBErrBatchGet, err)
+		}
+		opts.Limiter.consume(response.ConsumedCapacity...)
+
+		if err := d.loadItems(ctx, opts.Table, response.Responses[opts.Table]...); err != nil {
+			return err
+		}
+		result.Items = append(result.Items, response.Responses[opts.Table]...)
+		result.ConsumedCapacity = addCapacity(result.ConsumedCapacity, response.ConsumedCapacity...)
+
+		if len(response.UnprocessedKeys) == 0 {
+			return nil
+		}
+		if attempt >= maxRetries {
+			return DynamoDBErrBatchGetUnprocessed
+		}
+
+		request = response.UnprocessedKeys
+		if err := backoff(ctx, attempt); err != nil {
+			return err
+		}
+	}
+}
+
+// BatchWrite applies the puts and deletes in batches of 25, retrying
+// unprocessed items with backoff. Items that still fail after all retries are
+// reported in the result instead of as an error.
+func (d *dynamodbService) BatchWrite(ctx context.Context, opts BatchWriteOptions) (*BatchWriteResult, error) {
+	if opts.Table == "" {
+		return nil, DynamoDBErrTableNotSet
+	}
+
+	requests := make([]types.WriteRequest, 0, len(opts.Puts)+len(opts.Deletes))
+	for _, item := range opts.Puts {
+		av, err := MarshalItem(item, d.marshal)
+		if err != nil {
+			return nil, err
+		}
+		av, err = d.storeItem(ctx, opts.Table, av)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkItemSize(av, opts.MaxItemSize); err != nil {
+			return nil, err
+		}
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: av}})
+	}
+	for _, key := range opts.Deletes {
+		av, err := key.marshal(d.marshal)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: av}})
+	}
+
+	result := &BatchWriteResult{}
+	for start := 0; start < len(requests); start += batchWriteLimit {
+		end := min(start+batchWriteLimit, len(requests))
+		batch := requests[start:end]
+
+		unprocessed, err := d.batchWrite(ctx, opts, batch, result)
+		result.Written += len(batch) - len(unprocessed)
+		if err != nil {
+			if opts.OnFailed == nil || ctx.Err() != nil {
+				return result, err
+			}
+		} else {
+			err = DynamoDBErrUnprocessed
+		}
+
+		for _, request := range unprocessed {
+			if request.PutRequest != nil {
+				result.FailedPuts = append(result.FailedPuts, request.PutRequest.Item)
+			} else if request.DeleteRequest != nil {
+				result.FailedDeletes = append(result.FailedDeletes, request.DeleteRequest.Key)
+			}
+		}
+		if opts.OnFailed != nil && len(unprocessed) > 0 {
+			opts.OnFailed(failedWrites(opts.Table, unprocessed, err))
+		}
+	}
+
+	return result, nil
+}
+
+func failedWrites(table string, requests []types.WriteRequest, err error) []FailedWrite {
+	failed := make([]FailedWrite, len(requests))
+	for i, request := range requests {
+		failed[i] = FailedWrite{Table: table, Err: err}
+		if request.PutRequest != nil {
+			failed[i].Put = request.PutRequest.Item
+		} else if request.DeleteRequest != nil {
+			failed[i].Delete = request.DeleteRequest.Key
+		}
+	}
+	return failed
+}
+
+// batchWrite sends a single batch and returns the requests that remain
+// unprocessed once the retries are exhausted. On error, it returns the
+// requests still pending with it, as earlier attempts may have written some.
+func (d *dynamodbService) batchWrite(ctx context.Context, opts BatchWriteOptions, requests []types.WriteRequest, result *BatchWriteResult) ([]types.WriteRequest, error) {
+	maxRetries := opts.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = batchMaxRetries
+	}
+	defer d.invalidate(opts.Table)
+
+	pending := map[string][]types.WriteRequest{opts.Table: requests}
+	for attempt := 0; ; attempt++ {
+		if err := opts.Limiter.Wait(ctx); err != nil {
+			return pending[opts.Table], err
+		}
+
+		response, err := d.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems:           pending,
+			ReturnConsumedCapacity: capacityMode(opts.ReturnConsumedCapacity || opts.Limiter != nil),
+		})
+		if err != nil {
+			return pending[opts.Table], fmt.Errorf("%w: %w", DynamoDBErrBatchWrite, err)
+		}
+		opts.Limiter.consume(response.ConsumedCapacity...)
+
+		result.ConsumedCapacity = addCapacity(result.ConsumedCapacity, response.ConsumedCapacity...)
+
+		if len(response.UnprocessedItems) == 0 {
+			return nil, nil
+		}
+		if attempt >= maxRetries {
+			return response.UnprocessedItems[opts.Table], nil
+		}
+
+		pending = response.UnprocessedItems
+		if err := backoff(ctx, attempt); err != nil {
+			return pending[opts.Table], err
+		}
+	}
+}
+
+// backoff waits an exponentially growing, jittered delay before the next
+// attempt, returning early if the context is done.
+func backoff(ctx context.Context, attempt int) error {
+	delay := batchBaseBackoff << attempt
+	if delay <= 0 || delay > batchMaxBackoff {
+		delay = batchMaxBackoff
+	}
+	delay = delay/2 + rand.N(delay/2)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
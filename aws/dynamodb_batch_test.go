@@ -0,0 +1,134 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestBatchWriteUnprocessed(t *testing.T) {
+	d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}})
+	putItems(t, d, "users", 2)
+
+	fake.unprocessed = func(request map[string]any) bool {
+		id := requestID(request)
+		return id == "7" || id == "1"
+	}
+
+	puts := make([]any, 30)
+	for i := range puts {
+		puts[i] = map[string]any{"id": fmt.Sprint(i + 2)}
+	}
+	var failed []FailedWrite
+	result, err := d.BatchWrite(context.Background(), BatchWriteOptions{
+		Table:      "users",
+		Puts:       puts,
+		Deletes:    []Key{{"id": "0"}, {"id": "1"}},
+		MaxRetries: 1,
+		OnFailed:   func(writes []FailedWrite) { failed = append(failed, writes...) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Written != 30 {
+		t.Errorf("BatchWrite() wrote %d, want 30", result.Written)
+	}
+	if got := itemIDs(result.FailedPuts); !slices.Equal(got, []string{"7"}) {
+		t.Errorf("BatchWrite() failed puts = %v, want 7", got)
+	}
+	if got := itemIDs(result.FailedDeletes); !slices.Equal(got, []string{"1"}) {
+		t.Errorf("BatchWrite() failed deletes = %v, want 1", got)
+	}
+
+	if len(failed) != 2 {
+		t.Fatalf("OnFailed() received %d writes, want 2", len(failed))
+	}
+	for _, write := range failed {
+		if write.Table != "users" || !errors.Is(write.Err, DynamoDBErrUnprocessed) {
+			t.Errorf("OnFailed() write = %+v, want an unprocessed write of users", write)
+		}
+		if (write.Put == nil) == (write.Delete == nil) {
+			t.Errorf("OnFailed() write = %+v, want either a put or a delete", write)
+		}
+	}
+	if fake.count("users") != 30 {
+		t.Errorf("users holds %d items, want 30", fake.count("users"))
+	}
+}
+
+func TestBatchWriteFailedBatch(t *testing.T) {
+	puts := make([]any, 30)
+	for i := range puts {
+		puts[i] = map[string]any{"id": fmt.Sprint(i)}
+	}
+
+	t.Run("without OnFailed", func(t *testing.T) {
+		d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}})
+		fake.before = failCall("BatchWriteItem", 1)
+
+		_, err := d.BatchWrite(context.Background(), BatchWriteOptions{Table: "users", Puts: puts})
+		if !errors.Is(err, DynamoDBErrBatchWrite) {
+			t.Errorf("BatchWrite() error = %v, want DynamoDBErrBatchWrite", err)
+		}
+		if fake.count("users") != 0 {
+			t.Errorf("BatchWrite() wrote %d items after the failed batch, want none", fake.count("users"))
+		}
+	})
+
+	t.Run("with OnFailed", func(t *testing.T) {
+		d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}})
+		fake.before = failCall("BatchWriteItem", 1)
+
+		var failed []FailedWrite
+		result, err := d.BatchWrite(context.Background(), BatchWriteOptions{
+			Table:    "users",
+			Puts:     puts,
+			OnFailed: func(writes []FailedWrite) { failed = append(failed, writes...) },
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result.Written != 5 || fake.count("users") != 5 {
+			t.Errorf("BatchWrite() wrote %d, want the 5 items of the second batch", result.Written)
+		}
+		if len(failed) != batchWriteLimit || len(result.FailedPuts) != batchWriteLimit {
+			t.Fatalf("BatchWrite() failed %d and reported %d, want the %d of the first batch",
+				len(result.FailedPuts), len(failed), batchWriteLimit)
+		}
+		if !errors.Is(failed[0].Err, DynamoDBErrBatchWrite) {
+			t.Errorf("OnFailed() error = %v, want the error of the batch", failed[0].Err)
+		}
+	})
+}
+
+func TestBatchWriteFailedRetry(t *testing.T) {
+	d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}})
+
+	// The first attempt leaves 3 unprocessed, and its retry fails
+	fake.unprocessed = func(request map[string]any) bool { return requestID(request) == "3" }
+	fake.before = failCall("BatchWriteItem", 2)
+
+	var failed []FailedWrite
+	result, err := d.BatchWrite(context.Background(), BatchWriteOptions{
+		Table:    "users",
+		Puts:     []any{map[string]any{"id": "1"}, map[string]any{"id": "2"}, map[string]any{"id": "3"}},
+		OnFailed: func(writes []FailedWrite) { failed = append(failed, writes...) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Written != 2 {
+		t.Errorf("BatchWrite() wrote %d, want 2", result.Written)
+	}
+	if len(failed) != 1 || itemIDs([]Item{failed[0].Put})[0] != "3" {
+		t.Fatalf("OnFailed() received %+v, want only the pending put of 3", failed)
+	}
+	if !errors.Is(failed[0].Err, DynamoDBErrBatchWrite) {
+		t.Errorf("OnFailed() error = %v, want the error of the retry", failed[0].Err)
+	}
+}
@@ -0,0 +1,112 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Budget caps what a query or scan may read, so a mistake such as a filter on
+// a missing index fails fast instead of reading the whole table. The pages
+// are checked before each request, while the scanned items and capacity are
+// only known once a page is read, so the request that crosses a limit is the
+// last one. Share one budget between operations to cap them together. Zero
+// limits and a nil budget do not cap.
+type Budget struct {
+	MaxPages        int     // Requests sent
+	MaxScannedItems int64   // Items evaluated before the filters
+	MaxCapacity     float64 // Read capacity units consumed
+
+	mu       sync.Mutex
+	pages    int
+	scanned  int64
+	capacity float64
+}
+
+// BudgetExceededError reports the limit a query or scan ran into, and what
+// it had read until then.
+type BudgetExceededError struct {
+	Limit        string // "MaxPages", "MaxScannedItems" or "MaxCapacity"
+	Pages        int
+	ScannedItems int64
+	Capacity     float64
+}
+
+var DynamoDBErrBudgetExceeded = errors.New("read budget exceeded")
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("%s: %s reached after %d pages, %d scanned items and %g capacity units",
+		DynamoDBErrBudgetExceeded, e.Limit, e.Pages, e.ScannedItems, e.Capacity)
+}
+
+func (e *BudgetExceededError) Unwrap() error {
+	return DynamoDBErrBudgetExceeded
+}
+
+// Used returns what was read against the budget so far.
+func (b *Budget) Used() (pages int, scannedItems int64, capacity float64) {
+	if b == nil {
+		return 0, 0, 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.pages, b.scanned, b.capacity
+}
+
+// tracksCapacity reports whether requests must return their consumed
+// capacity.
+func (b *Budget) tracksCapacity() bool {
+	return b != nil && b.MaxCapacity > 0
+}
+
+// check fails when a limit is already reached, before sending a request.
+func (b *Budget) check() error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.MaxPages > 0 && b.pages >= b.MaxPages {
+		return b.exceeded("MaxPages")
+	}
+	return b.over()
+}
+
+// spend adds a page to the budget and fails when it crossed a limit.
+func (b *Budget) spend(scannedCount int32, capacity *types.ConsumedCapacity) error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pages++
+	b.scanned += int64(scannedCount)
+	if capacity != nil {
+		b.capacity += aws.ToFloat64(capacity.CapacityUnits)
+	}
+
+	return b.over()
+}
+
+func (b *Budget) over() error {
+	switch {
+	case b.MaxScannedItems > 0 && b.scanned > b.MaxScannedItems:
+		return b.exceeded("MaxScannedItems")
+	case b.MaxCapacity > 0 && b.capacity > b.MaxCapacity:
+		return b.exceeded("MaxCapacity")
+	}
+	return nil
+}
+
+func (b *Budget) exceeded(limit string) error {
+	return &BudgetExceededError{Limit: limit, Pages: b.pages, ScannedItems: b.scanned, Capacity: b.capacity}
+}
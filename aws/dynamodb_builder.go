@@ -0,0 +1,211 @@
+package aws
+
+import (
+	"context"
+	"iter"
+	"time"
+)
+
+// QueryBuilder builds QueryOptions step by step, e.g.,
+//
+//	ddb.Table("movies").
+//		Index("YearGenre").
+//		Partition("year", 2020).
+//		SortBeginsWith("genre", "Com").
+//		Filter(Gt("rating", 7)).
+//		Limit(25).
+//		Run(ctx)
+type QueryBuilder struct {
+	ddb    DynamoDB
+	opts   QueryOptions
+	filter Where
+}
+
+// Table starts a query on the table.
+func (d *dynamodbService) Table(name string) *QueryBuilder {
+	return &QueryBuilder{ddb: d, opts: QueryOptions{Table: name}}
+}
+
+// Index queries the GSI or LSI instead of the base table.
+func (b *QueryBuilder) Index(name string) *QueryBuilder {
+	b.opts.Index = name
+	return b
+}
+
+// Hydrate fetches the full items from the base table when the index only
+// projects some attributes.
+func (b *QueryBuilder) Hydrate() *QueryBuilder {
+	b.opts.Hydrate = true
+	return b
+}
+
+// Partition sets the partition key value to read.
+func (b *QueryBuilder) Partition(key string, value any) *QueryBuilder {
+	b.opts.Partition = &QueryKeyValue{Key: key, Value: value}
+	return b
+}
+
+// Sort sets the sort key condition.
+func (b *QueryBuilder) Sort(key string, operator WhereOperator, value any) *QueryBuilder {
+	b.opts.Sort = &QueryKeyValue{Key: key, Value: value, Operator: operator}
+	return b
+}
+
+func (b *QueryBuilder) SortEqual(key string, value any) *QueryBuilder {
+	return b.Sort(key, Equal, value)
+}
+
+func (b *QueryBuilder) SortLessThan(key string, value any) *QueryBuilder {
+	return b.Sort(key, LessThan, value)
+}
+
+func (b *QueryBuilder) SortLessThanEqual(key string, value any) *QueryBuilder {
+	return b.Sort(key, LessThanEqual, value)
+}
+
+func (b *QueryBuilder) SortGreaterThan(key string, value any) *QueryBuilder {
+	return b.Sort(key, GreaterThan, value)
+}
+
+func (b *QueryBuilder) SortGreaterThanEqual(key string, value any) *QueryBuilder {
+	return b.Sort(key, GreaterThanEqual, value)
+}
+
+func (b *QueryBuilder) SortBeginsWith(key string, prefix string) *QueryBuilder {
+	return b.Sort(key, BeginsWith, prefix)
+}
+
+// SortPrefix matches composite sort keys under the parts, e.g.,
+// SortPrefix("SK", "ORDER") for keys starting with "ORDER#".
+func (b *QueryBuilder) SortPrefix(key string, parts ...any) *QueryBuilder {
+	b.opts.Sort = KeyBeginsWith(key, parts...)
+	return b
+}
+
+// SortBetween matches sort keys from low to high, inclusive.
+func (b *QueryBuilder) SortBetween(key string, low, high any) *QueryBuilder {
+	b.opts.Sort = &QueryKeyValue{Key: key, Value: low, Value2: high, Operator: Between}
+	return b
+}
+
+// SortBetweenTimes matches sort keys from one time to another, inclusive,
+// encoded with the TimeFormat.
+func (b *QueryBuilder) SortBetweenTimes(key string, from, to time.Time) *QueryBuilder {
+	b.opts.Sort = TimeBetween(key, from, to)
+	return b
+}
+
+// SortSince matches sort keys at or after the time.
+func (b *QueryBuilder) SortSince(key string, since time.Time) *QueryBuilder {
+	b.opts.Sort = TimeSince(key, since)
+	return b
+}
+
+// SortLastHours matches sort keys within the last hours.
+func (b *QueryBuilder) SortLastHours(key string, hours int) *QueryBuilder {
+	b.opts.Sort = LastHours(key, hours)
+	return b
+}
+
+// TimeFormat sets how time.Time key values are encoded, e.g.,
+// TimeUnixMilli.
+func (b *QueryBuilder) TimeFormat(format TimeFormat) *QueryBuilder {
+	b.opts.TimeFormat = format
+	return b
+}
+
+// Filter adds conditions on non-key attributes. All the conditions and groups
+// added must hold.
+func (b *QueryBuilder) Filter(conditions ...Condition) *QueryBuilder {
+	for _, condition := range conditions {
+		condition.addTo(&b.filter)
+	}
+	return b
+}
+
+// Where adds a group of conditions, e.g., OrGroup(...).
+func (b *QueryBuilder) Where(where Where) *QueryBuilder {
+	where.addTo(&b.filter)
+	return b
+}
+
+// Limit sets the number of items per page.
+func (b *QueryBuilder) Limit(limit int32) *QueryBuilder {
+	b.opts.Limit = limit
+	return b
+}
+
+// Cursor continues from the NextCursor of a previous result.
+func (b *QueryBuilder) Cursor(cursor string) *QueryBuilder {
+	b.opts.Cursor = cursor
+	return b
+}
+
+// Descending returns the items in descending sort key order.
+func (b *QueryBuilder) Descending() *QueryBuilder {
+	b.opts.Order = Descending
+	return b
+}
+
+// Fields sets the attributes to return.
+func (b *QueryBuilder) Fields(fields ...string) *QueryBuilder {
+	b.opts.Fields = fields
+	return b
+}
+
+// Budget caps what the query may read, see Budget.
+func (b *QueryBuilder) Budget(budget *Budget) *QueryBuilder {
+	b.opts.Budget = budget
+	return b
+}
+
+// ReturnConsumedCapacity reports the capacity used in the result.
+func (b *QueryBuilder) ReturnConsumedCapacity() *QueryBuilder {
+	b.opts.ReturnConsumedCapacity = true
+	return b
+}
+
+// Options returns the built query options.
+func (b *QueryBuilder) Options() QueryOptions {
+	opts := b.opts
+	if len(b.filter.Conditions) > 0 || len(b.filter.Groups) > 0 {
+		filter := b.filter
+		opts.Where = &filter
+	}
+	return opts
+}
+
+// Run reads up to Limit items, see DynamoDB.Query.
+func (b *QueryBuilder) Run(ctx context.Context) (*QueryResult, error) {
+	return b.ddb.Query(ctx, b.Options())
+}
+
+// Page reads a single page, see DynamoDB.QueryPage.
+func (b *QueryBuilder) Page(ctx context.Context) (*QueryResult, error) {
+	return b.ddb.QueryPage(ctx, b.Options())
+}
+
+// All reads every page up to maxItems, see DynamoDB.QueryAll.
+func (b *QueryBuilder) All(ctx context.Context, maxItems int) (*QueryResult, error) {
+	return b.ddb.QueryAll(ctx, b.Options(), maxItems)
+}
+
+// Items iterates over the items page by page, see DynamoDB.QueryItems.
+func (b *QueryBuilder) Items(ctx context.Context) iter.Seq2[Item, error] {
+	return b.ddb.QueryItems(ctx, b.Options())
+}
+
+// Aggregate reads every item and aggregates them, see AggregateQuery.
+func (b *QueryBuilder) Aggregate(ctx context.Context, opts AggregateOptions) (*AggregateResult, error) {
+	return AggregateQuery(ctx, b.ddb, b.Options(), opts)
+}
+
+// Explain returns the request the query would send, see DynamoDB.Explain.
+func (b *QueryBuilder) Explain(ctx context.Context) (*QueryPlan, error) {
+	return b.ddb.Explain(ctx, b.Options())
+}
+
+// Count counts the matching items, see DynamoDB.Count.
+func (b *QueryBuilder) Count(ctx context.Context) (*CountResult, error) {
+	return b.ddb.Count(ctx, b.Options())
+}
@@ -0,0 +1,171 @@
+package aws
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var defaultBulkWorkers = 4
+
+type (
+	BulkWriteOptions struct {
+		Table      string
+		Items      []any            // Structs, maps or attribute value maps to write
+		Workers    int              // Batches written in parallel, defaults to 4
+		MaxRetries int              // Retries for unprocessed items, defaults to 5
+		Limiter    *CapacityLimiter // Optional write capacity budget shared by the workers
+		// Reject items larger than this many bytes with an *ItemTooLargeError,
+		// e.g., ItemSizeLimit. Zero skips the check.
+		MaxItemSize int
+		// Optional dead-letter hook receiving the items of each batch that
+		// could not be written, from one worker at a time. When set, failed
+		// batch requests and items over MaxItemSize no longer stop the
+		// workers.
+		OnFailed func(failed []FailedWrite)
+		// Called after each batch, from one worker at a time
+		OnProgress func(BulkWriteProgress)
+		// Report the capacity used in the result
+		ReturnConsumedCapacity bool
+	}
+
+	BulkWriteProgress struct {
+		Total   int // Number of items to write
+		Written int
+		Failed  int // Items still unprocessed after all retries
+	}
+)
+
+// BulkWrite puts the items in batches of 25 written by parallel workers, for
+// loads too large for a single BatchWrite call. Items are marshalled as their
+// batch is picked up, and the first error stops all workers. Items that still
+// fail after all retries are reported in the result instead of as an error.
+func (d *dynamodbService) BulkWrite(ctx context.Context, opts BulkWriteOptions) (*BatchWriteResult, error) {
+	if opts.Table == "" {
+		return nil, DynamoDBErrTableNotSet
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = defaultBulkWorkers
+	}
+
+	batchOpts := BatchWriteOptions{
+		Table:                  opts.Table,
+		MaxRetries:             opts.MaxRetries,
+		Limiter:                opts.Limiter,
+		ReturnConsumedCapacity: opts.ReturnConsumedCapacity,
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		result   = &BatchWriteResult{}
+		progress = BulkWriteProgress{Total: len(opts.Items)}
+		firstErr error
+		batches  = make(chan []any)
+	)
+
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for batch := range batches {
+				var (
+					requests = make([]types.WriteRequest, 0, len(batch))
+					failed   []FailedWrite
+				)
+				for _, item := range batch {
+					av, err := MarshalItem(item, d.marshal)
+					if err == nil {
+						av, err = d.storeItem(ctx, opts.Table, av)
+					}
+					if err == nil {
+						err = checkItemSize(av, opts.MaxItemSize)
+						if err != nil && opts.OnFailed != nil {
+							failed = append(failed, FailedWrite{Table: opts.Table, Put: av, Err: err})
+							continue
+						}
+					}
+					if err != nil {
+						fail(err)
+						return
+					}
+					requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: av}})
+				}
+
+				// Each batch tracks its own capacity, merged below
+				written := &BatchWriteResult{}
+				var unprocessed []types.WriteRequest
+				if len(requests) > 0 {
+					var err error
+					unprocessed, err = d.batchWrite(ctx, batchOpts, requests, written)
+					if err != nil {
+						if opts.OnFailed == nil || ctx.Err() != nil {
+							mu.Lock()
+							result.Written += len(requests) - len(unprocessed)
+							mu.Unlock()
+							fail(err)
+							return
+						}
+					} else {
+						err = DynamoDBErrUnprocessed
+					}
+					failed = append(failed, failedWrites(opts.Table, unprocessed, err)...)
+				}
+
+				mu.Lock()
+				result.Written += len(requests) - len(unprocessed)
+				for _, write := range failed {
+					result.FailedPuts = append(result.FailedPuts, write.Put)
+				}
+				result.ConsumedCapacity = mergeCapacity(result.ConsumedCapacity, written.ConsumedCapacity)
+				if opts.OnFailed != nil && len(failed) > 0 {
+					opts.OnFailed(failed)
+				}
+
+				progress.Written = result.Written
+				progress.Failed = len(result.FailedPuts)
+				if opts.OnProgress != nil {
+					opts.OnProgress(progress)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+send:
+	for batch := range slices.Chunk(opts.Items, batchWriteLimit) {
+		select {
+		case batches <- batch:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(batches)
+	wg.Wait()
+
+	if firstErr != nil {
+		return result, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
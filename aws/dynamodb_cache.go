@@ -0,0 +1,255 @@
+package aws
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var defaultCacheTTL = time.Minute
+
+// minCacheSweep is the number of entries of a MemoryCache before expired
+// entries are swept.
+const minCacheSweep = 64
+
+type (
+	// Cache stores read results by key. Implementations must be safe for
+	// concurrent use, e.g., NewMemoryCache or one backed by Redis.
+	Cache interface {
+		Get(key string) (any, bool)
+		Set(key string, value any, ttl time.Duration)
+		// Invalidate removes every entry whose key starts with the prefix
+		Invalidate(prefix string)
+	}
+
+	// CacheConfig caches the results of gets and queries. Writes through the
+	// same client invalidate the entries of the table they write to, while
+	// writes by other clients are only seen once the entries expire.
+	// Consistent reads bypass the cache. Results are copied in and out of the
+	// cache, so callers can change them freely. Cache hits don't read the
+	// table, so they are neither counted by a Budget nor slowed down by a
+	// CapacityLimiter of the options.
+	CacheConfig struct {
+		Cache Cache         // Defaults to a NewMemoryCache
+		TTL   time.Duration // Defaults to 1 minute
+	}
+
+	// MemoryCache is an in-process Cache. Expired entries are dropped when
+	// read, and swept once the cache doubles in size since the last sweep,
+	// so writes stay cheap however many entries it holds.
+	MemoryCache struct {
+		mu      sync.Mutex
+		entries map[string]cacheEntry
+		sweepAt int // Number of entries starting the next sweep
+	}
+
+	// cacheable is a result copied in and out of the cache, so callers and
+	// the cache never share items.
+	cacheable[T any] interface {
+		*T
+		clone() *T
+	}
+
+	cacheEntry struct {
+		value   any
+		expires time.Time
+	}
+)
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: map[string]cacheEntry{}, sweepAt: minCacheSweep}
+}
+
+func (c *MemoryCache) Get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	return entry.value, true
+}
+
+func (c *MemoryCache) Set(key string, value any, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if len(c.entries) >= c.sweepAt {
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		c.sweepAt = max(2*len(c.entries), minCacheSweep)
+	}
+	c.entries[key] = cacheEntry{value: value, expires: now.Add(ttl)}
+}
+
+func (c *MemoryCache) Invalidate(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// cacheKey identifies a read of the table by its operation and options. It is
+// empty when the read cannot be cached.
+func (d *dynamodbService) cacheKey(table, operation string, opts any) string {
+	if d.cache == nil {
+		return ""
+	}
+
+	data, err := json.Marshal(opts)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(data)
+	return cachePrefix(table) + operation + "/" + hex.EncodeToString(sum[:])
+}
+
+// cachedResult returns a copy of the cached result, so callers can change it
+// without changing the cache.
+func cachedResult[T any, P cacheable[T]](d *dynamodbService, key string) (*T, bool) {
+	if key == "" {
+		return nil, false
+	}
+
+	value, ok := d.cache.Cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	result, ok := value.(*T)
+	if !ok {
+		return nil, false
+	}
+
+	return P(result).clone(), true
+}
+
+func storeResult[T any, P cacheable[T]](d *dynamodbService, key string, result *T) {
+	if key == "" {
+		return
+	}
+
+	ttl := d.cache.TTL
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+
+	d.cache.Cache.Set(key, P(result).clone(), ttl)
+}
+
+// invalidate removes the cached reads of the tables, or of every table when
+// none are given.
+func (d *dynamodbService) invalidate(tables ...string) {
+	if d.cache == nil {
+		return
+	}
+
+	if len(tables) == 0 {
+		d.cache.Cache.Invalidate("")
+		return
+	}
+	for _, table := range tables {
+		d.cache.Cache.Invalidate(cachePrefix(table))
+	}
+}
+
+func cachePrefix(table string) string {
+	return table + "/"
+}
+
+func (r *GetResult) clone() *GetResult {
+	cloned := *r
+	cloned.Item = cloneItem(r.Item)
+	cloned.ConsumedCapacity = cloneCapacity(r.ConsumedCapacity)
+	return &cloned
+}
+
+func (r *QueryResult) clone() *QueryResult {
+	cloned := *r
+	if r.Items != nil {
+		cloned.Items = make([]Item, len(r.Items))
+		for i, item := range r.Items {
+			cloned.Items[i] = cloneItem(item)
+		}
+	}
+	cloned.LastEvaluatedKey = cloneItem(r.LastEvaluatedKey)
+	cloned.ConsumedCapacity = cloneCapacity(r.ConsumedCapacity)
+	cloned.Pages = slices.Clone(r.Pages)
+	return &cloned
+}
+
+func cloneCapacity(capacity *ConsumedCapacity) *ConsumedCapacity {
+	if capacity == nil {
+		return nil
+	}
+	cloned := *capacity
+	return &cloned
+}
+
+func cloneItem(item Item) Item {
+	if item == nil {
+		return nil
+	}
+
+	cloned := make(Item, len(item))
+	for name, value := range item {
+		cloned[name] = cloneAttributeValue(value)
+	}
+	return cloned
+}
+
+func cloneAttributeValue(value types.AttributeValue) types.AttributeValue {
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		return &types.AttributeValueMemberS{Value: v.Value}
+	case *types.AttributeValueMemberN:
+		return &types.AttributeValueMemberN{Value: v.Value}
+	case *types.AttributeValueMemberB:
+		return &types.AttributeValueMemberB{Value: bytes.Clone(v.Value)}
+	case *types.AttributeValueMemberBOOL:
+		return &types.AttributeValueMemberBOOL{Value: v.Value}
+	case *types.AttributeValueMemberNULL:
+		return &types.AttributeValueMemberNULL{Value: v.Value}
+	case *types.AttributeValueMemberSS:
+		return &types.AttributeValueMemberSS{Value: slices.Clone(v.Value)}
+	case *types.AttributeValueMemberNS:
+		return &types.AttributeValueMemberNS{Value: slices.Clone(v.Value)}
+	case *types.AttributeValueMemberBS:
+		values := make([][]byte, len(v.Value))
+		for i, b := range v.Value {
+			values[i] = bytes.Clone(b)
+		}
+		return &types.AttributeValueMemberBS{Value: values}
+	case *types.AttributeValueMemberL:
+		values := make([]types.AttributeValue, len(v.Value))
+		for i, element := range v.Value {
+			values[i] = cloneAttributeValue(element)
+		}
+		return &types.AttributeValueMemberL{Value: values}
+	case *types.AttributeValueMemberM:
+		return &types.AttributeValueMemberM{Value: cloneItem(v.Value)}
+	default:
+		return value
+	}
+}
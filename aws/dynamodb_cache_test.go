@@ -0,0 +1,126 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestNewDynamoDBDefaultCache(t *testing.T) {
+	d := NewDynamoDB(Config{Region: "us-east-1", Cache: &CacheConfig{TTL: time.Minute}}).(*dynamodbService)
+	if _, ok := d.cache.Cache.(*MemoryCache); !ok {
+		t.Fatalf("Cache = %T, want *MemoryCache", d.cache.Cache)
+	}
+	if d.cache.TTL != time.Minute {
+		t.Errorf("TTL = %s, want 1m", d.cache.TTL)
+	}
+}
+
+func TestCachedGet(t *testing.T) {
+	ctx := context.Background()
+	d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}})
+	d.cache = &CacheConfig{Cache: NewMemoryCache()}
+
+	if _, err := d.Put(ctx, "users", map[string]any{"id": "1", "name": "Ada", "tags": []string{"a"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func() Item {
+		t.Helper()
+		result, err := d.Get(ctx, GetOptions{Table: "users", Key: Key{"id": "1"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.Item
+	}
+
+	// Changing a result doesn't change the cached one
+	first := get()
+	first["name"].(*types.AttributeValueMemberS).Value = "changed"
+	first["tags"].(*types.AttributeValueMemberL).Value[0] = &types.AttributeValueMemberS{Value: "changed"}
+	delete(first, "id")
+
+	second := get()
+	if name := second["name"].(*types.AttributeValueMemberS).Value; name != "Ada" {
+		t.Errorf("cached name = %q, want Ada", name)
+	}
+	if tag := second["tags"].(*types.AttributeValueMemberL).Value[0].(*types.AttributeValueMemberS).Value; tag != "a" {
+		t.Errorf("cached tag = %q, want a", tag)
+	}
+	if _, ok := second["id"]; !ok {
+		t.Error("cached item lost its id")
+	}
+	if n := fake.calls["GetItem"]; n != 1 {
+		t.Errorf("%d GetItem calls, want 1", n)
+	}
+
+	// Writes invalidate the table
+	if _, err := d.Put(ctx, "users", map[string]any{"id": "1", "name": "Grace"}); err != nil {
+		t.Fatal(err)
+	}
+	if name := get()["name"].(*types.AttributeValueMemberS).Value; name != "Grace" {
+		t.Errorf("name after Put = %q, want Grace", name)
+	}
+	if n := fake.calls["GetItem"]; n != 2 {
+		t.Errorf("%d GetItem calls, want 2", n)
+	}
+
+	// Consistent reads bypass the cache
+	if _, err := d.Get(ctx, GetOptions{Table: "users", Key: Key{"id": "1"}, ConsistentRead: true}); err != nil {
+		t.Fatal(err)
+	}
+	if n := fake.calls["GetItem"]; n != 3 {
+		t.Errorf("%d GetItem calls, want 3", n)
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	cache := NewMemoryCache()
+
+	cache.Set("users/a", 1, time.Minute)
+	cache.Set("users/b", 2, -time.Second)
+	cache.Set("orders/a", 3, time.Minute)
+
+	if v, ok := cache.Get("users/a"); !ok || v != 1 {
+		t.Errorf("Get(users/a) = %v, %v", v, ok)
+	}
+	if _, ok := cache.Get("users/b"); ok {
+		t.Error("expired entry returned")
+	}
+
+	cache.Invalidate("users/")
+	if _, ok := cache.Get("users/a"); ok {
+		t.Error("invalidated entry returned")
+	}
+	if _, ok := cache.Get("orders/a"); !ok {
+		t.Error("entry of another prefix invalidated")
+	}
+}
+
+func TestMemoryCacheSweep(t *testing.T) {
+	cache := NewMemoryCache()
+
+	// Expired entries that are never read are swept as the cache grows
+	for i := range 10 * minCacheSweep {
+		cache.Set(fmt.Sprint("expired/", i), i, -time.Second)
+	}
+	if n := len(cache.entries); n > minCacheSweep {
+		t.Errorf("%d entries after sweeps, want at most %d", n, minCacheSweep)
+	}
+
+	// Live entries are kept, and sweeps get rarer as they grow
+	for i := range 10 * minCacheSweep {
+		cache.Set(fmt.Sprint("live/", i), i, time.Minute)
+	}
+	for i := range 10 * minCacheSweep {
+		if _, ok := cache.Get(fmt.Sprint("live/", i)); !ok {
+			t.Fatalf("live entry %d missing", i)
+		}
+	}
+	if cache.sweepAt <= len(cache.entries) {
+		t.Errorf("next sweep at %d entries, want above %d", cache.sweepAt, len(cache.entries))
+	}
+}
@@ -0,0 +1,59 @@
+package aws
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ConsumedCapacity is the capacity used by an operation, summed over all of
+// the requests it made.
+type ConsumedCapacity struct {
+	CapacityUnits      float64
+	ReadCapacityUnits  float64
+	WriteCapacityUnits float64
+}
+
+func capacityMode(enabled bool) types.ReturnConsumedCapacity {
+	if enabled {
+		return types.ReturnConsumedCapacityTotal
+	}
+	return types.ReturnConsumedCapacityNone
+}
+
+// addCapacity adds the consumed capacity of a response to the total. The
+// total stays nil until capacity is reported.
+func addCapacity(total *ConsumedCapacity, responses ...types.ConsumedCapacity) *ConsumedCapacity {
+	for _, c := range responses {
+		if total == nil {
+			total = &ConsumedCapacity{}
+		}
+
+		total.CapacityUnits += aws.ToFloat64(c.CapacityUnits)
+		total.ReadCapacityUnits += aws.ToFloat64(c.ReadCapacityUnits)
+		total.WriteCapacityUnits += aws.ToFloat64(c.WriteCapacityUnits)
+	}
+
+	return total
+}
+
+// consumed converts an optional single response capacity to a slice for
+// addCapacity.
+func consumed(c *types.ConsumedCapacity) []types.ConsumedCapacity {
+	if c == nil {
+		return nil
+	}
+	return []types.ConsumedCapacity{*c}
+}
+
+// mergeCapacity adds the capacity used by another operation to the total.
+func mergeCapacity(total, c *ConsumedCapacity) *ConsumedCapacity {
+	if c == nil {
+		return total
+	}
+
+	return addCapacity(total, types.ConsumedCapacity{
+		CapacityUnits:      aws.Float64(c.CapacityUnits),
+		ReadCapacityUnits:  aws.Float64(c.ReadCapacityUnits),
+		WriteCapacityUnits: aws.Float64(c.WriteCapacityUnits),
+	})
+}
@@ -0,0 +1,83 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+)
+
+// ChangeHandlers receive the stream records of a table decoded into T. Nil
+// handlers skip their events. When the stream does not include an image, T
+// is decoded from the keys of the record.
+type ChangeHandlers[T any] struct {
+	OnInsert func(ctx context.Context, record StreamRecord, item T) error
+	OnModify func(ctx context.Context, record StreamRecord, old, updated T) error
+	OnRemove func(ctx context.Context, record StreamRecord, old T) error
+}
+
+// Dispatch returns a stream handler that decodes the images of each record
+// and routes it to the handler of its event, e.g.,
+//
+//	NewStreamConsumer(config, StreamConsumerOptions{
+//		Table: "users",
+//		Handler: Dispatch(ChangeHandlers[User]{
+//			OnInsert: func(ctx context.Context, _ StreamRecord, u User) error { ... },
+//		}),
+//	})
+//
+// The images are decoded as the record holds them: a StreamConsumer has
+// already decrypted and rehydrated them, while records built elsewhere, e.g.,
+// from a Lambda event, still hold encrypted fields and offloaded pointers.
+func Dispatch[T any](handlers ChangeHandlers[T], opts ...MarshalOptions) StreamHandler {
+	decode := func(record StreamRecord, image Item) (T, error) {
+		if image == nil {
+			image = record.Keys
+		}
+
+		item, err := UnmarshalItem[T](image, opts...)
+		if err != nil {
+			return item, fmt.Errorf("record %s: %w", record.EventID, err)
+		}
+		return item, nil
+	}
+
+	return func(ctx context.Context, record StreamRecord) error {
+		switch record.EventName {
+		case StreamInsert:
+			if handlers.OnInsert == nil {
+				return nil
+			}
+
+			item, err := decode(record, record.NewImage)
+			if err != nil {
+				return err
+			}
+			return handlers.OnInsert(ctx, record, item)
+		case StreamModify:
+			if handlers.OnModify == nil {
+				return nil
+			}
+
+			old, err := decode(record, record.OldImage)
+			if err != nil {
+				return err
+			}
+			item, err := decode(record, record.NewImage)
+			if err != nil {
+				return err
+			}
+			return handlers.OnModify(ctx, record, old, item)
+		case StreamRemove:
+			if handlers.OnRemove == nil {
+				return nil
+			}
+
+			old, err := decode(record, record.OldImage)
+			if err != nil {
+				return err
+			}
+			return handlers.OnRemove(ctx, record, old)
+		default:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package aws
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+type (
+	// GenerateOptions sets the names of the generated code.
+	GenerateOptions struct {
+		Package string // Required
+		// Prefix of the generated constants and name of the accessor type,
+		// defaults to the table name in CamelCase, e.g., "UserOrders"
+		Type string
+	}
+
+	generateData struct {
+		Package      string
+		Type         string
+		Table        string
+		PartitionKey generateKey
+		SortKey      *generateKey
+		Indexes      []generateIndex
+	}
+
+	generateIndex struct {
+		Name         string
+		Const        string // e.g., "StatusIndex"
+		PartitionKey generateKey
+		SortKey      *generateKey
+	}
+
+	generateKey struct {
+		Name  string
+		Param string // Go parameter name
+		Type  string // Go type
+	}
+)
+
+var DynamoDBErrGenerate = errors.New("failed to generate code")
+
+// GenerateAccessors returns the Go source of typed accessors for the table:
+// constants for the table, index and key attribute names, and a type whose
+// methods take the keys with their Go types, e.g., for a "user-orders" table
+// keyed on "PK" and "SK" with a "StatusIndex":
+//
+//	orders := NewUserOrders(ddb)
+//	result, err := orders.Get(ctx, "USER#1", "ORDER#7")
+//	pending, err := orders.QueryStatusIndex("PENDING").Limit(10).Run(ctx)
+//
+// String keys are string parameters, number keys int64 and binary keys
+// []byte. The source is meant to be written by go:generate, see the
+// generate command.
+func GenerateAccessors(schema TableSchema, opts GenerateOptions) ([]byte, error) {
+	if schema.Table == "" {
+		return nil, DynamoDBErrTableNotSet
+	}
+	if schema.PartitionKey.Name == "" {
+		return nil, DynamoDBErrKeyNotSet
+	}
+	if !token.IsIdentifier(opts.Package) {
+		return nil, fmt.Errorf("%w: invalid package name %q", DynamoDBErrGenerate, opts.Package)
+	}
+
+	name := opts.Type
+	if name == "" {
+		name = goName(schema.Table)
+	}
+	if !token.IsIdentifier(name) || !token.IsExported(name) {
+		return nil, fmt.Errorf("%w: invalid type name %q", DynamoDBErrGenerate, name)
+	}
+
+	data := generateData{
+		Package:      opts.Package,
+		Type:         name,
+		Table:        schema.Table,
+		PartitionKey: newGenerateKey(schema.PartitionKey),
+	}
+	if schema.SortKey != nil {
+		sort := newGenerateKey(*schema.SortKey)
+		// Key names differing only in case, e.g., "PK" and "pk", get the
+		// same parameter name
+		if sort.Param == data.PartitionKey.Param {
+			sort.Param += "2"
+		}
+		data.SortKey = &sort
+	}
+
+	for _, index := range schema.GlobalIndexes {
+		generated := generateIndex{
+			Name:         index.Name,
+			Const:        indexConst(index.Name),
+			PartitionKey: newGenerateKey(index.PartitionKey),
+		}
+		if index.SortKey != nil {
+			sort := newGenerateKey(*index.SortKey)
+			generated.SortKey = &sort
+		}
+		data.Indexes = append(data.Indexes, generated)
+	}
+	for _, index := range schema.LocalIndexes {
+		sort := newGenerateKey(index.SortKey)
+		data.Indexes = append(data.Indexes, generateIndex{
+			Name:         index.Name,
+			Const:        indexConst(index.Name),
+			PartitionKey: data.PartitionKey,
+			SortKey:      &sort,
+		})
+	}
+
+	consts := map[string]string{}
+	for _, index := range data.Indexes {
+		if other, ok := consts[index.Const]; ok {
+			return nil, fmt.Errorf("%w: indexes %q and %q are both named %s", DynamoDBErrGenerate, other, index.Name, index.Const)
+		}
+		consts[index.Const] = index.Name
+	}
+
+	var b bytes.Buffer
+	if err := accessorsTemplate.Execute(&b, data); err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrGenerate, err)
+	}
+
+	source, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrGenerate, err)
+	}
+
+	return source, nil
+}
+
+// Schema returns the key schema and indexes of the described table, e.g., to
+// generate accessors for an existing table.
+func (t *TableDescription) Schema() TableSchema {
+	schema := TableSchema{
+		Table:        t.Name,
+		PartitionKey: t.PartitionKey,
+		SortKey:      t.SortKey,
+		BillingMode:  t.BillingMode,
+		Throughput:   t.Throughput,
+	}
+
+	for _, index := range t.Indexes {
+		if index.Global {
+			schema.GlobalIndexes = append(schema.GlobalIndexes, GlobalIndex{
+				Name:         index.Name,
+				PartitionKey: index.PartitionKey,
+				SortKey:      index.SortKey,
+				Projection:   index.Projection,
+			})
+			continue
+		}
+		if index.SortKey != nil {
+			schema.LocalIndexes = append(schema.LocalIndexes, LocalIndex{
+				Name:       index.Name,
+				SortKey:    *index.SortKey,
+				Projection: index.Projection,
+			})
+		}
+	}
+
+	return schema
+}
+
+func newGenerateKey(key KeyAttribute) generateKey {
+	param := goName(key.Name)
+	if param == "" {
+		param = "Key"
+	}
+	if strings.ToUpper(param) == param {
+		param = strings.ToLower(param) // e.g., "PK" or "ID"
+	} else {
+		param = string(unicode.ToLower(rune(param[0]))) + param[1:]
+	}
+	if token.IsKeyword(param) || param == "ctx" || param == "t" || param == "err" {
+		param += "Value"
+	}
+
+	goType := "string"
+	switch key.Type {
+	case AttributeNumber:
+		goType = "int64"
+	case AttributeBinary:
+		goType = "[]byte"
+	}
+
+	return generateKey{Name: key.Name, Param: param, Type: goType}
+}
+
+// indexConst names the constant of an index, ending with "Index".
+func indexConst(name string) string {
+	constant := goName(name)
+	if !strings.HasSuffix(constant, "Index") {
+		constant += "Index"
+	}
+	return constant
+}
+
+// goName converts a name such as "user-orders" or "created_at" to CamelCase.
+func goName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if b.Len() == 0 && unicode.IsDigit(r) {
+			b.WriteByte('T')
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var accessorsTemplate = template.Must(template.New("accessors").Parse(`// Code generated by hephaestus generate. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+
+	"github.com/ricomonster/hephaestus/aws"
+)
+
+// Names of the {{.Table}} table, its indexes and key attributes
+const (
+	{{.Type}}Table = {{printf "%q" .Table}}
+	{{.Type}}PartitionKey = {{printf "%q" .PartitionKey.Name}}
+	{{- if .SortKey}}
+	{{.Type}}SortKey = {{printf "%q" .SortKey.Name}}
+	{{- end}}
+{{- range .Indexes}}
+
+	{{$.Type}}{{.Const}} = {{printf "%q" .Name}}
+	{{$.Type}}{{.Const}}PartitionKey = {{printf "%q" .PartitionKey.Name}}
+	{{- if .SortKey}}
+	{{$.Type}}{{.Const}}SortKey = {{printf "%q" .SortKey.Name}}
+	{{- end}}
+{{- end}}
+)
+
+// {{.Type}} reads and writes the {{.Table}} table by its key schema.
+type {{.Type}} struct {
+	ddb aws.DynamoDB
+}
+
+func New{{.Type}}(ddb aws.DynamoDB) *{{.Type}} {
+	return &{{.Type}}{ddb: ddb}
+}
+
+// Key returns the key of an item.
+func (t *{{.Type}}) Key({{template "params" .}}) aws.Key {
+	return aws.Key{
+		{{.Type}}PartitionKey: {{.PartitionKey.Param}},
+		{{- if .SortKey}}
+		{{.Type}}SortKey: {{.SortKey.Param}},
+		{{- end}}
+	}
+}
+
+// Get fetches an item by its key, returning aws.DynamoDBErrItemNotFound when
+// it does not exist.
+func (t *{{.Type}}) Get(ctx context.Context, {{template "params" .}}) (*aws.GetResult, error) {
+	return t.ddb.Get(ctx, aws.GetOptions{Table: {{.Type}}Table, Key: t.Key({{template "args" .}})})
+}
+
+// Put creates or replaces the item.
+func (t *{{.Type}}) Put(ctx context.Context, item any, opts ...aws.PutOptions) (*aws.PutResult, error) {
+	return t.ddb.Put(ctx, {{.Type}}Table, item, opts...)
+}
+
+// Delete removes an item by its key.
+func (t *{{.Type}}) Delete(ctx context.Context, {{template "params" .}}) (*aws.DeleteResult, error) {
+	return t.ddb.Delete(ctx, aws.DeleteOptions{Table: {{.Type}}Table, Key: t.Key({{template "args" .}})})
+}
+
+// Query starts a query of the partition.
+func (t *{{.Type}}) Query({{.PartitionKey.Param}} {{.PartitionKey.Type}}) *aws.QueryBuilder {
+	return t.ddb.Table({{.Type}}Table).Partition({{.Type}}PartitionKey, {{.PartitionKey.Param}})
+}
+{{- range .Indexes}}
+
+// Query{{.Const}} starts a query of the partition of the {{.Name}} index.
+func (t *{{$.Type}}) Query{{.Const}}({{.PartitionKey.Param}} {{.PartitionKey.Type}}) *aws.QueryBuilder {
+	return t.ddb.Table({{$.Type}}Table).Index({{$.Type}}{{.Const}}).Partition({{$.Type}}{{.Const}}PartitionKey, {{.PartitionKey.Param}})
+}
+{{- end}}
+{{define "params"}}{{.PartitionKey.Param}} {{.PartitionKey.Type}}{{if .SortKey}}, {{.SortKey.Param}} {{.SortKey.Type}}{{end}}{{end}}
+{{- define "args"}}{{.PartitionKey.Param}}{{if .SortKey}}, {{.SortKey.Param}}{{end}}{{end}}
+`))
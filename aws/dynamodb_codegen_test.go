@@ -0,0 +1,117 @@
+package aws
+
+import (
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestGenerateAccessors(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema TableSchema
+		opts   GenerateOptions
+	}{
+		{
+			name: "user_orders",
+			schema: TableSchema{
+				Table:        "user-orders",
+				PartitionKey: KeyAttribute{Name: "PK"},
+				SortKey:      &KeyAttribute{Name: "SK"},
+				GlobalIndexes: []GlobalIndex{{
+					Name:         "StatusIndex",
+					PartitionKey: KeyAttribute{Name: "status"},
+					SortKey:      &KeyAttribute{Name: "created_at", Type: AttributeNumber},
+				}},
+				LocalIndexes: []LocalIndex{{
+					Name:    "total-index",
+					SortKey: KeyAttribute{Name: "total", Type: AttributeNumber},
+				}},
+			},
+			opts: GenerateOptions{Package: "tables"},
+		},
+		{
+			name: "same_param",
+			schema: TableSchema{
+				Table:        "events",
+				PartitionKey: KeyAttribute{Name: "PK"},
+				SortKey:      &KeyAttribute{Name: "pk", Type: AttributeBinary},
+			},
+			opts: GenerateOptions{Package: "tables", Type: "Events"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := GenerateAccessors(tt.schema, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkParams(t, source)
+
+			golden := filepath.Join("testdata", tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, source, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(source, want) {
+				t.Errorf("source differs from %s, run with -update to see the diff:\n%s", golden, source)
+			}
+		})
+	}
+}
+
+func TestGenerateAccessorsDuplicateIndex(t *testing.T) {
+	_, err := GenerateAccessors(TableSchema{
+		Table:        "users",
+		PartitionKey: KeyAttribute{Name: "id"},
+		GlobalIndexes: []GlobalIndex{
+			{Name: "email-index", PartitionKey: KeyAttribute{Name: "email"}},
+			{Name: "email_index", PartitionKey: KeyAttribute{Name: "email"}},
+		},
+	}, GenerateOptions{Package: "tables"})
+	if err == nil {
+		t.Fatal("want an error for indexes with the same Go name")
+	}
+}
+
+// checkParams fails when a function of the source repeats a parameter name,
+// which gofmt accepts but the compiler doesn't.
+func checkParams(t *testing.T, source []byte) {
+	t.Helper()
+
+	file, err := parser.ParseFile(token.NewFileSet(), "", source, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+
+		seen := map[string]bool{}
+		for _, field := range fn.Type.Params.List {
+			for _, name := range field.Names {
+				if seen[name.Name] {
+					t.Errorf("%s repeats parameter %s", fn.Name.Name, name.Name)
+				}
+				seen[name.Name] = true
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package aws
+
+// Condition is a WhereCondition or a nested Where group, e.g.,
+//
+//	AndGroup(Eq("status", "active"), OrGroup(Gt("price", 10), Exists("sale")))
+type Condition interface {
+	addTo(where *Where)
+	negate() Condition
+}
+
+func (c WhereCondition) addTo(where *Where) {
+	where.Conditions = append(where.Conditions, c)
+}
+
+func (c WhereCondition) negate() Condition {
+	c.Not = !c.Not
+	return c
+}
+
+func (w Where) addTo(where *Where) {
+	where.Groups = append(where.Groups, w)
+}
+
+func (w Where) negate() Condition {
+	w.Not = !w.Not
+	return w
+}
+
+// Not negates a condition or group, e.g., Not(Prefix("sku", "TEST-")).
+func Not(condition Condition) Condition {
+	return condition.negate()
+}
+
+// AndGroup returns a group where all the conditions must hold.
+func AndGroup(conditions ...Condition) Where {
+	return group(AND, conditions)
+}
+
+// OrGroup returns a group where any of the conditions must hold.
+func OrGroup(conditions ...Condition) Where {
+	return group(OR, conditions)
+}
+
+func group(operator LogicalOperator, conditions []Condition) Where {
+	where := Where{Operator: operator}
+	for _, condition := range conditions {
+		condition.addTo(&where)
+	}
+	return where
+}
+
+func Eq(field string, value any) WhereCondition {
+	return WhereCondition{Field: field, Operator: Equal, Value: value}
+}
+
+func Ne(field string, value any) WhereCondition {
+	return WhereCondition{Field: field, Operator: NotEqual, Value: value}
+}
+
+func Lt(field string, value any) WhereCondition {
+	return WhereCondition{Field: field, Operator: LessThan, Value: value}
+}
+
+func Le(field string, value any) WhereCondition {
+	return WhereCondition{Field: field, Operator: LessThanEqual, Value: value}
+}
+
+func Gt(field string, value any) WhereCondition {
+	return WhereCondition{Field: field, Operator: GreaterThan, Value: value}
+}
+
+func Ge(field string, value any) WhereCondition {
+	return WhereCondition{Field: field, Operator: GreaterThanEqual, Value: value}
+}
+
+// Btw matches values from low to high, inclusive.
+func Btw(field string, low, high any) WhereCondition {
+	return WhereCondition{Field: field, Operator: Between, Value: low, Value2: high}
+}
+
+// OneOf matches any of the values, see In.
+func OneOf(field string, values ...any) WhereCondition {
+	return WhereCondition{Field: field, Operator: In, Values: values}
+}
+
+// Has matches strings containing the substring, or sets and lists containing
+// the value.
+func Has(field string, value any) WhereCondition {
+	return WhereCondition{Field: field, Operator: Contains, Value: value}
+}
+
+func Prefix(field, prefix string) WhereCondition {
+	return WhereCondition{Field: field, Operator: BeginsWith, Value: prefix}
+}
+
+// OfType matches values of the type, e.g., OfType("price", "N").
+func OfType(field, attributeType string) WhereCondition {
+	return WhereCondition{Field: field, Operator: AttributeOfType, Value: attributeType}
+}
+
+func Exists(field string) WhereCondition {
+	return WhereCondition{Field: field, Operator: AttributeExists}
+}
+
+func NotExists(field string) WhereCondition {
+	return WhereCondition{Field: field, Operator: AttributeNotExists}
+}
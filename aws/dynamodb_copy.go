@@ -0,0 +1,209 @@
+package aws
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type (
+	CopyTableOptions struct {
+		TotalSegments int32 // Segments of the source scanned in parallel, defaults to 4
+		Workers       int   // Batches written in parallel, defaults to 4
+		MaxRetries    int   // Retries for unprocessed items, defaults to 5
+		// Optional read capacity budget for the source table
+		ReadLimiter *CapacityLimiter
+		// Optional write capacity budget for the destination table
+		WriteLimiter *CapacityLimiter
+		// Called after each batch, from one worker at a time
+		OnProgress func(CopyTableProgress)
+		// Optional store of the progress of each source segment, so a copy
+		// restarted with the same Job and TotalSegments resumes where it
+		// stopped, see ParallelScanOptions. A page is checkpointed once all
+		// its items are written or reported as failed.
+		Checkpointer Checkpointer
+		Job          string // Names the checkpoints, required with Checkpointer
+	}
+
+	CopyTableProgress struct {
+		Read    int // Items scanned from the source so far
+		Written int
+		Failed  int // Items still unprocessed after all retries
+	}
+
+	// copyBatch is up to 25 items of a scanned page, and the page to mark
+	// done once they are written when checkpointing.
+	copyBatch struct {
+		items []Item
+		page  *sync.WaitGroup
+	}
+
+	CopyTableResult struct {
+		Read       int
+		Written    int
+		FailedPuts []Item // Items still unprocessed after all retries
+	}
+)
+
+// CopyTable copies every item of the source table into the destination
+// table, which must exist with the same key schema. The source is read with a
+// parallel scan while workers write the pages in batches of 25, so copies of
+// large tables should set limiters to leave capacity for other clients.
+// Items overwrite those with the same key in the destination, and the first
+// error stops the copy.
+func (d *dynamodbService) CopyTable(ctx context.Context, src, dst string, opts ...CopyTableOptions) (*CopyTableResult, error) {
+	if src == "" || dst == "" {
+		return nil, DynamoDBErrTableNotSet
+	}
+
+	var o CopyTableOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	workers := o.Workers
+	if workers <= 0 {
+		workers = defaultBulkWorkers
+	}
+
+	batchOpts := BatchWriteOptions{
+		Table:      dst,
+		MaxRetries: o.MaxRetries,
+		Limiter:    o.WriteLimiter,
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		result   = &CopyTableResult{}
+		firstErr error
+		batches  = make(chan copyBatch)
+	)
+
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for batch := range batches {
+				// Once failed, the batches are only drained so the pages
+				// waiting on them return
+				if ctx.Err() == nil {
+					written, failed, err := d.writeCopyBatch(ctx, dst, batchOpts, batch.items)
+					if err != nil {
+						fail(err)
+					}
+
+					mu.Lock()
+					result.Written += written
+					result.FailedPuts = append(result.FailedPuts, failed...)
+					if err == nil && o.OnProgress != nil {
+						o.OnProgress(CopyTableProgress{
+							Read:    result.Read,
+							Written: result.Written,
+							Failed:  len(result.FailedPuts),
+						})
+					}
+					mu.Unlock()
+				}
+
+				if batch.page != nil {
+					batch.page.Done()
+				}
+			}
+		}()
+	}
+
+	_, err := d.ParallelScan(ctx, ParallelScanOptions{
+		ScanOptions:   ScanOptions{Table: src, Limiter: o.ReadLimiter},
+		TotalSegments: o.TotalSegments,
+		Checkpointer:  o.Checkpointer,
+		Job:           o.Job,
+		// Segments wait for their own pages to be written
+		ConcurrentOnItems: true,
+		OnItems: func(_ int32, items []Item) error {
+			mu.Lock()
+			result.Read += len(items)
+			mu.Unlock()
+
+			// With checkpoints, the page must be written before the scan
+			// moves past it
+			var page *sync.WaitGroup
+			if o.Checkpointer != nil {
+				page = &sync.WaitGroup{}
+			}
+
+			for items := range slices.Chunk(items, batchWriteLimit) {
+				if page != nil {
+					page.Add(1)
+				}
+				select {
+				case batches <- copyBatch{items: items, page: page}:
+				case <-ctx.Done():
+					if page != nil {
+						page.Done()
+					}
+					return ctx.Err()
+				}
+			}
+
+			if page != nil {
+				page.Wait()
+				return ctx.Err()
+			}
+			return nil
+		},
+	})
+	close(batches)
+	wg.Wait()
+
+	if firstErr != nil {
+		return result, firstErr
+	}
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// writeCopyBatch writes up to 25 scanned items to the destination, and
+// returns how many were written and the items still unprocessed after all
+// retries.
+func (d *dynamodbService) writeCopyBatch(ctx context.Context, dst string, opts BatchWriteOptions, items []Item) (int, []Item, error) {
+	requests := make([]types.WriteRequest, len(items))
+	for i, item := range items {
+		// Items were decrypted and rehydrated by the scan
+		av, err := d.storeItem(ctx, dst, item)
+		if err != nil {
+			return 0, nil, err
+		}
+		requests[i] = types.WriteRequest{PutRequest: &types.PutRequest{Item: av}}
+	}
+
+	unprocessed, err := d.batchWrite(ctx, opts, requests, &BatchWriteResult{})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var failed []Item
+	for _, request := range unprocessed {
+		failed = append(failed, request.PutRequest.Item)
+	}
+
+	return len(items) - len(unprocessed), failed, nil
+}
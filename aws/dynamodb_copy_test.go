@@ -0,0 +1,77 @@
+package aws
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestCopyTable(t *testing.T) {
+	d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}, "copy": {"id"}})
+	putItems(t, d, "users", 60)
+
+	var (
+		mu       sync.Mutex
+		progress []CopyTableProgress
+	)
+	result, err := d.CopyTable(context.Background(), "users", "copy", CopyTableOptions{
+		TotalSegments: 3,
+		Workers:       2,
+		OnProgress: func(p CopyTableProgress) {
+			mu.Lock()
+			defer mu.Unlock()
+			progress = append(progress, p)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Read != 60 || result.Written != 60 || len(result.FailedPuts) != 0 {
+		t.Errorf("CopyTable() = %+v, want 60 items read and written", result)
+	}
+	copied, err := d.ParallelScan(context.Background(), ParallelScanOptions{ScanOptions: ScanOptions{Table: "copy"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := itemIDs(copied.Items); !slices.Equal(got, wantIDs(60)) {
+		t.Errorf("copied items = %v, want every item", got)
+	}
+	if n := fake.item(t, "copy", map[string]any{"id": map[string]any{"S": "42"}})["n"]; n == nil {
+		t.Error("copied item lost its attributes")
+	}
+
+	if len(progress) == 0 || progress[len(progress)-1].Written != 60 {
+		t.Fatalf("OnProgress() = %+v, want the last call to report 60 written", progress)
+	}
+	for i := 1; i < len(progress); i++ {
+		if progress[i].Written < progress[i-1].Written {
+			t.Errorf("OnProgress() went back from %+v to %+v", progress[i-1], progress[i])
+		}
+	}
+}
+
+func TestCopyTableFailedPuts(t *testing.T) {
+	d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}, "copy": {"id"}})
+	putItems(t, d, "users", 30)
+
+	fake.unprocessed = func(request map[string]any) bool {
+		return requestID(request) == "7"
+	}
+
+	result, err := d.CopyTable(context.Background(), "users", "copy", CopyTableOptions{MaxRetries: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Written != 29 || len(result.FailedPuts) != 1 {
+		t.Fatalf("CopyTable() wrote %d and failed %d, want 29 and 1", result.Written, len(result.FailedPuts))
+	}
+	if got := itemIDs(result.FailedPuts); got[0] != "7" {
+		t.Errorf("CopyTable() failed puts = %v, want 7", got)
+	}
+	if fake.count("copy") != 29 {
+		t.Errorf("copy holds %d items, want 29", fake.count("copy"))
+	}
+}
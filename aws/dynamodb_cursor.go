@@ -0,0 +1,168 @@
+package aws
+
+import (
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// cursorValue is the JSON form of a key attribute. Keys can only be strings,
+// numbers or binary.
+type cursorValue struct {
+	S *string `json:"S,omitempty"`
+	N *string `json:"N,omitempty"`
+	B []byte  `json:"B,omitempty"`
+}
+
+// CursorConfig protects the cursors handed to untrusted clients. With a key,
+// cursors are signed with HMAC-SHA256 so tampered cursors are rejected, and
+// Encrypt also hides the key values with AES-256-GCM. Changing the key
+// invalidates the cursors issued before.
+type CursorConfig struct {
+	Key     []byte // Secret of any length, at least 32 random bytes recommended
+	Encrypt bool
+}
+
+var DynamoDBErrInvalidCursor = errors.New("invalid cursor")
+
+// encodeCursor serializes a LastEvaluatedKey into an opaque base64 string.
+// An empty key means there are no more pages and yields an empty cursor.
+func encodeCursor(key Item, config *CursorConfig) (string, error) {
+	if len(key) == 0 {
+		return "", nil
+	}
+
+	values := make(map[string]cursorValue, len(key))
+	for name, av := range key {
+		switch v := av.(type) {
+		case *types.AttributeValueMemberS:
+			values[name] = cursorValue{S: &v.Value}
+		case *types.AttributeValueMemberN:
+			values[name] = cursorValue{N: &v.Value}
+		case *types.AttributeValueMemberB:
+			values[name] = cursorValue{B: v.Value}
+		default:
+			return "", fmt.Errorf("%w: unsupported key attribute %s", DynamoDBErrInvalidCursor, name)
+		}
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", DynamoDBErrInvalidCursor, err)
+	}
+
+	data, err = config.seal(data)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", DynamoDBErrInvalidCursor, err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor turns a cursor from encodeCursor back into an
+// ExclusiveStartKey. An empty cursor yields a nil key.
+func decodeCursor(cursor string, config *CursorConfig) (Item, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrInvalidCursor, err)
+	}
+
+	data, err = config.open(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrInvalidCursor, err)
+	}
+
+	var values map[string]cursorValue
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrInvalidCursor, err)
+	}
+
+	key := make(Item, len(values))
+	for name, v := range values {
+		switch {
+		case v.S != nil:
+			key[name] = &types.AttributeValueMemberS{Value: *v.S}
+		case v.N != nil:
+			key[name] = &types.AttributeValueMemberN{Value: *v.N}
+		case v.B != nil:
+			key[name] = &types.AttributeValueMemberB{Value: v.B}
+		default:
+			return nil, fmt.Errorf("%w: empty key attribute %s", DynamoDBErrInvalidCursor, name)
+		}
+	}
+
+	return key, nil
+}
+
+// seal signs or encrypts the cursor data, leaving it as is without a key.
+func (c *CursorConfig) seal(data []byte) ([]byte, error) {
+	if c == nil || len(c.Key) == 0 {
+		return data, nil
+	}
+
+	if c.Encrypt {
+		aead, err := c.aead()
+		if err != nil {
+			return nil, err
+		}
+
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, err
+		}
+		return aead.Seal(nonce, nonce, data, nil), nil
+	}
+
+	mac := hmac.New(sha256.New, c.Key)
+	mac.Write(data)
+	return mac.Sum(data), nil
+}
+
+// open verifies and decrypts cursor data from seal.
+func (c *CursorConfig) open(data []byte) ([]byte, error) {
+	if c == nil || len(c.Key) == 0 {
+		return data, nil
+	}
+
+	if c.Encrypt {
+		aead, err := c.aead()
+		if err != nil {
+			return nil, err
+		}
+		if len(data) < aead.NonceSize() {
+			return nil, errors.New("cursor too short")
+		}
+
+		nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+		return aead.Open(nil, nonce, ciphertext, nil)
+	}
+
+	if len(data) < sha256.Size {
+		return nil, errors.New("cursor too short")
+	}
+
+	payload, signature := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	mac := hmac.New(sha256.New, c.Key)
+	mac.Write(payload)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, errors.New("signature mismatch")
+	}
+	return payload, nil
+}
+
+// aead derives a 256-bit AES key from the configured key.
+func (c *CursorConfig) aead() (cipher.AEAD, error) {
+	key := sha256.Sum256(c.Key)
+	return newAEAD(key[:])
+}
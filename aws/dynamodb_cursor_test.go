@@ -0,0 +1,100 @@
+package aws
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestCursor(t *testing.T) {
+	key := Item{
+		"pk":   &types.AttributeValueMemberS{Value: "USER#1"},
+		"sk":   &types.AttributeValueMemberN{Value: "42"},
+		"hash": &types.AttributeValueMemberB{Value: []byte{0, 1, 2}},
+	}
+
+	configs := map[string]*CursorConfig{
+		"plain":   nil,
+		"signed":  {Key: []byte("0123456789abcdef0123456789abcdef")},
+		"encrypt": {Key: []byte("0123456789abcdef0123456789abcdef"), Encrypt: true},
+	}
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			cursor, err := encodeCursor(key, config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			decoded, err := decodeCursor(cursor, config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(decoded, key) {
+				t.Errorf("decoded %#v, want %#v", decoded, key)
+			}
+		})
+	}
+}
+
+func TestCursorEmpty(t *testing.T) {
+	cursor, err := encodeCursor(nil, nil)
+	if err != nil || cursor != "" {
+		t.Errorf("encodeCursor(nil) = %q, %v, want an empty cursor", cursor, err)
+	}
+
+	key, err := decodeCursor("", nil)
+	if err != nil || key != nil {
+		t.Errorf("decodeCursor(\"\") = %v, %v, want a nil key", key, err)
+	}
+}
+
+func TestCursorRejected(t *testing.T) {
+	key := Item{"pk": &types.AttributeValueMemberS{Value: "USER#1"}}
+	signed := &CursorConfig{Key: []byte("0123456789abcdef0123456789abcdef")}
+	encrypted := &CursorConfig{Key: signed.Key, Encrypt: true}
+
+	signedCursor, err := encodeCursor(key, signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptedCursor, err := encodeCursor(key, encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plainCursor, err := encodeCursor(key, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := []byte(signedCursor)
+	tampered[2] ^= 1
+
+	tests := []struct {
+		name   string
+		cursor string
+		config *CursorConfig
+	}{
+		{"tampered", string(tampered), signed},
+		{"unsigned", plainCursor, signed},
+		{"other key", signedCursor, &CursorConfig{Key: []byte("another key")}},
+		{"encrypted with other key", encryptedCursor, &CursorConfig{Key: []byte("another key"), Encrypt: true}},
+		{"not base64", "not a cursor!", nil},
+		{"truncated", encryptedCursor[:4], encrypted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(tt.cursor, tt.config); !errors.Is(err, DynamoDBErrInvalidCursor) {
+				t.Errorf("decodeCursor() error = %v, want DynamoDBErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func TestCursorUnsupportedKey(t *testing.T) {
+	key := Item{"pk": &types.AttributeValueMemberBOOL{Value: true}}
+	if _, err := encodeCursor(key, nil); !errors.Is(err, DynamoDBErrInvalidCursor) {
+		t.Errorf("encodeCursor() error = %v, want DynamoDBErrInvalidCursor", err)
+	}
+}
@@ -0,0 +1,248 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type (
+	DiffTablesOptions struct {
+		TotalSegments int32            // Segments of each table scanned in parallel, defaults to 4
+		Limiter       *CapacityLimiter // Optional read capacity budget shared by both scans
+		// Top-level attributes left out of the comparison, e.g., "updated_at"
+		IgnoreFields []string
+	}
+
+	TableDiff struct {
+		Missing   []Item     // Items of the first table not found in the second
+		Extra     []Item     // Items of the second table not found in the first
+		Different []ItemDiff // Items with the same key but different attributes
+		Same      int        // Number of identical items
+	}
+
+	ItemDiff struct {
+		Key    Item
+		A      Item     // Item in the first table
+		B      Item     // Item in the second table
+		Fields []string // Top-level attributes that differ, sorted
+	}
+)
+
+var DynamoDBErrDiffTables = errors.New("tables cannot be compared")
+
+// Equal reports whether the tables hold the same items.
+func (t *TableDiff) Equal() bool {
+	return len(t.Missing) == 0 && len(t.Extra) == 0 && len(t.Different) == 0
+}
+
+// DiffTables compares the items of two tables with the same key schema by
+// their primary key, e.g., to verify a migration or a copy. The first table
+// is loaded in memory while the second one is scanned. Sets are compared
+// ignoring order and numbers by value. Items in the result are sorted by key.
+func (d *dynamodbService) DiffTables(ctx context.Context, a, b string, opts ...DiffTablesOptions) (*TableDiff, error) {
+	if a == "" || b == "" {
+		return nil, DynamoDBErrTableNotSet
+	}
+
+	var o DiffTablesOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	keys, err := d.diffKeys(ctx, a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	scanOpts := ParallelScanOptions{
+		ScanOptions:   ScanOptions{Table: a, Limiter: o.Limiter},
+		TotalSegments: o.TotalSegments,
+	}
+	scanned, err := d.ParallelScan(ctx, scanOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make(map[string]Item, len(scanned.Items))
+	for _, item := range scanned.Items {
+		pending[itemID(item, keys)] = item
+	}
+
+	diff := &TableDiff{}
+	scanOpts.Table = b
+	scanOpts.OnItems = func(_ int32, items []Item) error {
+		for _, item := range items {
+			id := itemID(item, keys)
+			other, ok := pending[id]
+			if !ok {
+				diff.Extra = append(diff.Extra, item)
+				continue
+			}
+			delete(pending, id)
+
+			fields := diffFields(other, item, o.IgnoreFields)
+			if len(fields) == 0 {
+				diff.Same++
+				continue
+			}
+			diff.Different = append(diff.Different, ItemDiff{
+				Key:    itemKey(item, keys),
+				A:      other,
+				B:      item,
+				Fields: fields,
+			})
+		}
+		return nil
+	}
+	if _, err := d.ParallelScan(ctx, scanOpts); err != nil {
+		return nil, err
+	}
+
+	diff.Missing = slices.Collect(maps.Values(pending))
+
+	byID := func(x, y Item) int { return strings.Compare(itemID(x, keys), itemID(y, keys)) }
+	slices.SortFunc(diff.Missing, byID)
+	slices.SortFunc(diff.Extra, byID)
+	slices.SortFunc(diff.Different, func(x, y ItemDiff) int { return byID(x.Key, y.Key) })
+
+	return diff, nil
+}
+
+// diffKeys returns the key attribute names shared by both tables.
+func (d *dynamodbService) diffKeys(ctx context.Context, a, b string) ([]string, error) {
+	var keys [2][]string
+	for i, table := range []string{a, b} {
+		description, err := d.DescribeTable(ctx, table)
+		if err != nil {
+			return nil, err
+		}
+
+		keys[i] = []string{description.PartitionKey.Name}
+		if description.SortKey != nil {
+			keys[i] = append(keys[i], description.SortKey.Name)
+		}
+	}
+
+	if !slices.Equal(keys[0], keys[1]) {
+		return nil, fmt.Errorf("%w: keys %v and %v differ", DynamoDBErrDiffTables, keys[0], keys[1])
+	}
+
+	return keys[0], nil
+}
+
+// itemID identifies an item by its key attributes.
+func itemID(item Item, keys []string) string {
+	var id strings.Builder
+	for _, key := range keys {
+		switch v := item[key].(type) {
+		case *types.AttributeValueMemberS:
+			id.WriteString("S:" + v.Value)
+		case *types.AttributeValueMemberN:
+			id.WriteString("N:" + v.Value)
+		case *types.AttributeValueMemberB:
+			id.WriteString("B:" + base64.StdEncoding.EncodeToString(v.Value))
+		}
+		id.WriteByte(0)
+	}
+	return id.String()
+}
+
+func itemKey(item Item, keys []string) Item {
+	key := make(Item, len(keys))
+	for _, name := range keys {
+		key[name] = item[name]
+	}
+	return key
+}
+
+// diffFields returns the top-level attributes that differ between the items.
+func diffFields(a, b Item, ignore []string) []string {
+	var fields []string
+	for name := range a {
+		if _, ok := b[name]; !ok {
+			fields = append(fields, name)
+		}
+	}
+	for name, value := range b {
+		if other, ok := a[name]; !ok || !attributeEqual(other, value) {
+			fields = append(fields, name)
+		}
+	}
+
+	fields = slices.DeleteFunc(fields, func(field string) bool {
+		return slices.Contains(ignore, field)
+	})
+	slices.Sort(fields)
+	return fields
+}
+
+// attributeEqual compares attribute values by meaning: sets ignore order and
+// numbers compare by value, e.g., "1.50" equals "1.5".
+func attributeEqual(a, b types.AttributeValue) bool {
+	switch x := a.(type) {
+	case *types.AttributeValueMemberS:
+		y, ok := b.(*types.AttributeValueMemberS)
+		return ok && x.Value == y.Value
+	case *types.AttributeValueMemberN:
+		y, ok := b.(*types.AttributeValueMemberN)
+		return ok && numberEqual(x.Value, y.Value)
+	case *types.AttributeValueMemberB:
+		y, ok := b.(*types.AttributeValueMemberB)
+		return ok && bytes.Equal(x.Value, y.Value)
+	case *types.AttributeValueMemberBOOL:
+		y, ok := b.(*types.AttributeValueMemberBOOL)
+		return ok && x.Value == y.Value
+	case *types.AttributeValueMemberNULL:
+		_, ok := b.(*types.AttributeValueMemberNULL)
+		return ok
+	case *types.AttributeValueMemberSS:
+		y, ok := b.(*types.AttributeValueMemberSS)
+		return ok && setEqual(x.Value, y.Value, func(p, q string) bool { return p == q })
+	case *types.AttributeValueMemberNS:
+		y, ok := b.(*types.AttributeValueMemberNS)
+		return ok && setEqual(x.Value, y.Value, numberEqual)
+	case *types.AttributeValueMemberBS:
+		y, ok := b.(*types.AttributeValueMemberBS)
+		return ok && setEqual(x.Value, y.Value, bytes.Equal)
+	case *types.AttributeValueMemberL:
+		y, ok := b.(*types.AttributeValueMemberL)
+		return ok && slices.EqualFunc(x.Value, y.Value, attributeEqual)
+	case *types.AttributeValueMemberM:
+		y, ok := b.(*types.AttributeValueMemberM)
+		return ok && maps.EqualFunc(x.Value, y.Value, attributeEqual)
+	default:
+		return false
+	}
+}
+
+func numberEqual(a, b string) bool {
+	x, okX := new(big.Rat).SetString(a)
+	y, okY := new(big.Rat).SetString(b)
+	if !okX || !okY {
+		return a == b
+	}
+	return x.Cmp(y) == 0
+}
+
+// setEqual reports whether both sets hold the same elements. Sets have no
+// duplicates, so matching every element of a in b is enough.
+func setEqual[E any](a, b []E, equal func(E, E) bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, x := range a {
+		if !slices.ContainsFunc(b, func(y E) bool { return equal(x, y) }) {
+			return false
+		}
+	}
+	return true
+}
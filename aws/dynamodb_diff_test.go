@@ -0,0 +1,106 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestAttributeEqual(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b types.AttributeValue
+		want bool
+	}{
+		{"string", &types.AttributeValueMemberS{Value: "a"}, &types.AttributeValueMemberS{Value: "a"}, true},
+		{"other string", &types.AttributeValueMemberS{Value: "a"}, &types.AttributeValueMemberS{Value: "b"}, false},
+		{"number scale", &types.AttributeValueMemberN{Value: "1.50"}, &types.AttributeValueMemberN{Value: "1.5"}, true},
+		{"number exponent", &types.AttributeValueMemberN{Value: "1e3"}, &types.AttributeValueMemberN{Value: "1000"}, true},
+		{"other number", &types.AttributeValueMemberN{Value: "1"}, &types.AttributeValueMemberN{Value: "2"}, false},
+		{"string and number", &types.AttributeValueMemberS{Value: "1"}, &types.AttributeValueMemberN{Value: "1"}, false},
+		{"binary", &types.AttributeValueMemberB{Value: []byte{1}}, &types.AttributeValueMemberB{Value: []byte{1}}, true},
+		{"bool", &types.AttributeValueMemberBOOL{Value: true}, &types.AttributeValueMemberBOOL{Value: false}, false},
+		{"null", &types.AttributeValueMemberNULL{Value: true}, &types.AttributeValueMemberNULL{Value: true}, true},
+		{
+			"string set order",
+			&types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+			&types.AttributeValueMemberSS{Value: []string{"b", "a"}},
+			true,
+		},
+		{
+			"number set",
+			&types.AttributeValueMemberNS{Value: []string{"1.0", "2"}},
+			&types.AttributeValueMemberNS{Value: []string{"2", "1"}},
+			true,
+		},
+		{
+			"set size",
+			&types.AttributeValueMemberSS{Value: []string{"a"}},
+			&types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+			false,
+		},
+		{
+			"binary set",
+			&types.AttributeValueMemberBS{Value: [][]byte{{1}, {2}}},
+			&types.AttributeValueMemberBS{Value: [][]byte{{2}, {1}}},
+			true,
+		},
+		{
+			"list order",
+			&types.AttributeValueMemberL{Value: []types.AttributeValue{
+				&types.AttributeValueMemberS{Value: "a"}, &types.AttributeValueMemberS{Value: "b"},
+			}},
+			&types.AttributeValueMemberL{Value: []types.AttributeValue{
+				&types.AttributeValueMemberS{Value: "b"}, &types.AttributeValueMemberS{Value: "a"},
+			}},
+			false,
+		},
+		{
+			"nested map",
+			&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+				"price": &types.AttributeValueMemberN{Value: "9.90"},
+				"tags":  &types.AttributeValueMemberSS{Value: []string{"x", "y"}},
+			}},
+			&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+				"price": &types.AttributeValueMemberN{Value: "9.9"},
+				"tags":  &types.AttributeValueMemberSS{Value: []string{"y", "x"}},
+			}},
+			true,
+		},
+		{
+			"map keys",
+			&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{"a": &types.AttributeValueMemberS{Value: "1"}}},
+			&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{"b": &types.AttributeValueMemberS{Value: "1"}}},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := attributeEqual(tt.a, tt.b); got != tt.want {
+				t.Errorf("attributeEqual() = %v, want %v", got, tt.want)
+			}
+			if got := attributeEqual(tt.b, tt.a); got != tt.want {
+				t.Errorf("attributeEqual() reversed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNumberEqual(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"1.50", "1.5", true},
+		{"-0", "0", true},
+		{"100", "1E2", true},
+		{"0.1", "0.10000000000000000001", false},
+		{"abc", "abc", true},
+		{"abc", "1", false},
+	}
+	for _, tt := range tests {
+		if got := numberEqual(tt.a, tt.b); got != tt.want {
+			t.Errorf("numberEqual(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package aws
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// encryptedKey is the attribute holding the KMS-encrypted data key of an
+// item with encrypted fields.
+const encryptedKey = "__encrypted_key"
+
+// EncryptionConfig encrypts attributes on the client before they are written,
+// so they stay unreadable to anyone with access to the table but not to the
+// KMS key. Each item gets its own data key from KMS, stored encrypted in the
+// item, and every field is sealed with AES-GCM, bound to the field name and
+// the primary key of the item so values cannot be moved to other fields or
+// items. Reads decrypt the fields transparently, which needs the key
+// attributes in the items read. Key attributes cannot be encrypted, and
+// values written with Update are stored as is.
+//
+// Data keys are not cached: every item written costs a KMS GenerateDataKey
+// call, and every encrypted item read a KMS Decrypt call, e.g., N calls for a
+// Scan or BatchGet of N items. Mind the KMS request quotas and costs.
+type EncryptionConfig struct {
+	KeyID  string   // KMS key ID, ARN or alias, e.g., "alias/app-data"
+	Fields []string // Top-level attributes to encrypt, in every table
+	// Optional KMS encryption context, required again to decrypt
+	Context map[string]string
+}
+
+var (
+	DynamoDBErrDecrypt = errors.New("failed to decrypt item")
+	DynamoDBErrEncrypt = errors.New("failed to encrypt item")
+)
+
+// encrypt seals the configured fields of the item with a new data key. The
+// item is copied when anything is encrypted.
+func (d *dynamodbService) encrypt(ctx context.Context, table string, item Item) (Item, error) {
+	if d.encryption == nil {
+		return item, nil
+	}
+
+	fields := slices.DeleteFunc(slices.Clone(d.encryption.Fields), func(field string) bool {
+		_, ok := item[field]
+		return !ok
+	})
+	if len(fields) == 0 {
+		return item, nil
+	}
+
+	names, err := d.keyNames(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range fields {
+		if slices.Contains(names, field) {
+			return nil, fmt.Errorf("%w: %s is a key attribute", DynamoDBErrEncrypt, field)
+		}
+	}
+
+	dataKey, err := d.kms.GenerateDataKey(ctx, &kms.GenerateDataKeyInput{
+		KeyId:             aws.String(d.encryption.KeyID),
+		KeySpec:           kmstypes.DataKeySpecAes256,
+		EncryptionContext: d.encryption.Context,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrEncrypt, err)
+	}
+
+	aead, err := newAEAD(dataKey.Plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrEncrypt, err)
+	}
+
+	encrypted := maps.Clone(item)
+	for _, field := range fields {
+		plaintext, err := json.Marshal(encodeAttributeValue(item[field]))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", DynamoDBErrEncrypt, field, err)
+		}
+		data, err := additionalData(field, names, item)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", DynamoDBErrEncrypt, err)
+		}
+
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, fmt.Errorf("%w: %w", DynamoDBErrEncrypt, err)
+		}
+
+		sealed := aead.Seal(nonce, nonce, plaintext, data)
+		encrypted[field] = &types.AttributeValueMemberB{Value: sealed}
+	}
+	encrypted[encryptedKey] = &types.AttributeValueMemberB{Value: dataKey.CiphertextBlob}
+
+	return encrypted, nil
+}
+
+// decrypt opens the encrypted fields of the items of the table in place.
+func (d *dynamodbService) decrypt(ctx context.Context, table string, items ...Item) error {
+	if d.encryption == nil {
+		return nil
+	}
+
+	var names []string
+	for _, item := range items {
+		blob, ok := item[encryptedKey].(*types.AttributeValueMemberB)
+		if !ok {
+			continue
+		}
+
+		if names == nil {
+			var err error
+			if names, err = d.keyNames(ctx, table); err != nil {
+				return err
+			}
+		}
+
+		dataKey, err := d.kms.Decrypt(ctx, &kms.DecryptInput{
+			CiphertextBlob:    blob.Value,
+			KeyId:             aws.String(d.encryption.KeyID),
+			EncryptionContext: d.encryption.Context,
+		})
+		if err != nil {
+			return fmt.Errorf("%w: %w", DynamoDBErrDecrypt, err)
+		}
+
+		aead, err := newAEAD(dataKey.Plaintext)
+		if err != nil {
+			return fmt.Errorf("%w: %w", DynamoDBErrDecrypt, err)
+		}
+
+		for _, field := range d.encryption.Fields {
+			sealed, ok := item[field].(*types.AttributeValueMemberB)
+			if !ok {
+				continue
+			}
+			if len(sealed.Value) < aead.NonceSize() {
+				return fmt.Errorf("%w: %s: value too short", DynamoDBErrDecrypt, field)
+			}
+
+			data, err := additionalData(field, names, item)
+			if err != nil {
+				return fmt.Errorf("%w: %w", DynamoDBErrDecrypt, err)
+			}
+
+			nonce, ciphertext := sealed.Value[:aead.NonceSize()], sealed.Value[aead.NonceSize():]
+			plaintext, err := aead.Open(nil, nonce, ciphertext, data)
+			if err != nil {
+				return fmt.Errorf("%w: %s: %w", DynamoDBErrDecrypt, field, err)
+			}
+
+			var value any
+			if err := json.Unmarshal(plaintext, &value); err != nil {
+				return fmt.Errorf("%w: %s: %w", DynamoDBErrDecrypt, field, err)
+			}
+			av, err := decodeAttributeValue(value)
+			if err != nil {
+				return fmt.Errorf("%w: %s: %w", DynamoDBErrDecrypt, field, err)
+			}
+			item[field] = av
+		}
+		delete(item, encryptedKey)
+	}
+
+	return nil
+}
+
+// additionalData returns the data authenticated with the field: its name and
+// the primary key of the item, so a sealed value only opens in the same
+// field of the same item.
+func additionalData(field string, names []string, item Item) ([]byte, error) {
+	key := make(map[string]any, len(names))
+	for _, name := range names {
+		av, ok := item[name]
+		if !ok {
+			return nil, fmt.Errorf("item has no %s key", name)
+		}
+		// DynamoDB normalizes numbers, e.g., "1.50" is read back as "1.5"
+		if n, ok := av.(*types.AttributeValueMemberN); ok {
+			if r, ok := new(big.Rat).SetString(n.Value); ok {
+				av = &types.AttributeValueMemberN{Value: r.RatString()}
+			}
+		}
+		key[name] = encodeAttributeValue(av)
+	}
+
+	return json.Marshal([]any{field, key})
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,102 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func newEncryptedDynamoDB(t *testing.T) (*dynamodbService, *fakeDynamoDB) {
+	d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}})
+	d.encryption = &EncryptionConfig{KeyID: "alias/test", Fields: []string{"ssn", "address"}}
+	return d, fake
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	d, fake := newEncryptedDynamoDB(t)
+
+	user := map[string]any{
+		"id":      "1",
+		"name":    "Ada",
+		"ssn":     "123-45-6789",
+		"address": map[string]any{"city": "London", "zip": 12345},
+	}
+	if _, err := d.Put(ctx, "users", user); err != nil {
+		t.Fatal(err)
+	}
+
+	stored := fake.item(t, "users", map[string]any{"id": map[string]any{"S": "1"}})
+	for _, field := range []string{"ssn", "address", encryptedKey} {
+		if _, ok := stored[field].(*types.AttributeValueMemberB); !ok {
+			t.Errorf("stored %s = %#v, want ciphertext", field, stored[field])
+		}
+	}
+	if name, ok := stored["name"].(*types.AttributeValueMemberS); !ok || name.Value != "Ada" {
+		t.Errorf("stored name = %#v, want plaintext", stored["name"])
+	}
+
+	result, err := d.Get(ctx, GetOptions{Table: "users", Key: Key{"id": "1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := UnmarshalItem[map[string]any](result.Item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"id":      "1",
+		"name":    "Ada",
+		"ssn":     "123-45-6789",
+		"address": map[string]any{"city": "London", "zip": float64(12345)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Get() = %v, want %v", got, want)
+	}
+}
+
+func TestEncryptKeyAttribute(t *testing.T) {
+	d, fake := newEncryptedDynamoDB(t)
+	d.encryption.Fields = append(d.encryption.Fields, "id")
+
+	_, err := d.Put(context.Background(), "users", map[string]any{"id": "1", "ssn": "123-45-6789"})
+	if !errors.Is(err, DynamoDBErrEncrypt) {
+		t.Fatalf("Put() error = %v, want DynamoDBErrEncrypt", err)
+	}
+	if fake.count("users") != 0 {
+		t.Error("Put() wrote the item")
+	}
+}
+
+func TestEncryptBindsToItem(t *testing.T) {
+	ctx := context.Background()
+	d, fake := newEncryptedDynamoDB(t)
+
+	if _, err := d.Put(ctx, "users", map[string]any{"id": "1", "ssn": "123-45-6789"}); err != nil {
+		t.Fatal(err)
+	}
+	stored := fake.stored("users", map[string]any{"id": map[string]any{"S": "1"}})
+
+	// The ciphertext and data key copied to another item
+	moved := maps.Clone(stored)
+	moved["id"] = map[string]any{"S": "2"}
+	fake.store("users", moved)
+
+	if _, err := d.Get(ctx, GetOptions{Table: "users", Key: Key{"id": "2"}}); !errors.Is(err, DynamoDBErrDecrypt) {
+		t.Errorf("Get() of the moved item error = %v, want DynamoDBErrDecrypt", err)
+	}
+
+	// The ciphertext copied to another field of the same item
+	swapped := maps.Clone(stored)
+	swapped["address"] = stored["ssn"]
+	delete(swapped, "ssn")
+	fake.store("users", swapped)
+
+	if _, err := d.Get(ctx, GetOptions{Table: "users", Key: Key{"id": "1"}}); !errors.Is(err, DynamoDBErrDecrypt) {
+		t.Errorf("Get() of the swapped field error = %v, want DynamoDBErrDecrypt", err)
+	}
+}
@@ -0,0 +1,375 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type (
+	// SingleTableOptions names the generic attributes of a single table.
+	SingleTableOptions struct {
+		PartitionKey  string // Defaults to "PK"
+		SortKey       string // Defaults to "SK"
+		TypeAttribute string // Names the entity of each item, defaults to "type"
+		Marshal       MarshalOptions
+	}
+
+	// SingleTable is a table shared by several entity types, see NewEntity.
+	// Items hold generic key attributes built from key templates, and an
+	// attribute naming their entity.
+	SingleTable struct {
+		ddb      DynamoDB
+		table    string
+		opts     SingleTableOptions
+		mu       sync.RWMutex
+		entities map[string]func(Item) (any, error) // Unmarshalers by entity name
+	}
+
+	// Entity reads and writes the items of type T in a single table, e.g.,
+	//
+	//	type Order struct {
+	//		TenantID string `dynamodbav:"tenant_id"`
+	//		ID       string `dynamodbav:"id"`
+	//		Total    int    `dynamodbav:"total"`
+	//	}
+	//
+	//	table := NewSingleTable(ddb, "app")
+	//	orders, err := NewEntity[Order](table, "ORDER", "TENANT#{tenant_id}", "ORDER#{id}")
+	//	err = orders.Put(ctx, Order{TenantID: "1", ID: "7", Total: 42})
+	//	all, err := orders.Query(ctx, map[string]any{"tenant_id": "1"})
+	Entity[T any] struct {
+		table     *SingleTable
+		name      string
+		partition keyTemplate
+		sort      keyTemplate
+	}
+
+	// keyTemplate is a key with {attribute} placeholders, held as the literal
+	// text around each placeholder.
+	keyTemplate struct {
+		literals []string // One more than the fields
+		fields   []string
+	}
+)
+
+var DynamoDBErrKeyTemplate = errors.New("invalid key template")
+
+// NewSingleTable returns the single table.
+func NewSingleTable(ddb DynamoDB, table string, opts ...SingleTableOptions) *SingleTable {
+	var o SingleTableOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.PartitionKey == "" {
+		o.PartitionKey = "PK"
+	}
+	if o.SortKey == "" {
+		o.SortKey = "SK"
+	}
+	if o.TypeAttribute == "" {
+		o.TypeAttribute = "type"
+	}
+
+	return &SingleTable{
+		ddb:      ddb,
+		table:    table,
+		opts:     o,
+		entities: map[string]func(Item) (any, error){},
+	}
+}
+
+// NewEntity registers the entity type T under the name in the table. The key
+// templates build the partition and sort keys from the attributes of T, e.g.,
+// "TENANT#{tenant_id}", and the sort template may be empty for items with a
+// constant sort key of the entity name.
+func NewEntity[T any](table *SingleTable, name, partition, sort string) (*Entity[T], error) {
+	if name == "" {
+		return nil, fmt.Errorf("%w: entity name not set", DynamoDBErrKeyTemplate)
+	}
+	if sort == "" {
+		sort = name
+	}
+
+	p, err := parseKeyTemplate(partition)
+	if err != nil {
+		return nil, err
+	}
+	s, err := parseKeyTemplate(sort)
+	if err != nil {
+		return nil, err
+	}
+
+	table.mu.Lock()
+	defer table.mu.Unlock()
+	if _, ok := table.entities[name]; ok {
+		return nil, fmt.Errorf("%w: entity %q already registered", DynamoDBErrKeyTemplate, name)
+	}
+	table.entities[name] = func(item Item) (any, error) {
+		return UnmarshalItem[T](item, table.opts.Marshal)
+	}
+
+	return &Entity[T]{table: table, name: name, partition: p, sort: s}, nil
+}
+
+// QueryEntities reads every item of the partition, unmarshaled into the
+// entity type registered for its type attribute, e.g., an Order and its
+// OrderLine values. Items of unregistered entities are skipped.
+func (t *SingleTable) QueryEntities(ctx context.Context, partition string, opts ...QueryOptions) ([]any, error) {
+	var o QueryOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	o.Table = t.table
+	o.Index = ""
+	o.Partition = &QueryKeyValue{Key: t.opts.PartitionKey, Value: partition}
+
+	result, err := t.ddb.QueryAll(ctx, o, 0)
+	if result == nil {
+		return nil, err
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var out []any
+	for _, item := range result.Items {
+		name, ok := item[t.opts.TypeAttribute].(*types.AttributeValueMemberS)
+		if !ok {
+			continue
+		}
+		unmarshal, ok := t.entities[name.Value]
+		if !ok {
+			continue
+		}
+		entity, unmarshalErr := unmarshal(item)
+		if unmarshalErr != nil {
+			return nil, unmarshalErr
+		}
+		out = append(out, entity)
+	}
+
+	return out, err
+}
+
+// Name returns the value of the type attribute of the entity items.
+func (e *Entity[T]) Name() string {
+	return e.name
+}
+
+// Key returns the key of an item from the values of the template
+// attributes.
+func (e *Entity[T]) Key(values map[string]any) (Key, error) {
+	lookup := valueLookup(values)
+
+	partition, err := e.partition.build(lookup)
+	if err != nil {
+		return nil, err
+	}
+	sort, err := e.sort.build(lookup)
+	if err != nil {
+		return nil, err
+	}
+
+	return Key{e.table.opts.PartitionKey: partition, e.table.opts.SortKey: sort}, nil
+}
+
+// Put creates or replaces the item, with its keys and type attribute set.
+func (e *Entity[T]) Put(ctx context.Context, item T, opts ...PutOptions) error {
+	av, err := e.marshal(item)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.table.ddb.Put(ctx, e.table.table, av, opts...)
+	return err
+}
+
+// Get fetches an item by the values of the template attributes, returning
+// DynamoDBErrItemNotFound when it does not exist or belongs to another
+// entity.
+func (e *Entity[T]) Get(ctx context.Context, values map[string]any) (T, error) {
+	var out T
+
+	key, err := e.Key(values)
+	if err != nil {
+		return out, err
+	}
+
+	result, err := e.table.ddb.Get(ctx, GetOptions{Table: e.table.table, Key: key})
+	if err != nil {
+		return out, err
+	}
+	if name, ok := result.Item[e.table.opts.TypeAttribute].(*types.AttributeValueMemberS); !ok || name.Value != e.name {
+		return out, DynamoDBErrItemNotFound
+	}
+
+	return UnmarshalItem[T](result.Item, e.table.opts.Marshal)
+}
+
+// Delete removes an item by the values of the template attributes. Deleting a
+// missing item is not an error.
+func (e *Entity[T]) Delete(ctx context.Context, values map[string]any) error {
+	key, err := e.Key(values)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.table.ddb.Delete(ctx, DeleteOptions{Table: e.table.table, Key: key})
+	return err
+}
+
+// Query reads every item of the entity in the partition built from the
+// values. The sort key is matched up to its first attribute missing from the
+// values, e.g., {"tenant_id": "1"} reads every "ORDER#" of the tenant while
+// adding "id" reads a single order. Only items of the entity are returned.
+// The options may narrow the query with Where, Order and Fields.
+func (e *Entity[T]) Query(ctx context.Context, values map[string]any, opts ...QueryOptions) ([]T, error) {
+	lookup := valueLookup(values)
+
+	partition, err := e.partition.build(lookup)
+	if err != nil {
+		return nil, err
+	}
+
+	var o QueryOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	o.Table = e.table.table
+	o.Index = ""
+	o.Partition = &QueryKeyValue{Key: e.table.opts.PartitionKey, Value: partition}
+
+	o.Sort = nil
+	switch prefix, complete := e.sort.prefix(lookup); {
+	case complete:
+		o.Sort = &QueryKeyValue{Key: e.table.opts.SortKey, Value: prefix, Operator: Equal}
+	case prefix != "":
+		o.Sort = &QueryKeyValue{Key: e.table.opts.SortKey, Value: prefix, Operator: BeginsWith}
+	}
+
+	where := &Where{Conditions: []WhereCondition{Eq(e.table.opts.TypeAttribute, e.name)}}
+	if o.Where != nil {
+		where.Groups = []Where{*o.Where}
+	}
+	o.Where = where
+
+	result, err := e.table.ddb.QueryAll(ctx, o, 0)
+	if result == nil {
+		return nil, err
+	}
+
+	items, unmarshalErr := UnmarshalItems[T](result.Items, e.table.opts.Marshal)
+	if unmarshalErr != nil {
+		return nil, unmarshalErr
+	}
+	return items, err
+}
+
+// marshal converts the item and sets its keys and type attribute.
+func (e *Entity[T]) marshal(item T) (Item, error) {
+	av, err := MarshalItem(item, e.table.opts.Marshal)
+	if err != nil {
+		return nil, err
+	}
+
+	lookup := func(field string) (string, bool) {
+		switch value := av[field].(type) {
+		case *types.AttributeValueMemberS:
+			return value.Value, true
+		case *types.AttributeValueMemberN:
+			return value.Value, true
+		default:
+			return "", false
+		}
+	}
+
+	partition, err := e.partition.build(lookup)
+	if err != nil {
+		return nil, err
+	}
+	sort, err := e.sort.build(lookup)
+	if err != nil {
+		return nil, err
+	}
+
+	av[e.table.opts.PartitionKey] = &types.AttributeValueMemberS{Value: partition}
+	av[e.table.opts.SortKey] = &types.AttributeValueMemberS{Value: sort}
+	av[e.table.opts.TypeAttribute] = &types.AttributeValueMemberS{Value: e.name}
+
+	return av, nil
+}
+
+// valueLookup reads template values from a map, formatted like composite key
+// parts.
+func valueLookup(values map[string]any) func(field string) (string, bool) {
+	return func(field string) (string, bool) {
+		value, ok := values[field]
+		if !ok {
+			return "", false
+		}
+		return keyPart(value), true
+	}
+}
+
+func parseKeyTemplate(template string) (keyTemplate, error) {
+	if template == "" {
+		return keyTemplate{}, fmt.Errorf("%w: empty template", DynamoDBErrKeyTemplate)
+	}
+
+	var t keyTemplate
+	rest := template
+	for {
+		literal, after, found := strings.Cut(rest, "{")
+		if strings.Contains(literal, "}") {
+			return keyTemplate{}, fmt.Errorf("%w: unexpected } in %q", DynamoDBErrKeyTemplate, template)
+		}
+		t.literals = append(t.literals, literal)
+		if !found {
+			return t, nil
+		}
+
+		field, after, found := strings.Cut(after, "}")
+		if !found || field == "" || strings.Contains(field, "{") {
+			return keyTemplate{}, fmt.Errorf("%w: unterminated placeholder in %q", DynamoDBErrKeyTemplate, template)
+		}
+		t.fields = append(t.fields, field)
+		rest = after
+	}
+}
+
+// build fills every placeholder, failing when a value is missing.
+func (t keyTemplate) build(lookup func(field string) (string, bool)) (string, error) {
+	key, complete := t.prefix(lookup)
+	if !complete {
+		missing := t.fields[0]
+		for _, field := range t.fields {
+			if _, ok := lookup(field); !ok {
+				missing = field
+				break
+			}
+		}
+		return "", fmt.Errorf("%w: %q not set", DynamoDBErrKeyTemplate, missing)
+	}
+	return key, nil
+}
+
+// prefix fills the placeholders up to the first missing value, and reports
+// whether the key is complete.
+func (t keyTemplate) prefix(lookup func(field string) (string, bool)) (string, bool) {
+	var b strings.Builder
+	b.WriteString(t.literals[0])
+	for i, field := range t.fields {
+		value, ok := lookup(field)
+		if !ok {
+			return b.String(), false
+		}
+		b.WriteString(value)
+		b.WriteString(t.literals[i+1])
+	}
+	return b.String(), true
+}
@@ -0,0 +1,67 @@
+package aws
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseKeyTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		want     keyTemplate
+	}{
+		{"ORDER", keyTemplate{literals: []string{"ORDER"}}},
+		{"TENANT#{tenant_id}", keyTemplate{literals: []string{"TENANT#", ""}, fields: []string{"tenant_id"}}},
+		{
+			"TENANT#{tenant}#ORDER#{id}",
+			keyTemplate{literals: []string{"TENANT#", "#ORDER#", ""}, fields: []string{"tenant", "id"}},
+		},
+		{"{a}{b}", keyTemplate{literals: []string{"", "", ""}, fields: []string{"a", "b"}}},
+	}
+	for _, tt := range tests {
+		got, err := parseKeyTemplate(tt.template)
+		if err != nil {
+			t.Errorf("parseKeyTemplate(%q) error = %v", tt.template, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseKeyTemplate(%q) = %+v, want %+v", tt.template, got, tt.want)
+		}
+	}
+
+	for _, template := range []string{"", "A}", "A#{id", "A#{}", "A#{a{b}}"} {
+		if _, err := parseKeyTemplate(template); !errors.Is(err, DynamoDBErrKeyTemplate) {
+			t.Errorf("parseKeyTemplate(%q) error = %v, want DynamoDBErrKeyTemplate", template, err)
+		}
+	}
+}
+
+func TestKeyTemplatePrefix(t *testing.T) {
+	template, err := parseKeyTemplate("TENANT#{tenant}#ORDER#{id}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		values   map[string]any
+		want     string
+		complete bool
+	}{
+		{map[string]any{"tenant": "1", "id": 7}, "TENANT#1#ORDER#7", true},
+		{map[string]any{"tenant": "1"}, "TENANT#1#ORDER#", false},
+		// Values after a missing one are not used
+		{map[string]any{"id": 7}, "TENANT#", false},
+		{nil, "TENANT#", false},
+	}
+	for _, tt := range tests {
+		got, complete := template.prefix(valueLookup(tt.values))
+		if got != tt.want || complete != tt.complete {
+			t.Errorf("prefix(%v) = %q, %v, want %q, %v", tt.values, got, complete, tt.want, tt.complete)
+		}
+	}
+
+	if _, err := template.build(valueLookup(map[string]any{"tenant": "1"})); !errors.Is(err, DynamoDBErrKeyTemplate) {
+		t.Errorf("build() error = %v, want DynamoDBErrKeyTemplate", err)
+	}
+}
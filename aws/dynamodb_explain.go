@@ -0,0 +1,172 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// QueryPlan is the request a query would send, built without executing it.
+type QueryPlan struct {
+	Table        string
+	Index        string // Empty when the base table is queried
+	KeyCondition string
+	Filter       string // Empty without Where conditions
+	Projection   string // Empty when all attributes are returned
+	Names        map[string]string
+	Values       map[string]types.AttributeValue
+	Limit        int32
+	Descending   bool
+	// Hints on the key schema, e.g., a filter on a sort key that a key
+	// condition could use instead
+	Warnings []string
+}
+
+// Explain builds the query like Query would, and checks it against the key
+// schema of the table and its indexes, without reading any item.
+func (d *dynamodbService) Explain(ctx context.Context, opts QueryOptions) (*QueryPlan, error) {
+	input, err := d.buildQueryInput(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = int32(defaultLimit)
+	}
+
+	plan := &QueryPlan{
+		Table:        opts.Table,
+		Index:        opts.Index,
+		KeyCondition: aws.ToString(input.KeyConditionExpression),
+		Filter:       aws.ToString(input.FilterExpression),
+		Projection:   aws.ToString(input.ProjectionExpression),
+		Names:        input.ExpressionAttributeNames,
+		Values:       input.ExpressionAttributeValues,
+		Limit:        limit,
+		Descending:   opts.Order == Descending,
+	}
+
+	description, err := d.DescribeTable(ctx, opts.Table)
+	if err != nil {
+		return nil, err
+	}
+	plan.Warnings = explainKeys(description, opts)
+
+	return plan, nil
+}
+
+// String formats the plan for logs, with the values as DynamoDB JSON.
+func (p *QueryPlan) String() string {
+	var b strings.Builder
+
+	target := p.Table
+	if p.Index != "" {
+		target += " index " + p.Index
+	}
+	fmt.Fprintf(&b, "Query %s (limit %d", target, p.Limit)
+	if p.Descending {
+		b.WriteString(", descending")
+	}
+	b.WriteString(")\n")
+
+	fmt.Fprintf(&b, "  KeyCondition: %s\n", p.KeyCondition)
+	if p.Filter != "" {
+		fmt.Fprintf(&b, "  Filter: %s\n", p.Filter)
+	}
+	if p.Projection != "" {
+		fmt.Fprintf(&b, "  Projection: %s\n", p.Projection)
+	}
+	for _, name := range slices.Sorted(maps.Keys(p.Names)) {
+		fmt.Fprintf(&b, "  %s = %s\n", name, p.Names[name])
+	}
+	for _, name := range slices.Sorted(maps.Keys(p.Values)) {
+		value, _ := json.Marshal(encodeAttributeValue(p.Values[name]))
+		fmt.Fprintf(&b, "  %s = %s\n", name, value)
+	}
+	for _, warning := range p.Warnings {
+		fmt.Fprintf(&b, "  Warning: %s\n", warning)
+	}
+
+	return b.String()
+}
+
+// explainKeys compares the keys and filters of the query with the key schema.
+func explainKeys(description *TableDescription, opts QueryOptions) []string {
+	partition, sort := description.PartitionKey, description.SortKey
+	if opts.Index != "" {
+		i := slices.IndexFunc(description.Indexes, func(index IndexDescription) bool {
+			return index.Name == opts.Index
+		})
+		if i < 0 {
+			return []string{fmt.Sprintf("index %q does not exist on table %q", opts.Index, opts.Table)}
+		}
+		partition, sort = description.Indexes[i].PartitionKey, description.Indexes[i].SortKey
+	}
+
+	var warnings []string
+	if opts.Partition.Key != partition.Name {
+		warnings = append(warnings, fmt.Sprintf("%q is not the partition key, %q is", opts.Partition.Key, partition.Name))
+	}
+	if opts.Sort != nil && (sort == nil || opts.Sort.Key != sort.Name) {
+		warnings = append(warnings, fmt.Sprintf("%q is not the sort key", opts.Sort.Key))
+	}
+
+	if opts.Where == nil {
+		return warnings
+	}
+
+	for _, field := range filterFields(*opts.Where) {
+		switch {
+		case field == partition.Name:
+			warnings = append(warnings, fmt.Sprintf("filter on the partition key %q; it is already matched by the key condition", field))
+			continue
+		case sort != nil && field == sort.Name:
+			warnings = append(warnings, fmt.Sprintf("filter on the sort key %q reads the whole partition; use Sort for a key condition", field))
+			continue
+		}
+
+		// Another index keyed on the same partition could use a key condition
+		if description.SortKey != nil && opts.Index != "" &&
+			description.PartitionKey.Name == opts.Partition.Key && description.SortKey.Name == field {
+			warnings = append(warnings, fmt.Sprintf("filter on %q, the sort key of the base table; query the table with Sort instead", field))
+		}
+		for _, index := range description.Indexes {
+			if index.Name == opts.Index || index.SortKey == nil {
+				continue
+			}
+			if index.PartitionKey.Name == opts.Partition.Key && index.SortKey.Name == field {
+				warnings = append(warnings, fmt.Sprintf("filter on %q, the sort key of index %q; query the index with Sort instead", field, index.Name))
+			}
+		}
+	}
+
+	return warnings
+}
+
+// filterFields returns the top-level attributes the conditions filter on, in
+// order of appearance.
+func filterFields(where Where) []string {
+	var fields []string
+	for _, condition := range where.Conditions {
+		field, _, _ := strings.Cut(condition.Field, ".")
+		field, _, _ = strings.Cut(field, "[")
+		if !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	for _, group := range where.Groups {
+		for _, field := range filterFields(group) {
+			if !slices.Contains(fields, field) {
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields
+}
@@ -0,0 +1,211 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	ExportDynamoDBJSON ExportFormat = "DYNAMODB_JSON"
+	ExportIon          ExportFormat = "ION"
+)
+
+type (
+	ExportFormat string
+
+	ExportTableOptions struct {
+		Table       string // Table name or ARN, the table needs point in time recovery
+		Bucket      string
+		Prefix      string       // Optional key prefix of the exported files
+		BucketOwner string       // Optional account ID when the bucket belongs to another account
+		Format      ExportFormat // Defaults to ExportDynamoDBJSON
+		ExportTime  time.Time    // Optional point in time to export, defaults to now
+		Wait        bool         // Block until the export completes or fails
+		// Optional store of the export ARN, so a job restarted with the same
+		// Job waits on the export it started instead of starting another one.
+		// A failed export is started again.
+		Checkpointer Checkpointer
+		Job          string // Names the checkpoint, required with Checkpointer
+	}
+
+	Export struct {
+		ARN    string
+		Status string // "IN_PROGRESS", "COMPLETED" or "FAILED"
+		// S3 URL of the manifest summary, e.g.,
+		// s3://bucket/prefix/AWSDynamoDB/01234-abcd/manifest-summary.json
+		ManifestLocation string
+		ItemCount        int64
+		SizeBytes        int64
+		FailureMessage   string
+		StartedAt        time.Time
+		EndedAt          time.Time
+	}
+)
+
+var (
+	DynamoDBErrBucketNotSet   = errors.New("bucket not set")
+	DynamoDBErrDescribeExport = errors.New("failed to describe export")
+	DynamoDBErrExportFailed   = errors.New("export failed")
+	DynamoDBErrExportTable    = errors.New("failed to export table")
+)
+
+// ExportTable exports the table to S3 from its point in time recovery data,
+// without consuming read capacity.
+func (d *dynamodbService) ExportTable(ctx context.Context, opts ExportTableOptions) (*Export, error) {
+	if opts.Table == "" {
+		return nil, DynamoDBErrTableNotSet
+	}
+	if opts.Bucket == "" {
+		return nil, DynamoDBErrBucketNotSet
+	}
+	if opts.Checkpointer != nil && opts.Job == "" {
+		return nil, DynamoDBErrJobNotSet
+	}
+
+	if export, err := d.resumeExport(ctx, opts); export != nil || err != nil {
+		return export, err
+	}
+
+	// The export API only accepts ARNs
+	tableARN := opts.Table
+	if !strings.HasPrefix(tableARN, "arn:") {
+		description, err := d.DescribeTable(ctx, opts.Table)
+		if err != nil {
+			return nil, err
+		}
+		tableARN = description.ARN
+	}
+
+	format := opts.Format
+	if format == "" {
+		format = ExportDynamoDBJSON
+	}
+
+	input := &dynamodb.ExportTableToPointInTimeInput{
+		TableArn:     aws.String(tableARN),
+		S3Bucket:     aws.String(opts.Bucket),
+		ExportFormat: types.ExportFormat(format),
+	}
+	if opts.Prefix != "" {
+		input.S3Prefix = aws.String(opts.Prefix)
+	}
+	if opts.BucketOwner != "" {
+		input.S3BucketOwner = aws.String(opts.BucketOwner)
+	}
+	if !opts.ExportTime.IsZero() {
+		input.ExportTime = aws.Time(opts.ExportTime)
+	}
+
+	response, err := d.client.ExportTableToPointInTime(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrExportTable, err)
+	}
+
+	export := describeExport(response.ExportDescription)
+	if opts.Checkpointer != nil {
+		if err := opts.Checkpointer.Checkpoint(context.WithoutCancel(ctx), opts.Job, export.ARN); err != nil {
+			return export, fmt.Errorf("%w: %w", DynamoDBErrCheckpoint, err)
+		}
+	}
+	if !opts.Wait {
+		return export, nil
+	}
+
+	return d.WaitForExport(ctx, export.ARN)
+}
+
+// resumeExport returns the export checkpointed for the job, nil when there is
+// none or it failed.
+func (d *dynamodbService) resumeExport(ctx context.Context, opts ExportTableOptions) (*Export, error) {
+	if opts.Checkpointer == nil {
+		return nil, nil
+	}
+
+	exportARN, err := opts.Checkpointer.Load(ctx, opts.Job)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrCheckpoint, err)
+	}
+	if exportARN == "" {
+		return nil, nil
+	}
+
+	export, err := d.DescribeExport(ctx, exportARN)
+	if err != nil {
+		return nil, err
+	}
+	if types.ExportStatus(export.Status) == types.ExportStatusFailed {
+		return nil, nil
+	}
+	if !opts.Wait {
+		return export, nil
+	}
+
+	return d.WaitForExport(ctx, export.ARN)
+}
+
+// DescribeExport returns the progress of the export.
+func (d *dynamodbService) DescribeExport(ctx context.Context, exportARN string) (*Export, error) {
+	response, err := d.client.DescribeExport(ctx, &dynamodb.DescribeExportInput{
+		ExportArn: aws.String(exportARN),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrDescribeExport, err)
+	}
+
+	return describeExport(response.ExportDescription), nil
+}
+
+// WaitForExport polls the export until it completes. DynamoDBErrExportFailed
+// is returned along with the export when it fails.
+func (d *dynamodbService) WaitForExport(ctx context.Context, exportARN string) (*Export, error) {
+	ticker := time.NewTicker(tablePollInterval)
+	defer ticker.Stop()
+
+	for {
+		export, err := d.DescribeExport(ctx, exportARN)
+		if err != nil {
+			return nil, err
+		}
+
+		switch types.ExportStatus(export.Status) {
+		case types.ExportStatusCompleted:
+			return export, nil
+		case types.ExportStatusFailed:
+			return export, fmt.Errorf("%w: %s", DynamoDBErrExportFailed, export.FailureMessage)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func describeExport(description *types.ExportDescription) *Export {
+	if description == nil {
+		return &Export{}
+	}
+
+	export := &Export{
+		ARN:            aws.ToString(description.ExportArn),
+		Status:         string(description.ExportStatus),
+		ItemCount:      aws.ToInt64(description.ItemCount),
+		SizeBytes:      aws.ToInt64(description.BilledSizeBytes),
+		FailureMessage: aws.ToString(description.FailureMessage),
+		StartedAt:      aws.ToTime(description.StartTime),
+		EndedAt:        aws.ToTime(description.EndTime),
+	}
+	if manifest := aws.ToString(description.ExportManifest); manifest != "" {
+		export.ManifestLocation = fmt.Sprintf("s3://%s/%s", aws.ToString(description.S3Bucket), manifest)
+	}
+
+	return export
+}
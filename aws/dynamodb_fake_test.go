@@ -0,0 +1,612 @@
+package aws
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	fakeStreamARN = "arn:aws:dynamodb:us-east-1:123456789012:table/users/stream/2025-01-01T00:00:00.000"
+	fakeShardID   = "shard-0"
+	fakeKeyPrefix = "fake-kms:"
+)
+
+type (
+	// fakeDynamoDB is an in-memory DynamoDB serving the JSON protocol to the
+	// SDK clients, covering the operations the tests use, along with the KMS
+	// data keys of encrypted items, a stream of a single shard and the S3
+	// objects read by LoadFromS3 and offloaded attributes. Items are
+	// kept in their wire form, e.g., {"id": {"S": "1"}}. Expressions other
+	// than projections are ignored.
+	fakeDynamoDB struct {
+		mu      sync.Mutex
+		tables  map[string]*fakeTable
+		calls   map[string]int    // By operation, e.g., "PutItem"
+		records []map[string]any  // Stream records in wire form
+		closed  bool              // Whether the shard is closed after the records
+		tokens  map[string]string // Transactions by client request token
+		objects map[string][]byte // S3 objects by "<bucket>/<key>"
+
+		// Optional, runs before each operation. A non-nil response is
+		// returned instead of running the operation.
+		before func(operation string, input map[string]any) (any, error)
+		// Optional, leaves the batch write requests it matches unprocessed,
+		// e.g., {"PutRequest": {"Item": ...}}
+		unprocessed func(request map[string]any) bool
+	}
+
+	fakeTable struct {
+		keys  []string // Partition key, then the sort key if any
+		items []map[string]any
+	}
+
+	// fakeError is returned by before to fail an operation with the type of
+	// a DynamoDB error, e.g., "ConditionalCheckFailedException".
+	fakeError string
+
+	// fakeCanceled is returned by before to cancel a transaction with the
+	// reason codes of its items, e.g., "None" or "ConditionalCheckFailed".
+	fakeCanceled []string
+)
+
+// newFakeDynamoDB returns a service backed by a fake with the tables, given
+// as table names followed by their key names, e.g., {"users": {"id"}}.
+func newFakeDynamoDB(t *testing.T, tables map[string][]string) (*dynamodbService, *fakeDynamoDB) {
+	t.Helper()
+
+	fake := &fakeDynamoDB{tables: map[string]*fakeTable{}, calls: map[string]int{}, tokens: map[string]string{}, objects: map[string][]byte{}}
+	for name, keys := range tables {
+		fake.tables[name] = &fakeTable{keys: keys}
+	}
+
+	client := dynamodb.New(dynamodb.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String("http://dynamodb.test"),
+		Credentials:  aws.AnonymousCredentials{},
+		HTTPClient:   fake,
+		Retryer:      aws.NopRetryer{},
+	})
+
+	keys := kms.New(kms.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String("http://kms.test"),
+		Credentials:  aws.AnonymousCredentials{},
+		HTTPClient:   fake,
+		Retryer:      aws.NopRetryer{},
+	})
+
+	objects := s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String("http://s3.test"),
+		Credentials:  aws.AnonymousCredentials{},
+		HTTPClient:   fake,
+		Retryer:      aws.NopRetryer{},
+		UsePathStyle: true,
+	})
+
+	return &dynamodbService{client: client, kms: keys, s3: objects}, fake
+}
+
+// newFakeStreamConsumer returns a consumer of the stream of the fake, loading
+// the images with the service.
+func newFakeStreamConsumer(d *dynamodbService, fake *fakeDynamoDB, opts StreamConsumerOptions) *StreamConsumer {
+	client := dynamodbstreams.New(dynamodbstreams.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String("http://streams.test"),
+		Credentials:  aws.AnonymousCredentials{},
+		HTTPClient:   fake,
+		Retryer:      aws.NopRetryer{},
+	})
+
+	if opts.StreamARN == "" {
+		opts.StreamARN = fakeStreamARN
+	}
+	if opts.Checkpointer == nil {
+		opts.Checkpointer = NewMemoryCheckpointer()
+	}
+	opts.PollInterval = time.Millisecond
+	opts.RefreshInterval = time.Millisecond
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = streamBatchSize
+	}
+
+	return &StreamConsumer{client: client, ddb: d, opts: opts, shards: map[string]*shardState{}}
+}
+
+func (e fakeError) Error() string {
+	return string(e)
+}
+
+func (e fakeCanceled) Error() string {
+	return "transaction canceled: " + strings.Join(e, ", ")
+}
+
+// Do serves a request of the SDK client.
+func (f *fakeDynamoDB) Do(req *http.Request) (*http.Response, error) {
+	if req.URL.Host == "s3.test" {
+		return f.serveS3(req), nil
+	}
+
+	service, operation, _ := strings.Cut(req.Header.Get("X-Amz-Target"), ".")
+
+	var input map[string]any
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	f.calls[operation]++
+	before := f.before
+	f.mu.Unlock()
+
+	var (
+		output any
+		err    error
+	)
+	if before != nil {
+		output, err = before(operation, input)
+	}
+	if output == nil && err == nil {
+		f.mu.Lock()
+		switch service {
+		case "TrentService":
+			output, err = serveKMS(operation, input)
+		case "DynamoDBStreams_20120810":
+			output, err = f.serveStream(operation, input)
+		default:
+			output, err = f.serve(operation, input)
+		}
+		f.mu.Unlock()
+	}
+
+	status := http.StatusOK
+	if err != nil {
+		status = http.StatusBadRequest
+		errorType := "InternalServerError"
+		var reasons []any
+		switch e := err.(type) {
+		case fakeError:
+			errorType = string(e)
+		case fakeCanceled:
+			errorType = "TransactionCanceledException"
+			for _, code := range e {
+				reasons = append(reasons, map[string]any{"Code": code})
+			}
+		}
+		output = map[string]any{
+			"__type":              "com.amazonaws.dynamodb.v20120810#" + errorType,
+			"message":             err.Error(),
+			"CancellationReasons": reasons,
+		}
+	}
+
+	body, err := json.Marshal(output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {"application/x-amz-json-1.0"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func (f *fakeDynamoDB) serve(operation string, input map[string]any) (any, error) {
+	table, ok := f.tables[fmt.Sprint(input["TableName"])]
+	if !ok && input["TableName"] != nil {
+		return nil, fakeError("ResourceNotFoundException")
+	}
+
+	switch operation {
+	case "DescribeTable":
+		schema := []map[string]any{{"AttributeName": table.keys[0], "KeyType": "HASH"}}
+		if len(table.keys) > 1 {
+			schema = append(schema, map[string]any{"AttributeName": table.keys[1], "KeyType": "RANGE"})
+		}
+		return map[string]any{"Table": map[string]any{
+			"TableName":   input["TableName"],
+			"TableStatus": "ACTIVE",
+			"KeySchema":   schema,
+		}}, nil
+	case "PutItem":
+		table.put(input["Item"].(map[string]any))
+		return map[string]any{}, nil
+	case "GetItem":
+		if item := table.get(input["Key"].(map[string]any)); item != nil {
+			return map[string]any{"Item": item}, nil
+		}
+		return map[string]any{}, nil
+	case "DeleteItem":
+		table.delete(input["Key"].(map[string]any))
+		return map[string]any{}, nil
+	case "Scan":
+		return table.scan(input), nil
+	case "BatchWriteItem":
+		unprocessed := map[string][]any{}
+		for name, requests := range input["RequestItems"].(map[string]any) {
+			table, ok := f.tables[name]
+			if !ok {
+				return nil, fakeError("ResourceNotFoundException")
+			}
+			for _, request := range requests.([]any) {
+				request := request.(map[string]any)
+				if f.unprocessed != nil && f.unprocessed(request) {
+					unprocessed[name] = append(unprocessed[name], request)
+					continue
+				}
+				if put, ok := request["PutRequest"].(map[string]any); ok {
+					table.put(put["Item"].(map[string]any))
+				}
+				if del, ok := request["DeleteRequest"].(map[string]any); ok {
+					table.delete(del["Key"].(map[string]any))
+				}
+			}
+		}
+		return map[string]any{"UnprocessedItems": unprocessed}, nil
+	case "TransactGetItems":
+		var responses []any
+		for _, item := range input["TransactItems"].([]any) {
+			get := item.(map[string]any)["Get"].(map[string]any)
+			table, ok := f.tables[get["TableName"].(string)]
+			if !ok {
+				return nil, fakeError("ResourceNotFoundException")
+			}
+
+			response := map[string]any{}
+			if found := table.get(get["Key"].(map[string]any)); found != nil {
+				response["Item"] = project(found, get)
+			}
+			responses = append(responses, response)
+		}
+		return map[string]any{"Responses": responses}, nil
+	case "TransactWriteItems":
+		// A token seen before succeeds again without applying the writes,
+		// unless the items differ
+		if token, ok := input["ClientRequestToken"].(string); ok {
+			request, _ := json.Marshal(input["TransactItems"])
+			if previous, ok := f.tokens[token]; ok {
+				if previous != string(request) {
+					return nil, fakeError("IdempotentParameterMismatchException")
+				}
+				return map[string]any{}, nil
+			}
+			f.tokens[token] = string(request)
+		}
+
+		for _, item := range input["TransactItems"].([]any) {
+			for action, write := range item.(map[string]any) {
+				write := write.(map[string]any)
+				table, ok := f.tables[write["TableName"].(string)]
+				if !ok {
+					return nil, fakeError("ResourceNotFoundException")
+				}
+
+				switch action {
+				case "Put":
+					table.put(write["Item"].(map[string]any))
+				case "Delete":
+					table.delete(write["Key"].(map[string]any))
+				case "ConditionCheck":
+				default:
+					return nil, fmt.Errorf("transaction action %s not supported by the fake", action)
+				}
+			}
+		}
+		return map[string]any{}, nil
+	default:
+		return nil, fmt.Errorf("operation %s not supported by the fake", operation)
+	}
+}
+
+// serveS3 lists and reads the objects, e.g., GET /bucket?list-type=2 and
+// GET /bucket/key.
+func (f *fakeDynamoDB) serveS3(req *http.Request) *http.Response {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	respond := func(status int, body []byte) *http.Response {
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Content-Type": {"application/xml"}},
+			Body:       io.NopCloser(bytes.NewReader(body)),
+			Request:    req,
+		}
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, "/"), "/")
+	if key != "" {
+		object, ok := f.objects[bucket+"/"+key]
+		if !ok {
+			return respond(http.StatusNotFound, []byte("<Error><Code>NoSuchKey</Code></Error>"))
+		}
+		return respond(http.StatusOK, object)
+	}
+
+	prefix := bucket + "/" + req.URL.Query().Get("prefix")
+	var list bytes.Buffer
+	list.WriteString("<ListBucketResult><IsTruncated>false</IsTruncated>")
+	for _, name := range slices.Sorted(maps.Keys(f.objects)) {
+		if strings.HasPrefix(name, prefix) {
+			fmt.Fprintf(&list, "<Contents><Key>%s</Key><Size>%d</Size></Contents>",
+				strings.TrimPrefix(name, bucket+"/"), len(f.objects[name]))
+		}
+	}
+	list.WriteString("</ListBucketResult>")
+	return respond(http.StatusOK, list.Bytes())
+}
+
+// serveKMS generates data keys whose ciphertext is the key itself behind a
+// prefix.
+func serveKMS(operation string, input map[string]any) (any, error) {
+	switch operation {
+	case "GenerateDataKey":
+		key := make([]byte, 32)
+		rand.Read(key)
+		return map[string]any{
+			"KeyId":          input["KeyId"],
+			"Plaintext":      key,
+			"CiphertextBlob": append([]byte(fakeKeyPrefix), key...),
+		}, nil
+	case "Decrypt":
+		blob, err := base64.StdEncoding.DecodeString(input["CiphertextBlob"].(string))
+		if err != nil {
+			return nil, err
+		}
+		key, ok := bytes.CutPrefix(blob, []byte(fakeKeyPrefix))
+		if !ok {
+			return nil, fakeError("InvalidCiphertextException")
+		}
+		return map[string]any{"KeyId": input["KeyId"], "Plaintext": key}, nil
+	default:
+		return nil, fmt.Errorf("operation %s not supported by the fake", operation)
+	}
+}
+
+// serveStream reads the records of the single shard, with iterators holding
+// the position of the next record.
+func (f *fakeDynamoDB) serveStream(operation string, input map[string]any) (any, error) {
+	switch operation {
+	case "DescribeStream":
+		return map[string]any{"StreamDescription": map[string]any{
+			"StreamArn": input["StreamArn"],
+			"Shards":    []any{map[string]any{"ShardId": fakeShardID}},
+		}}, nil
+	case "GetShardIterator":
+		position := 0
+		switch input["ShardIteratorType"] {
+		case "LATEST":
+			position = len(f.records)
+		case "AFTER_SEQUENCE_NUMBER":
+			position = slices.IndexFunc(f.records, func(record map[string]any) bool {
+				return record["dynamodb"].(map[string]any)["SequenceNumber"] == input["SequenceNumber"]
+			}) + 1
+		}
+		return map[string]any{"ShardIterator": strconv.Itoa(position)}, nil
+	case "GetRecords":
+		position, err := strconv.Atoi(input["ShardIterator"].(string))
+		if err != nil {
+			return nil, err
+		}
+		end := min(position+int(input["Limit"].(float64)), len(f.records))
+
+		output := map[string]any{"Records": f.records[position:end]}
+		if !f.closed || end < len(f.records) {
+			output["NextShardIterator"] = strconv.Itoa(end)
+		}
+		return output, nil
+	default:
+		return nil, fmt.Errorf("operation %s not supported by the fake", operation)
+	}
+}
+
+// record adds a record of the item to the stream, with images in wire form
+// or nil.
+func (f *fakeDynamoDB) record(event StreamEventName, keys, newImage, oldImage map[string]any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	change := map[string]any{
+		"Keys":           keys,
+		"SequenceNumber": strconv.Itoa(len(f.records) + 1),
+	}
+	if newImage != nil {
+		change["NewImage"] = newImage
+	}
+	if oldImage != nil {
+		change["OldImage"] = oldImage
+	}
+	f.records = append(f.records, map[string]any{
+		"eventID":   fmt.Sprint("event-", len(f.records)+1),
+		"eventName": string(event),
+		"dynamodb":  change,
+	})
+}
+
+// stored returns the stored item with the key in wire form.
+func (f *fakeDynamoDB) stored(table string, key map[string]any) map[string]any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.tables[table].get(key)
+}
+
+// putObject stores the object under "<bucket>/<key>".
+func (f *fakeDynamoDB) putObject(name string, body []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[name] = body
+}
+
+// store writes the item in wire form to the table, bypassing the client.
+func (f *fakeDynamoDB) store(table string, item map[string]any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tables[table].put(item)
+}
+
+// item returns the stored item with the key, converted to attribute values.
+func (f *fakeDynamoDB) item(t *testing.T, table string, key map[string]any) Item {
+	t.Helper()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	wire := f.tables[table].get(key)
+	if wire == nil {
+		return nil
+	}
+	av, err := decodeAttributeValue(map[string]any{"M": wire})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return av.(*types.AttributeValueMemberM).Value
+}
+
+// count returns the number of items in the table.
+func (f *fakeDynamoDB) count(table string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.tables[table].items)
+}
+
+// key returns the identity of the item, its key attributes in wire form.
+func (t *fakeTable) key(item map[string]any) string {
+	parts := make([]any, len(t.keys))
+	for i, name := range t.keys {
+		parts[i] = item[name]
+	}
+	data, _ := json.Marshal(parts)
+	return string(data)
+}
+
+func (t *fakeTable) index(key map[string]any) int {
+	id := t.key(key)
+	return slices.IndexFunc(t.items, func(item map[string]any) bool {
+		return t.key(item) == id
+	})
+}
+
+func (t *fakeTable) get(key map[string]any) map[string]any {
+	if i := t.index(key); i >= 0 {
+		return t.items[i]
+	}
+	return nil
+}
+
+func (t *fakeTable) put(item map[string]any) {
+	if i := t.index(item); i >= 0 {
+		t.items[i] = item
+		return
+	}
+	t.items = append(t.items, item)
+}
+
+func (t *fakeTable) delete(key map[string]any) {
+	if i := t.index(key); i >= 0 {
+		t.items = slices.Delete(t.items, i, i+1)
+	}
+}
+
+// scan returns a page of the segment, in key order. Items are assigned to
+// segments by a hash of their key, so they stay in their segment while others
+// are written or deleted.
+func (t *fakeTable) scan(input map[string]any) map[string]any {
+	segment, total := uint32(0), uint32(1)
+	if v, ok := input["TotalSegments"].(float64); ok {
+		segment, total = uint32(input["Segment"].(float64)), uint32(v)
+	}
+
+	var start string
+	if key, ok := input["ExclusiveStartKey"].(map[string]any); ok {
+		start = t.key(key)
+	}
+
+	var items []map[string]any
+	for _, item := range t.items {
+		id := t.key(item)
+		hash := fnv.New32a()
+		hash.Write([]byte(id))
+		if hash.Sum32()%total == segment && id > start {
+			items = append(items, item)
+		}
+	}
+	slices.SortFunc(items, func(a, b map[string]any) int {
+		return strings.Compare(t.key(a), t.key(b))
+	})
+
+	output := map[string]any{}
+	if limit, ok := input["Limit"].(float64); ok && len(items) > int(limit) {
+		items = items[:int(limit)]
+		last := map[string]any{}
+		for _, name := range t.keys {
+			last[name] = items[len(items)-1][name]
+		}
+		output["LastEvaluatedKey"] = last
+	}
+
+	page := make([]map[string]any, len(items))
+	for i, item := range items {
+		page[i] = project(item, input)
+	}
+	output["Items"] = page
+	output["Count"] = len(page)
+	output["ScannedCount"] = len(page)
+	return output
+}
+
+// project returns the attributes of the item named by the projection of the
+// input, e.g., "#0, #1", or the whole item without a projection.
+func project(item map[string]any, input map[string]any) map[string]any {
+	projection, ok := input["ProjectionExpression"].(string)
+	if !ok {
+		return item
+	}
+
+	names, _ := input["ExpressionAttributeNames"].(map[string]any)
+	projected := map[string]any{}
+	for _, name := range strings.Split(projection, ",") {
+		name = strings.TrimSpace(name)
+		if alias, ok := names[name].(string); ok {
+			name = alias
+		}
+		if value, ok := item[name]; ok {
+			projected[name] = value
+		}
+	}
+	return projected
+}
+
+// requestID returns the id of the item put or deleted by a batch write
+// request in wire form.
+func requestID(request map[string]any) string {
+	var key map[string]any
+	if put, ok := request["PutRequest"].(map[string]any); ok {
+		key = put["Item"].(map[string]any)
+	}
+	if del, ok := request["DeleteRequest"].(map[string]any); ok {
+		key = del["Key"].(map[string]any)
+	}
+	id, _ := key["id"].(map[string]any)["S"].(string)
+	return id
+}
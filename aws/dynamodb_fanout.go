@@ -0,0 +1,118 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+var defaultQueryConcurrency = 8
+
+type QueryManyOptions struct {
+	Concurrency int // Partitions queried at once, defaults to 8
+	// Items read per partition before failing with DynamoDBErrMaxItems, see
+	// QueryAll
+	MaxItems int
+	// Optional callback receiving the items of each partition as soon as it
+	// is read instead of merging them into the result. Calls are serialized.
+	OnItems func(partition any, items []Item) error
+}
+
+// QueryMany runs the query once per partition value, reading every page of
+// each partition, with bounded concurrency. The partition value of
+// opts.Partition is replaced while its key is kept. The items are merged in
+// the order of the partition values, and the first error cancels the
+// remaining queries.
+func (d *dynamodbService) QueryMany(ctx context.Context, opts QueryOptions, partitionValues []any, many ...QueryManyOptions) (*QueryResult, error) {
+	if opts.Partition == nil || opts.Partition.Key == "" {
+		return nil, DynamoDBErrPartitionNotSet
+	}
+
+	var o QueryManyOptions
+	if len(many) > 0 {
+		o = many[0]
+	}
+
+	concurrency := o.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultQueryConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		results  = make([]*QueryResult, len(partitionValues))
+		firstErr error
+		sem      = make(chan struct{}, concurrency)
+	)
+
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	for i, value := range partitionValues {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			partitionOpts := opts
+			partitionOpts.Partition = &QueryKeyValue{Key: opts.Partition.Key, Value: value}
+			partitionOpts.Cursor = ""
+
+			result, err := d.QueryAll(ctx, partitionOpts, o.MaxItems)
+			if err != nil {
+				fail(fmt.Errorf("partition %v: %w", value, err))
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if o.OnItems != nil && len(result.Items) > 0 {
+				if err := o.OnItems(value, result.Items); err != nil {
+					if firstErr == nil {
+						firstErr = err
+						cancel()
+					}
+					return
+				}
+				result.Items = nil
+			}
+			results[i] = result
+		}()
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	merged := &QueryResult{}
+	for _, result := range results {
+		merged.Items = append(merged.Items, result.Items...)
+		merged.Count += result.Count
+		merged.ScannedCount += result.ScannedCount
+		merged.Pages = append(merged.Pages, result.Pages...)
+		merged.ConsumedCapacity = mergeCapacity(merged.ConsumedCapacity, result.ConsumedCapacity)
+	}
+
+	return merged, nil
+}
@@ -0,0 +1,76 @@
+package aws
+
+import (
+	"context"
+	"slices"
+)
+
+// hydrate replaces the items read from an index with the full items from the
+// base table when QueryOptions.Hydrate is set. Items deleted from the table
+// since the index was read are dropped, and the order is kept.
+func (d *dynamodbService) hydrate(ctx context.Context, opts QueryOptions, items []Item) ([]Item, *ConsumedCapacity, error) {
+	if !opts.Hydrate || opts.Index == "" || len(items) == 0 {
+		return items, nil, nil
+	}
+
+	names, err := d.keyNames(ctx, opts.Table)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		keys = make([]Item, 0, len(items))
+		seen = make(map[string]bool, len(items))
+	)
+	for _, item := range items {
+		id := itemID(item, names)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		keys = append(keys, itemKey(item, names))
+	}
+
+	batchOpts := BatchGetOptions{Table: opts.Table, ReturnConsumedCapacity: opts.ReturnConsumedCapacity}
+	result := &BatchGetResult{}
+	for batch := range slices.Chunk(keys, batchGetLimit) {
+		if err := d.batchGet(ctx, batchOpts, batch, result); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	full := make(map[string]Item, len(result.Items))
+	for _, item := range result.Items {
+		full[itemID(item, names)] = item
+	}
+
+	hydrated := make([]Item, 0, len(items))
+	for _, item := range items {
+		if item, ok := full[itemID(item, names)]; ok {
+			hydrated = append(hydrated, item)
+		}
+	}
+
+	return hydrated, result.ConsumedCapacity, nil
+}
+
+// keyNames returns the key attribute names of the table, described once and
+// then cached.
+func (d *dynamodbService) keyNames(ctx context.Context, table string) ([]string, error) {
+	if names, ok := d.keys.Load(table); ok {
+		return names.([]string), nil
+	}
+
+	description, err := d.DescribeTable(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{description.PartitionKey.Name}
+	if description.SortKey != nil {
+		names = append(names, description.SortKey.Name)
+	}
+	d.keys.Store(table, names)
+
+	return names, nil
+}
@@ -0,0 +1,593 @@
+package aws
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	ImportCSV          ImportFormat = "CSV"
+	ImportDynamoDBJSON ImportFormat = "DYNAMODB_JSON"
+	ImportIon          ImportFormat = "ION"
+)
+
+const (
+	CompressionNone ImportCompression = "NONE"
+	CompressionGzip ImportCompression = "GZIP"
+	CompressionZstd ImportCompression = "ZSTD"
+)
+
+const (
+	LoadJSON         LoadFormat = "JSON"          // One plain JSON object per line
+	LoadDynamoDBJSON LoadFormat = "DYNAMODB_JSON" // One {"Item": {...}} per line, as written by ExportTable
+	// Rows of string attributes named by the header row. Empty fields are
+	// left out of the item.
+	LoadCSV LoadFormat = "CSV"
+
+	// loadChunk is the most items read ahead of the writes of each object
+	loadChunk = 1000
+)
+
+type (
+	ImportFormat string
+
+	ImportCompression string
+
+	LoadFormat string
+
+	ImportTableOptions struct {
+		Schema      TableSchema // The table to create, it must not exist yet
+		Bucket      string
+		Prefix      string            // Key prefix of the files to import
+		BucketOwner string            // Optional account ID when the bucket belongs to another account
+		Format      ImportFormat      // Defaults to ImportDynamoDBJSON
+		Compression ImportCompression // Defaults to CompressionNone
+		Wait        bool              // Block until the import completes or fails
+	}
+
+	Import struct {
+		ARN            string
+		Status         string // "IN_PROGRESS", "COMPLETED", "FAILED", ...
+		TableARN       string
+		ImportedCount  int64
+		ErrorCount     int64 // Items that could not be imported
+		FailureMessage string
+		StartedAt      time.Time
+		EndedAt        time.Time
+	}
+
+	LoadOptions struct {
+		Table  string
+		Bucket string
+		// Every object under the prefix is loaded and ".gz" objects are
+		// decompressed. For exports, use the prefix of the data directory,
+		// e.g., "exports/AWSDynamoDB/01234-abcd/data/"
+		Prefix string
+		Format LoadFormat // Defaults to LoadJSON
+		// Optional hook changing each item before it is written, e.g., to add
+		// keys or turn CSV strings into numbers. Returning nil skips the item.
+		Transform func(item Item) (Item, error)
+		Workers   int              // Batches written in parallel, defaults to 4
+		Limiter   *CapacityLimiter // Optional write capacity budget
+		// Called after each chunk of items is written, from one goroutine at
+		// a time
+		OnProgress func(LoadProgress)
+	}
+
+	LoadResult struct {
+		Objects int // Objects read
+		Written int // Items written
+		Skipped int // Items dropped by Transform
+		// Items that were still unprocessed after the retries
+		Failed []Item
+	}
+
+	LoadProgress struct {
+		Object  string // Key of the object being read
+		Objects int    // Objects fully read
+		Read    int    // Items read
+		Written int
+		Skipped int
+		Failed  int
+	}
+
+	// loadLine is an item read from an object, with the line it starts on.
+	loadLine struct {
+		number int
+		item   Item
+	}
+)
+
+var (
+	DynamoDBErrDescribeImport = errors.New("failed to describe import")
+	DynamoDBErrImportFailed   = errors.New("import failed")
+	DynamoDBErrImportTable    = errors.New("failed to import table")
+	DynamoDBErrLoad           = errors.New("failed to load items")
+)
+
+// ImportTable creates a new table from data in S3 using the native import,
+// which does not consume write capacity. The schema TTL attribute is not
+// applied.
+func (d *dynamodbService) ImportTable(ctx context.Context, opts ImportTableOptions) (*Import, error) {
+	if err := opts.Schema.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Bucket == "" {
+		return nil, DynamoDBErrBucketNotSet
+	}
+
+	format := opts.Format
+	if format == "" {
+		format = ImportDynamoDBJSON
+	}
+	compression := opts.Compression
+	if compression == "" {
+		compression = CompressionNone
+	}
+
+	create := opts.Schema.CreateTableOptions()
+	schema, definitions := keySchema(create.PartitionKey, create.SortKey)
+
+	parameters := &types.TableCreationParameters{
+		TableName:            aws.String(create.Table),
+		KeySchema:            schema,
+		AttributeDefinitions: definitions,
+		BillingMode:          types.BillingModePayPerRequest,
+	}
+	if create.BillingMode == BillingProvisioned {
+		parameters.BillingMode = types.BillingModeProvisioned
+		parameters.ProvisionedThroughput = create.Throughput.provisioned()
+	}
+
+	for _, index := range create.GlobalIndexes {
+		if index.Throughput == nil && create.BillingMode == BillingProvisioned {
+			index.Throughput = create.Throughput
+		}
+
+		gsi, indexDefinitions := index.create()
+		parameters.GlobalSecondaryIndexes = append(parameters.GlobalSecondaryIndexes, types.GlobalSecondaryIndex{
+			IndexName:             gsi.IndexName,
+			KeySchema:             gsi.KeySchema,
+			Projection:            gsi.Projection,
+			ProvisionedThroughput: gsi.ProvisionedThroughput,
+		})
+		parameters.AttributeDefinitions = mergeDefinitions(parameters.AttributeDefinitions, indexDefinitions...)
+	}
+
+	source := &types.S3BucketSource{S3Bucket: aws.String(opts.Bucket)}
+	if opts.Prefix != "" {
+		source.S3KeyPrefix = aws.String(opts.Prefix)
+	}
+	if opts.BucketOwner != "" {
+		source.S3BucketOwner = aws.String(opts.BucketOwner)
+	}
+
+	response, err := d.client.ImportTable(ctx, &dynamodb.ImportTableInput{
+		InputFormat:             types.InputFormat(format),
+		InputCompressionType:    types.InputCompressionType(compression),
+		S3BucketSource:          source,
+		TableCreationParameters: parameters,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrImportTable, err)
+	}
+
+	imported := describeImport(response.ImportTableDescription)
+	if !opts.Wait {
+		return imported, nil
+	}
+
+	return d.WaitForImport(ctx, imported.ARN)
+}
+
+// DescribeImport returns the progress of the import.
+func (d *dynamodbService) DescribeImport(ctx context.Context, importARN string) (*Import, error) {
+	response, err := d.client.DescribeImport(ctx, &dynamodb.DescribeImportInput{
+		ImportArn: aws.String(importARN),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrDescribeImport, err)
+	}
+
+	return describeImport(response.ImportTableDescription), nil
+}
+
+// WaitForImport polls the import until it completes. DynamoDBErrImportFailed
+// is returned along with the import when it fails or is cancelled.
+func (d *dynamodbService) WaitForImport(ctx context.Context, importARN string) (*Import, error) {
+	ticker := time.NewTicker(tablePollInterval)
+	defer ticker.Stop()
+
+	for {
+		imported, err := d.DescribeImport(ctx, importARN)
+		if err != nil {
+			return nil, err
+		}
+
+		switch types.ImportStatus(imported.Status) {
+		case types.ImportStatusCompleted:
+			return imported, nil
+		case types.ImportStatusFailed, types.ImportStatusCancelled:
+			return imported, fmt.Errorf("%w: %s", DynamoDBErrImportFailed, imported.FailureMessage)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// LoadFromS3 writes JSON lines or CSV rows stored in S3 into an existing
+// table with BulkWrite. Objects are streamed, so only a chunk of items per
+// object is held in memory. Unlike ImportTable it works with any table,
+// including DynamoDB Local, at the cost of write capacity.
+func (d *dynamodbService) LoadFromS3(ctx context.Context, opts LoadOptions) (*LoadResult, error) {
+	if opts.Table == "" {
+		return nil, DynamoDBErrTableNotSet
+	}
+	if opts.Bucket == "" {
+		return nil, DynamoDBErrBucketNotSet
+	}
+
+	result := &LoadResult{}
+	progress := &LoadProgress{}
+	paginator := s3.NewListObjectsV2Paginator(d.s3, &s3.ListObjectsV2Input{
+		Bucket: aws.String(opts.Bucket),
+		Prefix: aws.String(opts.Prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return result, fmt.Errorf("%w: %w", DynamoDBErrLoad, err)
+		}
+
+		for _, object := range page.Contents {
+			key := aws.ToString(object.Key)
+			if strings.HasSuffix(key, "/") {
+				continue
+			}
+
+			progress.Object = key
+			if err := d.loadObject(ctx, opts, key, result, progress); err != nil {
+				return result, err
+			}
+			result.Objects++
+			progress.Objects = result.Objects
+		}
+	}
+
+	return result, nil
+}
+
+func (d *dynamodbService) loadObject(ctx context.Context, opts LoadOptions, key string, result *LoadResult, progress *LoadProgress) error {
+	object, err := d.s3.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(opts.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", DynamoDBErrLoad, key, err)
+	}
+	defer object.Body.Close()
+
+	var body io.Reader = object.Body
+	if strings.HasSuffix(key, ".gz") {
+		gz, err := gzip.NewReader(object.Body)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", DynamoDBErrLoad, key, err)
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	flush := func(items []any) error {
+		written, err := d.BulkWrite(ctx, BulkWriteOptions{
+			Table:   opts.Table,
+			Items:   items,
+			Workers: opts.Workers,
+			Limiter: opts.Limiter,
+		})
+		if written != nil {
+			result.Written += written.Written
+			result.Failed = append(result.Failed, written.FailedPuts...)
+		}
+		if err != nil {
+			return err
+		}
+
+		progress.Written = result.Written
+		progress.Failed = len(result.Failed)
+		if opts.OnProgress != nil {
+			opts.OnProgress(*progress)
+		}
+		return nil
+	}
+
+	var items []any
+	for line, err := range decodeObject(body, opts.Format) {
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", DynamoDBErrLoad, key, err)
+		}
+		progress.Read++
+
+		item := line.item
+		if opts.Transform != nil {
+			if item, err = opts.Transform(item); err != nil {
+				return fmt.Errorf("%w: %s: line %d: %w", DynamoDBErrLoad, key, line.number, err)
+			}
+			if item == nil {
+				result.Skipped++
+				progress.Skipped = result.Skipped
+				continue
+			}
+		}
+
+		items = append(items, item)
+		if len(items) == loadChunk {
+			if err := flush(items); err != nil {
+				return err
+			}
+			items = nil
+		}
+	}
+
+	if len(items) > 0 {
+		return flush(items)
+	}
+
+	return nil
+}
+
+// decodeObject yields the items of an object with their line numbers. Errors
+// name the line they were found on.
+func decodeObject(body io.Reader, format LoadFormat) iter.Seq2[loadLine, error] {
+	if format == LoadCSV {
+		return decodeCSV(body)
+	}
+
+	return func(yield func(loadLine, error) bool) {
+		scanner := bufio.NewScanner(body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024) // Items are at most 400KB
+		line := 1
+		for ; scanner.Scan(); line++ {
+			if len(strings.TrimSpace(scanner.Text())) == 0 {
+				continue
+			}
+
+			item, err := decodeLine(scanner.Bytes(), format)
+			if err != nil {
+				yield(loadLine{}, fmt.Errorf("line %d: %w", line, err))
+				return
+			}
+			if !yield(loadLine{number: line, item: item}, nil) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(loadLine{}, fmt.Errorf("line %d: %w", line, err))
+		}
+	}
+}
+
+// decodeCSV yields a string attribute per non-empty field of each row, named
+// by the header row.
+func decodeCSV(body io.Reader) iter.Seq2[loadLine, error] {
+	return func(yield func(loadLine, error) bool) {
+		reader := csv.NewReader(body)
+		reader.ReuseRecord = true
+
+		header, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		if err != nil {
+			yield(loadLine{}, err)
+			return
+		}
+		header = slices.Clone(header)
+
+		for {
+			record, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				yield(loadLine{}, err)
+				return
+			}
+
+			item := make(Item, len(record))
+			for i, value := range record {
+				if value != "" {
+					item[header[i]] = &types.AttributeValueMemberS{Value: value}
+				}
+			}
+			line, _ := reader.FieldPos(0)
+			if !yield(loadLine{number: line, item: item}, nil) {
+				return
+			}
+		}
+	}
+}
+
+// decodeLine parses a single JSON line. Plain JSON numbers are stored as
+// numbers without losing precision.
+func decodeLine(line []byte, format LoadFormat) (Item, error) {
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+
+	var value map[string]any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	if format == LoadDynamoDBJSON {
+		if wrapped, ok := value["Item"].(map[string]any); ok {
+			value = wrapped
+		}
+
+		item := make(Item, len(value))
+		for name, v := range value {
+			av, err := decodeAttributeValue(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			item[name] = av
+		}
+		return item, nil
+	}
+
+	return attributevalue.MarshalMap(jsonNumbers(value))
+}
+
+// jsonNumbers replaces json.Number values so they are marshaled as numbers
+// instead of strings.
+func jsonNumbers(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		return attributevalue.Number(v)
+	case map[string]any:
+		for name, nested := range v {
+			v[name] = jsonNumbers(nested)
+		}
+	case []any:
+		for i, nested := range v {
+			v[i] = jsonNumbers(nested)
+		}
+	}
+	return value
+}
+
+// decodeAttributeValue parses the DynamoDB JSON form of a value, e.g.,
+// {"S": "hello"} or {"N": "42"}.
+func decodeAttributeValue(value any) (types.AttributeValue, error) {
+	typed, ok := value.(map[string]any)
+	if !ok || len(typed) != 1 {
+		return nil, errors.New("expected a single typed attribute value")
+	}
+
+	for kind, v := range typed {
+		switch kind {
+		case "S", "N", "B":
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s value must be a string", kind)
+			}
+			switch kind {
+			case "S":
+				return &types.AttributeValueMemberS{Value: s}, nil
+			case "N":
+				return &types.AttributeValueMemberN{Value: s}, nil
+			}
+			b, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return nil, err
+			}
+			return &types.AttributeValueMemberB{Value: b}, nil
+		case "BOOL":
+			b, ok := v.(bool)
+			if !ok {
+				return nil, errors.New("BOOL value must be a boolean")
+			}
+			return &types.AttributeValueMemberBOOL{Value: b}, nil
+		case "NULL":
+			return &types.AttributeValueMemberNULL{Value: true}, nil
+		case "SS", "NS", "BS":
+			list, ok := v.([]any)
+			if !ok {
+				return nil, fmt.Errorf("%s value must be a list", kind)
+			}
+			values := make([]string, len(list))
+			for i, element := range list {
+				s, ok := element.(string)
+				if !ok {
+					return nil, fmt.Errorf("%s elements must be strings", kind)
+				}
+				values[i] = s
+			}
+			switch kind {
+			case "SS":
+				return &types.AttributeValueMemberSS{Value: values}, nil
+			case "NS":
+				return &types.AttributeValueMemberNS{Value: values}, nil
+			}
+			binaries := make([][]byte, len(values))
+			for i, s := range values {
+				b, err := base64.StdEncoding.DecodeString(s)
+				if err != nil {
+					return nil, err
+				}
+				binaries[i] = b
+			}
+			return &types.AttributeValueMemberBS{Value: binaries}, nil
+		case "L":
+			list, ok := v.([]any)
+			if !ok {
+				return nil, errors.New("L value must be a list")
+			}
+			values := make([]types.AttributeValue, len(list))
+			for i, element := range list {
+				av, err := decodeAttributeValue(element)
+				if err != nil {
+					return nil, err
+				}
+				values[i] = av
+			}
+			return &types.AttributeValueMemberL{Value: values}, nil
+		case "M":
+			m, ok := v.(map[string]any)
+			if !ok {
+				return nil, errors.New("M value must be an object")
+			}
+			values := make(map[string]types.AttributeValue, len(m))
+			for name, element := range m {
+				av, err := decodeAttributeValue(element)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", name, err)
+				}
+				values[name] = av
+			}
+			return &types.AttributeValueMemberM{Value: values}, nil
+		default:
+			return nil, fmt.Errorf("unsupported attribute type %s", kind)
+		}
+	}
+
+	return nil, nil
+}
+
+func describeImport(description *types.ImportTableDescription) *Import {
+	if description == nil {
+		return &Import{}
+	}
+
+	return &Import{
+		ARN:            aws.ToString(description.ImportArn),
+		Status:         string(description.ImportStatus),
+		TableARN:       aws.ToString(description.TableArn),
+		ImportedCount:  description.ImportedItemCount,
+		ErrorCount:     description.ErrorCount,
+		FailureMessage: aws.ToString(description.FailureMessage),
+		StartedAt:      aws.ToTime(description.StartTime),
+		EndedAt:        aws.ToTime(description.EndTime),
+	}
+}
@@ -0,0 +1,152 @@
+package aws
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func gzipped(t *testing.T, data string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestLoadFromS3(t *testing.T) {
+	d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}})
+	fake.putObject("bucket/data/", nil)
+	fake.putObject("bucket/data/1.json", []byte(`{"id": "1", "balance": 12345678901234567890}`+"\n\n"+`{"id": "skip"}`+"\n"))
+	fake.putObject("bucket/data/2.json.gz", gzipped(t, `{"id": "2", "tags": ["a", "b"]}`+"\n"))
+	fake.putObject("bucket/other/3.json", []byte(`{"id": "3"}`+"\n"))
+
+	var progress []LoadProgress
+	result, err := d.LoadFromS3(context.Background(), LoadOptions{
+		Table:  "users",
+		Bucket: "bucket",
+		Prefix: "data/",
+		Transform: func(item Item) (Item, error) {
+			if item["id"].(*types.AttributeValueMemberS).Value == "skip" {
+				return nil, nil
+			}
+			return item, nil
+		},
+		OnProgress: func(p LoadProgress) { progress = append(progress, p) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Objects != 2 || result.Written != 2 || result.Skipped != 1 || len(result.Failed) != 0 {
+		t.Errorf("LoadFromS3() = %+v, want 2 objects, 2 written and 1 skipped", result)
+	}
+	if fake.count("users") != 2 {
+		t.Fatalf("users holds %d items, want 2", fake.count("users"))
+	}
+
+	first := fake.item(t, "users", map[string]any{"id": map[string]any{"S": "1"}})
+	if balance, ok := first["balance"].(*types.AttributeValueMemberN); !ok || balance.Value != "12345678901234567890" {
+		t.Errorf("balance = %#v, want the exact number", first["balance"])
+	}
+	second := fake.item(t, "users", map[string]any{"id": map[string]any{"S": "2"}})
+	if _, ok := second["tags"].(*types.AttributeValueMemberL); !ok {
+		t.Errorf("tags = %#v, want the list of the gzipped object", second["tags"])
+	}
+
+	if len(progress) != 2 || progress[1].Read != 3 || progress[1].Written != 2 || progress[1].Object != "data/2.json.gz" {
+		t.Errorf("OnProgress() = %+v, want a call per object", progress)
+	}
+}
+
+func TestLoadFromS3Formats(t *testing.T) {
+	tests := []struct {
+		name   string
+		format LoadFormat
+		object string
+		want   Item
+	}{
+		{
+			name:   "csv",
+			format: LoadCSV,
+			object: "id,name,age\n1,Ada,36\n",
+			want: Item{
+				"id":   &types.AttributeValueMemberS{Value: "1"},
+				"name": &types.AttributeValueMemberS{Value: "Ada"},
+				"age":  &types.AttributeValueMemberS{Value: "36"},
+			},
+		},
+		{
+			name:   "dynamodb json",
+			format: LoadDynamoDBJSON,
+			object: `{"Item": {"id": {"S": "1"}, "age": {"N": "36"}}}` + "\n",
+			want: Item{
+				"id":  &types.AttributeValueMemberS{Value: "1"},
+				"age": &types.AttributeValueMemberN{Value: "36"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}})
+			fake.putObject("bucket/users", []byte(tt.object))
+
+			if _, err := d.LoadFromS3(context.Background(), LoadOptions{Table: "users", Bucket: "bucket", Format: tt.format}); err != nil {
+				t.Fatal(err)
+			}
+
+			got := fake.item(t, "users", map[string]any{"id": map[string]any{"S": "1"}})
+			if !attributeEqual(&types.AttributeValueMemberM{Value: got}, &types.AttributeValueMemberM{Value: tt.want}) {
+				t.Errorf("loaded item = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFromS3Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		object    string
+		transform func(Item) (Item, error)
+		want      string
+	}{
+		{
+			name:   "invalid line",
+			object: `{"id": "1"}` + "\n" + `{"id": ` + "\n",
+			want:   "users.json: line 2",
+		},
+		{
+			name:   "transform",
+			object: `{"id": "1"}` + "\n\n" + `{"id": "2"}` + "\n",
+			transform: func(item Item) (Item, error) {
+				if item["id"].(*types.AttributeValueMemberS).Value == "2" {
+					return nil, errors.New("invalid id")
+				}
+				return item, nil
+			},
+			want: "users.json: line 3: invalid id",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}})
+			fake.putObject("bucket/users.json", []byte(tt.object))
+
+			_, err := d.LoadFromS3(context.Background(), LoadOptions{Table: "users", Bucket: "bucket", Transform: tt.transform})
+			if !errors.Is(err, DynamoDBErrLoad) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadFromS3() error = %v, want DynamoDBErrLoad naming %q", err, tt.want)
+			}
+		})
+	}
+}
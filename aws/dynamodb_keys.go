@@ -0,0 +1,174 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const DefaultKeyDelimiter = "#"
+
+// KeyFormat builds and parses composite keys, whose parts are joined by a
+// delimiter, e.g., "TENANT#123#ORDER#456". Parts are usually name and value
+// pairs, from the widest to the narrowest, so a prefix of the key matches a
+// level of the hierarchy. A delimiter or backslash within a part is escaped
+// with a backslash, e.g., "a#b" is stored as "a\#b", and unescaped by Parse.
+type KeyFormat struct {
+	Delimiter string // Defaults to "#", must not contain a backslash
+	// Optional width integer parts are zero-padded to, so they sort in
+	// numeric order, e.g., 10 stores 9 as "0000000009" before 10 as
+	// "0000000010". Negative integers are not padded.
+	NumberWidth int
+}
+
+var DynamoDBErrCompositeKey = errors.New("invalid composite key")
+
+// CompositeKey joins the parts with the default delimiter, e.g.,
+// CompositeKey("TENANT", 123, "ORDER", 456) is "TENANT#123#ORDER#456".
+func CompositeKey(parts ...any) string {
+	return KeyFormat{}.Build(parts...)
+}
+
+// ParseCompositeKey splits a key joined with the default delimiter.
+func ParseCompositeKey(key string) []string {
+	return KeyFormat{}.Parse(key)
+}
+
+// CompositeKeyPairs reads a key joined with the default delimiter as name and
+// value pairs, e.g., "TENANT#123#ORDER#456" is {"TENANT": "123", "ORDER":
+// "456"}.
+func CompositeKeyPairs(key string) (map[string]string, error) {
+	return KeyFormat{}.Pairs(key)
+}
+
+// CompositeKeyPrefix joins the parts with the default delimiter, and ends
+// with it, so "TENANT#1#" does not match "TENANT#12#...".
+func CompositeKeyPrefix(parts ...any) string {
+	return KeyFormat{}.Prefix(parts...)
+}
+
+// KeyBeginsWith matches sort keys under the parts joined with the default
+// delimiter, e.g., QueryOptions{Sort: KeyBeginsWith("SK", "ORDER")} for
+// every order of the partition.
+func KeyBeginsWith(key string, parts ...any) *QueryKeyValue {
+	return KeyFormat{}.BeginsWith(key, parts...)
+}
+
+// Build joins the parts, escaped. Time values are formatted as with
+// EncodeTime and TimeRFC3339, integers are padded to NumberWidth, and any
+// other value is formatted as with fmt.Sprint.
+func (f KeyFormat) Build(parts ...any) string {
+	delimiter := f.delimiter()
+	escaper := strings.NewReplacer(`\`, `\\`, delimiter, `\`+delimiter)
+
+	values := make([]string, len(parts))
+	for i, part := range parts {
+		values[i] = escaper.Replace(f.part(part))
+	}
+	return strings.Join(values, delimiter)
+}
+
+// Parse splits the key into its parts, unescaped.
+func (f KeyFormat) Parse(key string) []string {
+	if key == "" {
+		return nil
+	}
+
+	delimiter := f.delimiter()
+	var (
+		parts []string
+		part  strings.Builder
+	)
+	for i := 0; i < len(key); {
+		switch {
+		case key[i] == '\\' && strings.HasPrefix(key[i+1:], delimiter):
+			part.WriteString(delimiter)
+			i += 1 + len(delimiter)
+		case key[i] == '\\' && i+1 < len(key):
+			part.WriteByte(key[i+1])
+			i += 2
+		case strings.HasPrefix(key[i:], delimiter):
+			parts = append(parts, part.String())
+			part.Reset()
+			i += len(delimiter)
+		default:
+			part.WriteByte(key[i])
+			i++
+		}
+	}
+
+	return append(parts, part.String())
+}
+
+// Pairs reads the key as name and value pairs. The key must have an even
+// number of parts and no name may be empty or repeated.
+func (f KeyFormat) Pairs(key string) (map[string]string, error) {
+	parts := f.Parse(key)
+	if len(parts)%2 != 0 {
+		return nil, fmt.Errorf("%w: %q has an odd number of parts", DynamoDBErrCompositeKey, key)
+	}
+
+	pairs := make(map[string]string, len(parts)/2)
+	for i := 0; i < len(parts); i += 2 {
+		name := parts[i]
+		if name == "" {
+			return nil, fmt.Errorf("%w: %q has an empty name", DynamoDBErrCompositeKey, key)
+		}
+		if _, ok := pairs[name]; ok {
+			return nil, fmt.Errorf("%w: %q repeats %q", DynamoDBErrCompositeKey, key, name)
+		}
+		pairs[name] = parts[i+1]
+	}
+
+	return pairs, nil
+}
+
+// Prefix joins the parts and ends with the delimiter, so the prefix only
+// matches whole parts.
+func (f KeyFormat) Prefix(parts ...any) string {
+	return f.Build(parts...) + f.delimiter()
+}
+
+// BeginsWith matches sort keys under the parts, see Prefix.
+func (f KeyFormat) BeginsWith(key string, parts ...any) *QueryKeyValue {
+	return &QueryKeyValue{Key: key, Value: f.Prefix(parts...), Operator: BeginsWith}
+}
+
+func (f KeyFormat) delimiter() string {
+	if f.Delimiter == "" {
+		return DefaultKeyDelimiter
+	}
+	return f.Delimiter
+}
+
+// part formats a part, padding integers to NumberWidth.
+func (f KeyFormat) part(part any) string {
+	if f.NumberWidth <= 0 {
+		return keyPart(part)
+	}
+
+	switch value := part.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		s := fmt.Sprint(value)
+		if pad := f.NumberWidth - len(s); pad > 0 && !strings.HasPrefix(s, "-") {
+			s = strings.Repeat("0", pad) + s
+		}
+		return s
+	}
+	return keyPart(part)
+}
+
+func keyPart(part any) string {
+	switch value := part.(type) {
+	case string:
+		return value
+	case time.Time:
+		return formatTime(value)
+	case *time.Time:
+		if value != nil {
+			return formatTime(*value)
+		}
+	}
+	return fmt.Sprint(part)
+}
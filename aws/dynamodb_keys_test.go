@@ -0,0 +1,111 @@
+package aws
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestKeyFormatBuild(t *testing.T) {
+	at := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		format KeyFormat
+		parts  []any
+		want   string
+	}{
+		{"default", KeyFormat{}, []any{"TENANT", 123, "ORDER", 456}, "TENANT#123#ORDER#456"},
+		{"delimiter", KeyFormat{Delimiter: "|"}, []any{"USER", "a#b"}, "USER|a#b"},
+		{"time", KeyFormat{}, []any{"AT", at}, "AT#2025-01-01T00:00:00.000000000Z"},
+		{"time pointer", KeyFormat{}, []any{"AT", &at}, "AT#2025-01-01T00:00:00.000000000Z"},
+		{"escaped delimiter", KeyFormat{}, []any{"USER", "a#b"}, `USER#a\#b`},
+		{"escaped backslash", KeyFormat{}, []any{"PATH", `c:\tmp`}, `PATH#c:\\tmp`},
+		{"padded", KeyFormat{NumberWidth: 5}, []any{"ORDER", 9, "LINE", uint8(12)}, "ORDER#00009#LINE#00012"},
+		{"padding too narrow", KeyFormat{NumberWidth: 2}, []any{"ORDER", 12345}, "ORDER#12345"},
+		{"negative not padded", KeyFormat{NumberWidth: 5}, []any{"DELTA", -3}, "DELTA#-3"},
+		{"strings not padded", KeyFormat{NumberWidth: 5}, []any{"ORDER", "9"}, "ORDER#9"},
+		{"empty", KeyFormat{}, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.format.Build(tt.parts...); got != tt.want {
+				t.Errorf("Build() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyFormatPaddedSortOrder(t *testing.T) {
+	format := KeyFormat{NumberWidth: 10}
+	keys := []string{format.Build("ORDER", 9), format.Build("ORDER", 10), format.Build("ORDER", 100)}
+	if !slices.IsSorted(keys) {
+		t.Errorf("padded keys are not in numeric order: %q", keys)
+	}
+}
+
+func TestKeyFormatParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		format KeyFormat
+		key    string
+		want   []string
+	}{
+		{"default", KeyFormat{}, "TENANT#123#ORDER#456", []string{"TENANT", "123", "ORDER", "456"}},
+		{"empty", KeyFormat{}, "", nil},
+		{"empty parts", KeyFormat{}, "A##B#", []string{"A", "", "B", ""}},
+		{"escaped", KeyFormat{}, `USER#a\#b#PATH#c:\\tmp`, []string{"USER", "a#b", "PATH", `c:\tmp`}},
+		{"multi-char delimiter", KeyFormat{Delimiter: "::"}, `A::b\::c::D`, []string{"A", "b::c", "D"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.format.Parse(tt.key); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyFormatRoundTrip(t *testing.T) {
+	parts := []string{"USER", `a#b\c`, "NOTE", `#\#`, "", "end\\"}
+	for _, format := range []KeyFormat{{}, {Delimiter: "|"}, {Delimiter: "::"}} {
+		values := make([]any, len(parts))
+		for i, part := range parts {
+			values[i] = part
+		}
+
+		key := format.Build(values...)
+		if got := format.Parse(key); !reflect.DeepEqual(got, parts) {
+			t.Errorf("delimiter %q: Parse(Build()) = %q, want %q", format.Delimiter, got, parts)
+		}
+	}
+}
+
+func TestKeyFormatPairs(t *testing.T) {
+	pairs, err := CompositeKeyPairs(CompositeKey("TENANT", "a#1", "ORDER", 456))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"TENANT": "a#1", "ORDER": "456"}; !reflect.DeepEqual(pairs, want) {
+		t.Errorf("Pairs() = %v, want %v", pairs, want)
+	}
+
+	for _, key := range []string{"TENANT#1#ORDER", "#1", "TENANT#1#TENANT#2"} {
+		if _, err := CompositeKeyPairs(key); !errors.Is(err, DynamoDBErrCompositeKey) {
+			t.Errorf("Pairs(%q) error = %v, want DynamoDBErrCompositeKey", key, err)
+		}
+	}
+}
+
+func TestKeyFormatPrefix(t *testing.T) {
+	if got := CompositeKeyPrefix("TENANT", 1); got != "TENANT#1#" {
+		t.Errorf("Prefix() = %q, want %q", got, "TENANT#1#")
+	}
+
+	condition := KeyBeginsWith("SK", "ORDER")
+	if condition.Key != "SK" || condition.Value != "ORDER#" || condition.Operator != BeginsWith {
+		t.Errorf("BeginsWith() = %+v", condition)
+	}
+}
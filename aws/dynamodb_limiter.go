@@ -0,0 +1,91 @@
+package aws
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// CapacityLimiter keeps requests under a budget of capacity units per second,
+// e.g., a share of the table WCU for a bulk load. Requests wait while the
+// budget is spent, and the capacity they report is taken from it afterwards,
+// so the rate adapts to the actual item sizes. Share one limiter between the
+// operations that should draw from the same budget. A nil limiter does not
+// limit.
+type CapacityLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // Units added per second
+	burst  float64 // Max units saved up while idle
+	tokens float64 // Negative while paying back capacity already used
+	last   time.Time
+}
+
+// NewCapacityLimiter returns a limiter allowing unitsPerSecond capacity units,
+// with a burst of one second worth of units.
+func NewCapacityLimiter(unitsPerSecond float64) *CapacityLimiter {
+	return &CapacityLimiter{
+		rate:   unitsPerSecond,
+		burst:  unitsPerSecond,
+		tokens: unitsPerSecond,
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until the budget has units left or the context is done.
+func (l *CapacityLimiter) Wait(ctx context.Context) error {
+	if l == nil || l.rate <= 0 {
+		return nil
+	}
+
+	for {
+		l.mu.Lock()
+		l.refill()
+		tokens := l.tokens
+		l.mu.Unlock()
+
+		if tokens > 0 {
+			return nil
+		}
+
+		// Sleep until the debt is paid back
+		delay := time.Duration((-tokens/l.rate)*float64(time.Second)) + time.Millisecond
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Consume takes the units from the budget.
+func (l *CapacityLimiter) Consume(units float64) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill()
+	l.tokens -= units
+}
+
+// consume takes the capacity reported by a response from the budget.
+func (l *CapacityLimiter) consume(responses ...types.ConsumedCapacity) {
+	var units float64
+	for _, c := range responses {
+		units += aws.ToFloat64(c.CapacityUnits)
+	}
+	l.Consume(units)
+}
+
+func (l *CapacityLimiter) refill() {
+	now := time.Now()
+	l.tokens = min(l.tokens+now.Sub(l.last).Seconds()*l.rate, l.burst)
+	l.last = now
+}
@@ -0,0 +1,242 @@
+package aws
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	// UTC string with fixed nanoseconds, the default, e.g.,
+	// "2025-01-01T10:30:00.500000000Z". Times stored by earlier versions used
+	// time.RFC3339Nano, which drops trailing zeros and keeps the offset, so
+	// they still decode but don't sort or compare with the new ones, and keys
+	// holding them no longer match. Rewrite them before relying on ranges or
+	// conditions over times, e.g., with a migrations.Backfill setting each
+	// time attribute again, and recreate items keyed by time.
+	TimeRFC3339   TimeFormat = "RFC3339"
+	TimeUnix      TimeFormat = "UNIX"       // Number of seconds since the epoch
+	TimeUnixMilli TimeFormat = "UNIX_MILLI" // Number of milliseconds since the epoch
+)
+
+const (
+	EmptyKeep EmptyMode = "KEEP" // Store empty strings as is, the default
+	EmptyNull EmptyMode = "NULL" // Store empty strings as NULL
+	// Drop attributes holding empty strings. List elements are kept, so the
+	// other elements keep their indexes.
+	EmptyOmit EmptyMode = "OMIT"
+	// Fail with DynamoDBErrEmptyValue instead of writing empty strings
+	EmptyReject EmptyMode = "REJECT"
+)
+
+type (
+	TimeFormat string
+
+	EmptyMode string
+
+	MarshalOptions struct {
+		TagKey       string     // Extra struct tag to read, e.g., "json"
+		TimeFormat   TimeFormat // How time.Time values are stored, defaults to TimeRFC3339
+		EmptyStrings EmptyMode  // How empty strings are stored, defaults to EmptyKeep
+		// How nil pointers, maps and slices are stored, defaults to EmptyKeep
+		// which stores them as NULL like EmptyNull
+		NilValues EmptyMode
+		// Use encoding.TextMarshaler and encoding.BinaryMarshaler (and their
+		// unmarshaler counterparts) so types can provide their own encoding
+		UseEncodingMarshalers bool
+	}
+)
+
+// MarshalItem converts a struct or map into a DynamoDB item. Values that are
+// already attribute value maps are returned as is.
+func MarshalItem(item any, opts ...MarshalOptions) (Item, error) {
+	if av, ok := item.(Item); ok {
+		return av, nil
+	}
+
+	var o MarshalOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+
+	av, err := attributevalue.MarshalMapWithOptions(item, o.encoderOptions)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrMarshal, err)
+	}
+
+	if o.replacesEmpty() {
+		m, err := o.replaceEmpty("", &types.AttributeValueMemberM{Value: av})
+		if err != nil {
+			return nil, err
+		}
+		av = m.(*types.AttributeValueMemberM).Value
+	}
+
+	return av, nil
+}
+
+// UnmarshalItem converts a DynamoDB item into T.
+func UnmarshalItem[T any](item Item, opts ...MarshalOptions) (T, error) {
+	var o MarshalOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	var out T
+	if err := attributevalue.UnmarshalMapWithOptions(item, &out, o.decoderOptions); err != nil {
+		return out, fmt.Errorf("%w: %w", DynamoDBErrUnmarshal, err)
+	}
+
+	return out, nil
+}
+
+// UnmarshalItems converts DynamoDB items into a slice of T.
+func UnmarshalItems[T any](items []Item, opts ...MarshalOptions) ([]T, error) {
+	var o MarshalOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	out := make([]T, 0, len(items))
+	if err := attributevalue.UnmarshalListOfMapsWithOptions(items, &out, o.decoderOptions); err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrUnmarshal, err)
+	}
+
+	return out, nil
+}
+
+func (o MarshalOptions) encoderOptions(eo *attributevalue.EncoderOptions) {
+	if o.TagKey != "" {
+		eo.TagKey = o.TagKey
+	}
+	eo.UseEncodingMarshalers = o.UseEncodingMarshalers
+
+	switch o.TimeFormat {
+	case TimeUnix:
+		eo.EncodeTime = func(t time.Time) (types.AttributeValue, error) {
+			return &types.AttributeValueMemberN{Value: strconv.FormatInt(t.Unix(), 10)}, nil
+		}
+	case TimeUnixMilli:
+		eo.EncodeTime = func(t time.Time) (types.AttributeValue, error) {
+			return &types.AttributeValueMemberN{Value: strconv.FormatInt(t.UnixMilli(), 10)}, nil
+		}
+	default:
+		eo.EncodeTime = func(t time.Time) (types.AttributeValue, error) {
+			return &types.AttributeValueMemberS{Value: formatTime(t)}, nil
+		}
+	}
+}
+
+func (o MarshalOptions) decoderOptions(do *attributevalue.DecoderOptions) {
+	if o.TagKey != "" {
+		do.TagKey = o.TagKey
+	}
+	do.UseEncodingUnmarshalers = o.UseEncodingMarshalers
+
+	if o.TimeFormat == TimeUnixMilli {
+		do.DecodeTime.N = func(n string) (time.Time, error) {
+			ms, err := strconv.ParseInt(n, 10, 64)
+			if err != nil {
+				return time.Time{}, err
+			}
+			return time.UnixMilli(ms), nil
+		}
+	}
+}
+
+func (o MarshalOptions) validate() error {
+	switch o.TimeFormat {
+	case "", TimeRFC3339, TimeUnix, TimeUnixMilli:
+	default:
+		return fmt.Errorf("%w: unknown time format %q", DynamoDBErrMarshal, o.TimeFormat)
+	}
+
+	for _, mode := range []EmptyMode{o.EmptyStrings, o.NilValues} {
+		switch mode {
+		case "", EmptyKeep, EmptyNull, EmptyOmit, EmptyReject:
+		default:
+			return fmt.Errorf("%w: unknown empty mode %q", DynamoDBErrMarshal, mode)
+		}
+	}
+
+	return nil
+}
+
+func (o MarshalOptions) replacesEmpty() bool {
+	return (o.EmptyStrings != "" && o.EmptyStrings != EmptyKeep) ||
+		o.NilValues == EmptyOmit || o.NilValues == EmptyReject
+}
+
+// replaceEmpty walks maps and lists, applying the empty string and nil modes
+// to the value at path. A nil return means the value is omitted.
+func (o MarshalOptions) replaceEmpty(path string, av types.AttributeValue) (types.AttributeValue, error) {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		if v.Value != "" {
+			return v, nil
+		}
+
+		switch o.EmptyStrings {
+		case "", EmptyKeep:
+			return v, nil
+		case EmptyOmit:
+			return nil, nil
+		case EmptyReject:
+			return nil, fmt.Errorf("%w: %s is an empty string", DynamoDBErrEmptyValue, path)
+		case EmptyNull:
+			return &types.AttributeValueMemberNULL{Value: true}, nil
+		default:
+			return nil, fmt.Errorf("%w: unknown empty mode %q", DynamoDBErrMarshal, o.EmptyStrings)
+		}
+	case *types.AttributeValueMemberNULL:
+		switch o.NilValues {
+		case EmptyOmit:
+			return nil, nil
+		case EmptyReject:
+			return nil, fmt.Errorf("%w: %s is nil", DynamoDBErrEmptyValue, path)
+		default:
+			return v, nil
+		}
+	case *types.AttributeValueMemberM:
+		m := make(map[string]types.AttributeValue, len(v.Value))
+		for name, value := range v.Value {
+			replaced, err := o.replaceEmpty(joinPath(path, name), value)
+			if err != nil {
+				return nil, err
+			}
+			if replaced != nil {
+				m[name] = replaced
+			}
+		}
+		return &types.AttributeValueMemberM{Value: m}, nil
+	case *types.AttributeValueMemberL:
+		l := make([]types.AttributeValue, 0, len(v.Value))
+		for i, value := range v.Value {
+			replaced, err := o.replaceEmpty(fmt.Sprintf("%s[%d]", path, i), value)
+			if err != nil {
+				return nil, err
+			}
+			// Omitted elements are kept, dropping them would shift the others
+			if replaced == nil {
+				replaced = value
+			}
+			l = append(l, replaced)
+		}
+		return &types.AttributeValueMemberL{Value: l}, nil
+	default:
+		return av, nil
+	}
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
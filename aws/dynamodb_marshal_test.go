@@ -0,0 +1,93 @@
+package aws
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestReplaceEmpty(t *testing.T) {
+	s := func(value string) types.AttributeValue { return &types.AttributeValueMemberS{Value: value} }
+	null := &types.AttributeValueMemberNULL{Value: true}
+
+	item := &types.AttributeValueMemberM{Value: Item{
+		"name":  s("Ada"),
+		"empty": s(""),
+		"nil":   null,
+		"tags":  &types.AttributeValueMemberL{Value: []types.AttributeValue{s("a"), s(""), s("c")}},
+		"meta":  &types.AttributeValueMemberM{Value: Item{"note": s(""), "nil": null}},
+	}}
+
+	tests := []struct {
+		name    string
+		opts    MarshalOptions
+		want    Item
+		wantErr error
+	}{
+		{
+			name: "keep",
+			opts: MarshalOptions{EmptyStrings: EmptyKeep},
+			want: item.Value,
+		},
+		{
+			name: "null strings",
+			opts: MarshalOptions{EmptyStrings: EmptyNull},
+			want: Item{
+				"name":  s("Ada"),
+				"empty": null,
+				"nil":   null,
+				"tags":  &types.AttributeValueMemberL{Value: []types.AttributeValue{s("a"), null, s("c")}},
+				"meta":  &types.AttributeValueMemberM{Value: Item{"note": null, "nil": null}},
+			},
+		},
+		{
+			name: "omit strings and nils",
+			opts: MarshalOptions{EmptyStrings: EmptyOmit, NilValues: EmptyOmit},
+			want: Item{
+				"name": s("Ada"),
+				// List elements are kept so indexes don't shift
+				"tags": &types.AttributeValueMemberL{Value: []types.AttributeValue{s("a"), s(""), s("c")}},
+				"meta": &types.AttributeValueMemberM{Value: Item{}},
+			},
+		},
+		{
+			name:    "reject strings",
+			opts:    MarshalOptions{EmptyStrings: EmptyReject},
+			wantErr: DynamoDBErrEmptyValue,
+		},
+		{
+			name:    "reject nils",
+			opts:    MarshalOptions{NilValues: EmptyReject},
+			wantErr: DynamoDBErrEmptyValue,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.opts.replaceEmpty("", item)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("replaceEmpty() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if m := got.(*types.AttributeValueMemberM).Value; !reflect.DeepEqual(m, tt.want) {
+				t.Errorf("replaceEmpty() = %#v, want %#v", m, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshalItemUnknownMode(t *testing.T) {
+	tests := []MarshalOptions{
+		{EmptyStrings: "omit"},
+		{NilValues: "DROP"},
+		{TimeFormat: "unix"},
+	}
+	for _, opts := range tests {
+		if _, err := MarshalItem(map[string]any{"name": ""}, opts); !errors.Is(err, DynamoDBErrMarshal) {
+			t.Errorf("MarshalItem(%+v) error = %v, want DynamoDBErrMarshal", opts, err)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// offloadPointer is the single attribute of the map that replaces an
+// offloaded value, holding its "s3://bucket/key" location.
+const offloadPointer = "__s3_offload"
+
+var defaultOffloadThreshold = 64 * 1024
+
+// OffloadConfig stores large attribute values in S3 so items stay under the
+// 400KB limit. Values above the threshold are written to the bucket as
+// DynamoDB JSON and replaced by a pointer, and reads replace the pointers
+// with the stored values. Objects are named after their content and are not
+// deleted with the items, so expire them with a lifecycle rule if needed.
+type OffloadConfig struct {
+	Bucket string
+	Prefix string // Optional key prefix, e.g., "offload/"
+	// Values larger than this many bytes are offloaded, defaults to 64KB. Keep
+	// it above 2KB so key attributes are never offloaded.
+	Threshold int
+}
+
+var (
+	DynamoDBErrOffload   = errors.New("failed to offload attribute")
+	DynamoDBErrRehydrate = errors.New("failed to read offloaded attribute")
+)
+
+// offload replaces the large values of the item with pointers to their copies
+// in S3. The item is copied when anything is replaced.
+func (d *dynamodbService) offload(ctx context.Context, table string, item Item) (Item, error) {
+	if d.offloadConfig == nil {
+		return item, nil
+	}
+
+	threshold := d.offloadConfig.Threshold
+	if threshold <= 0 {
+		threshold = defaultOffloadThreshold
+	}
+
+	var offloaded Item
+	for name, value := range item {
+		if attributeSize(value) <= threshold {
+			continue
+		}
+
+		body, err := json.Marshal(encodeAttributeValue(value))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", DynamoDBErrOffload, name, err)
+		}
+
+		sum := sha256.Sum256(body)
+		key := path.Join(d.offloadConfig.Prefix, table, hex.EncodeToString(sum[:]))
+		_, err = d.s3.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:      aws.String(d.offloadConfig.Bucket),
+			Key:         aws.String(key),
+			Body:        bytes.NewReader(body),
+			ContentType: aws.String("application/json"),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", DynamoDBErrOffload, name, err)
+		}
+
+		if offloaded == nil {
+			offloaded = maps.Clone(item)
+		}
+		offloaded[name] = &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			offloadPointer: &types.AttributeValueMemberS{Value: "s3://" + d.offloadConfig.Bucket + "/" + key},
+		}}
+	}
+
+	if offloaded == nil {
+		return item, nil
+	}
+	return offloaded, nil
+}
+
+// rehydrate replaces the offload pointers of the items with the values stored
+// in S3.
+func (d *dynamodbService) rehydrate(ctx context.Context, items ...Item) error {
+	if d.offloadConfig == nil {
+		return nil
+	}
+
+	for _, item := range items {
+		for name, value := range item {
+			location, ok := offloadLocation(value)
+			if !ok {
+				continue
+			}
+
+			av, err := d.readOffloaded(ctx, location)
+			if err != nil {
+				return fmt.Errorf("%w: %s: %w", DynamoDBErrRehydrate, name, err)
+			}
+			item[name] = av
+		}
+	}
+
+	return nil
+}
+
+func (d *dynamodbService) readOffloaded(ctx context.Context, location string) (types.AttributeValue, error) {
+	bucket, key, ok := strings.Cut(strings.TrimPrefix(location, "s3://"), "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid location %q", location)
+	}
+
+	object, err := d.s3.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer object.Body.Close()
+
+	body, err := io.ReadAll(object.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil, err
+	}
+	return decodeAttributeValue(value)
+}
+
+// offloadLocation returns the S3 location when the value is an offload
+// pointer.
+func offloadLocation(value types.AttributeValue) (string, bool) {
+	m, ok := value.(*types.AttributeValueMemberM)
+	if !ok || len(m.Value) != 1 {
+		return "", false
+	}
+
+	pointer, ok := m.Value[offloadPointer].(*types.AttributeValueMemberS)
+	if !ok {
+		return "", false
+	}
+	return pointer.Value, true
+}
+
+// encodeAttributeValue returns the DynamoDB JSON form of a value, the inverse
+// of decodeAttributeValue.
+func encodeAttributeValue(value types.AttributeValue) map[string]any {
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		return map[string]any{"S": v.Value}
+	case *types.AttributeValueMemberN:
+		return map[string]any{"N": v.Value}
+	case *types.AttributeValueMemberB:
+		return map[string]any{"B": base64.StdEncoding.EncodeToString(v.Value)}
+	case *types.AttributeValueMemberBOOL:
+		return map[string]any{"BOOL": v.Value}
+	case *types.AttributeValueMemberNULL:
+		return map[string]any{"NULL": true}
+	case *types.AttributeValueMemberSS:
+		return map[string]any{"SS": v.Value}
+	case *types.AttributeValueMemberNS:
+		return map[string]any{"NS": v.Value}
+	case *types.AttributeValueMemberBS:
+		values := make([]string, len(v.Value))
+		for i, b := range v.Value {
+			values[i] = base64.StdEncoding.EncodeToString(b)
+		}
+		return map[string]any{"BS": values}
+	case *types.AttributeValueMemberL:
+		values := make([]any, len(v.Value))
+		for i, element := range v.Value {
+			values[i] = encodeAttributeValue(element)
+		}
+		return map[string]any{"L": values}
+	case *types.AttributeValueMemberM:
+		values := make(map[string]any, len(v.Value))
+		for name, element := range v.Value {
+			values[name] = encodeAttributeValue(element)
+		}
+		return map[string]any{"M": values}
+	default:
+		return map[string]any{"NULL": true}
+	}
+}
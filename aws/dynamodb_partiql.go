@@ -0,0 +1,285 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var batchStatementLimit = 25 // Max statements per BatchExecuteStatement request
+
+type (
+	Statement struct {
+		Statement      string // e.g., `SELECT * FROM "movies" WHERE year = ?`
+		Parameters     []any  // Values for the ? placeholders
+		ConsistentRead bool
+	}
+
+	ExecuteStatementOptions struct {
+		Statement
+		Limit     int32
+		NextToken string // Token from a previous result to read the next page
+	}
+
+	StatementResult struct {
+		Items     []Item
+		NextToken string // Empty when there are no more pages
+	}
+
+	BatchStatementResult struct {
+		Item Item  // Set for successful SELECT statements
+		Err  error // *StatementError when the statement failed
+	}
+
+	// StatementError is the failure of a single statement in a batch or
+	// transaction.
+	StatementError struct {
+		Index   int // Position of the statement in the request
+		Code    string
+		Message string
+	}
+
+	// TransactionCanceledError reports why a transaction was canceled. Reasons
+	// has an entry per statement or item, nil for those that did not fail.
+	TransactionCanceledError struct {
+		Reasons []*StatementError
+		Err     error
+
+		sentinel error // Failed operation, e.g., DynamoDBErrTransactWrite
+	}
+)
+
+var (
+	DynamoDBErrBatchExecuteStatement = errors.New("failed to batch execute statements")
+	DynamoDBErrExecuteStatement      = errors.New("failed to execute statement")
+	DynamoDBErrExecuteTransaction    = errors.New("failed to execute transaction")
+	DynamoDBErrStatementNotSet       = errors.New("statement not set")
+)
+
+func (e *StatementError) Error() string {
+	return fmt.Sprintf("statement %d: %s: %s", e.Index, e.Code, e.Message)
+}
+
+func (e *TransactionCanceledError) Error() string {
+	var reasons []string
+	for _, reason := range e.Reasons {
+		if reason != nil {
+			reasons = append(reasons, reason.Error())
+		}
+	}
+
+	return fmt.Sprintf("%s: transaction canceled: %s", e.operation(), strings.Join(reasons, "; "))
+}
+
+func (e *TransactionCanceledError) Unwrap() []error {
+	return []error{e.operation(), e.Err}
+}
+
+func (e *TransactionCanceledError) operation() error {
+	if e.sentinel == nil {
+		return DynamoDBErrExecuteTransaction
+	}
+	return e.sentinel
+}
+
+// ExecuteStatement runs a single PartiQL statement and returns one page of
+// results.
+func (d *dynamodbService) ExecuteStatement(ctx context.Context, opts ExecuteStatementOptions) (*StatementResult, error) {
+	if opts.Statement.Statement == "" {
+		return nil, DynamoDBErrStatementNotSet
+	}
+	if !readOnly(opts.Statement) {
+		defer d.invalidate()
+	}
+
+	params, err := statementParameters(opts.Parameters, d.marshal)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &dynamodb.ExecuteStatementInput{
+		Statement:      aws.String(opts.Statement.Statement),
+		Parameters:     params,
+		ConsistentRead: aws.Bool(opts.ConsistentRead),
+	}
+
+	if opts.Limit > 0 {
+		input.Limit = aws.Int32(opts.Limit)
+	}
+	if opts.NextToken != "" {
+		input.NextToken = aws.String(opts.NextToken)
+	}
+
+	response, err := d.client.ExecuteStatement(ctx, input)
+	if err != nil {
+		return nil, writeError(DynamoDBErrExecuteStatement, err)
+	}
+
+	return &StatementResult{
+		Items:     response.Items,
+		NextToken: aws.ToString(response.NextToken),
+	}, nil
+}
+
+// BatchExecuteStatement runs the statements in batches of 25. Statements fail
+// independently, so the error of each one is reported in its result, in the
+// same order as the statements.
+func (d *dynamodbService) BatchExecuteStatement(ctx context.Context, statements []Statement) ([]BatchStatementResult, error) {
+	if !readOnly(statements...) {
+		defer d.invalidate()
+	}
+
+	requests := make([]types.BatchStatementRequest, len(statements))
+	for i, statement := range statements {
+		if statement.Statement == "" {
+			return nil, DynamoDBErrStatementNotSet
+		}
+
+		params, err := statementParameters(statement.Parameters, d.marshal)
+		if err != nil {
+			return nil, err
+		}
+
+		requests[i] = types.BatchStatementRequest{
+			Statement:      aws.String(statement.Statement),
+			Parameters:     params,
+			ConsistentRead: aws.Bool(statement.ConsistentRead),
+		}
+	}
+
+	results := make([]BatchStatementResult, 0, len(statements))
+	for start := 0; start < len(requests); start += batchStatementLimit {
+		end := min(start+batchStatementLimit, len(requests))
+
+		response, err := d.client.BatchExecuteStatement(ctx, &dynamodb.BatchExecuteStatementInput{
+			Statements: requests[start:end],
+		})
+		if err != nil {
+			return results, fmt.Errorf("%w: %w", DynamoDBErrBatchExecuteStatement, err)
+		}
+
+		for i, r := range response.Responses {
+			result := BatchStatementResult{Item: r.Item}
+			if r.Error != nil {
+				result.Err = &StatementError{
+					Index:   start + i,
+					Code:    string(r.Error.Code),
+					Message: aws.ToString(r.Error.Message),
+				}
+			}
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+// ExecuteTransaction runs up to 100 statements atomically. The returned items
+// are in the same order as the statements. When the transaction is canceled a
+// *TransactionCanceledError tells which statements caused it.
+func (d *dynamodbService) ExecuteTransaction(ctx context.Context, statements []Statement) ([]Item, error) {
+	if len(statements) == 0 {
+		return nil, nil
+	}
+	if len(statements) > transactLimit {
+		return nil, DynamoDBErrTransactLimit
+	}
+	if !readOnly(statements...) {
+		defer d.invalidate()
+	}
+
+	transact := make([]types.ParameterizedStatement, len(statements))
+	for i, statement := range statements {
+		if statement.Statement == "" {
+			return nil, DynamoDBErrStatementNotSet
+		}
+
+		params, err := statementParameters(statement.Parameters, d.marshal)
+		if err != nil {
+			return nil, err
+		}
+
+		transact[i] = types.ParameterizedStatement{
+			Statement:  aws.String(statement.Statement),
+			Parameters: params,
+		}
+	}
+
+	response, err := d.client.ExecuteTransaction(ctx, &dynamodb.ExecuteTransactionInput{
+		TransactStatements: transact,
+	})
+	if err != nil {
+		var canceled *types.TransactionCanceledException
+		if errors.As(err, &canceled) {
+			return nil, transactionCanceledError(DynamoDBErrExecuteTransaction, canceled)
+		}
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrExecuteTransaction, err)
+	}
+
+	items := make([]Item, len(statements))
+	for i, r := range response.Responses {
+		items[i] = r.Item
+	}
+
+	return items, nil
+}
+
+func transactionCanceledError(sentinel error, canceled *types.TransactionCanceledException) *TransactionCanceledError {
+	err := &TransactionCanceledError{
+		Reasons:  make([]*StatementError, len(canceled.CancellationReasons)),
+		Err:      canceled,
+		sentinel: sentinel,
+	}
+
+	for i, reason := range canceled.CancellationReasons {
+		code := aws.ToString(reason.Code)
+		if code == "" || code == "None" {
+			continue
+		}
+
+		err.Reasons[i] = &StatementError{
+			Index:   i,
+			Code:    code,
+			Message: aws.ToString(reason.Message),
+		}
+	}
+
+	return err
+}
+
+// readOnly reports whether the statements only read. Other statements
+// invalidate the whole cache since their tables are not parsed.
+func readOnly(statements ...Statement) bool {
+	for _, statement := range statements {
+		fields := strings.Fields(statement.Statement)
+		if len(fields) == 0 || !strings.EqualFold(fields[0], "SELECT") {
+			return false
+		}
+	}
+	return true
+}
+
+// statementParameters marshals the parameters with the options, so times
+// compare with the stored ones.
+func statementParameters(values []any, opts MarshalOptions) ([]types.AttributeValue, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	params := make([]types.AttributeValue, len(values))
+	for i, value := range values {
+		av, err := attributevalue.MarshalWithOptions(value, opts.encoderOptions)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", DynamoDBErrMarshal, err)
+		}
+		params[i] = av
+	}
+
+	return params, nil
+}
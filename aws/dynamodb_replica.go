@@ -0,0 +1,124 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Replica is a copy of a global table in another region.
+type Replica struct {
+	Region string
+	Status string // e.g., "CREATING", "ACTIVE" or "DELETING"
+	// Details when the status needs attention, e.g., a KMS key that is not
+	// accessible
+	StatusDescription string
+	Progress          string // Percent of the initial copy done while creating
+}
+
+var DynamoDBErrUpdateReplicas = errors.New("failed to update replicas")
+
+// AddReplica starts replicating the table to the region, turning it into a
+// global table. Streams with new and old images are enabled first when
+// needed. The replica is created in the background; use WaitForReplicas to
+// block until it is ACTIVE.
+func (d *dynamodbService) AddReplica(ctx context.Context, table, region string) error {
+	response, err := d.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+	if err != nil {
+		return fmt.Errorf("%w: %w", DynamoDBErrDescribeTable, err)
+	}
+
+	stream := response.Table.StreamSpecification
+	switch {
+	case stream == nil || !aws.ToBool(stream.StreamEnabled):
+		_, err := d.client.UpdateTable(ctx, &dynamodb.UpdateTableInput{
+			TableName: aws.String(table),
+			StreamSpecification: &types.StreamSpecification{
+				StreamEnabled:  aws.Bool(true),
+				StreamViewType: types.StreamViewTypeNewAndOldImages,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("%w: %w", DynamoDBErrUpdateReplicas, err)
+		}
+		if _, err := d.WaitForTableActive(ctx, table); err != nil {
+			return err
+		}
+	case stream.StreamViewType != types.StreamViewTypeNewAndOldImages:
+		return fmt.Errorf("%w: global tables need a %s stream, the table has %s",
+			DynamoDBErrUpdateReplicas, types.StreamViewTypeNewAndOldImages, stream.StreamViewType)
+	}
+
+	return d.updateReplicas(ctx, table, types.ReplicationGroupUpdate{
+		Create: &types.CreateReplicationGroupMemberAction{RegionName: aws.String(region)},
+	})
+}
+
+// RemoveReplica stops replicating the table to the region and deletes the
+// replica there.
+func (d *dynamodbService) RemoveReplica(ctx context.Context, table, region string) error {
+	return d.updateReplicas(ctx, table, types.ReplicationGroupUpdate{
+		Delete: &types.DeleteReplicationGroupMemberAction{RegionName: aws.String(region)},
+	})
+}
+
+// Replicas returns the replication status of the table in each region it is
+// replicated to, empty when it is not a global table.
+func (d *dynamodbService) Replicas(ctx context.Context, table string) ([]Replica, error) {
+	description, err := d.DescribeTable(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+
+	return description.Replicas, nil
+}
+
+// WaitForReplicas blocks until the table and all of its replicas are ACTIVE,
+// and removed replicas are gone, or the context is done.
+func (d *dynamodbService) WaitForReplicas(ctx context.Context, table string) ([]Replica, error) {
+	description, err := d.waitFor(ctx, table, func(description *TableDescription) (bool, error) {
+		if !description.active() {
+			return false, nil
+		}
+		for _, replica := range description.Replicas {
+			if replica.Status != string(types.ReplicaStatusActive) {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return description.Replicas, nil
+}
+
+func (d *dynamodbService) updateReplicas(ctx context.Context, table string, update types.ReplicationGroupUpdate) error {
+	_, err := d.client.UpdateTable(ctx, &dynamodb.UpdateTableInput{
+		TableName:      aws.String(table),
+		ReplicaUpdates: []types.ReplicationGroupUpdate{update},
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", DynamoDBErrUpdateReplicas, err)
+	}
+
+	return nil
+}
+
+func describeReplicas(replicas []types.ReplicaDescription) []Replica {
+	described := make([]Replica, 0, len(replicas))
+	for _, replica := range replicas {
+		described = append(described, Replica{
+			Region:            aws.ToString(replica.RegionName),
+			Status:            string(replica.ReplicaStatus),
+			StatusDescription: aws.ToString(replica.ReplicaStatusDescription),
+			Progress:          aws.ToString(replica.ReplicaStatusPercentProgress),
+		})
+	}
+	return described
+}
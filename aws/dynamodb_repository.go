@@ -0,0 +1,211 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Repository reads and writes items of type T in a single table, so callers
+// work with T and key values instead of items. Keys are named after the
+// schema, and T maps its fields to the same attribute names with its
+// dynamodbav tags (or MarshalOptions.TagKey), e.g.,
+//
+//	type User struct {
+//		ID   string `dynamodbav:"PK"`
+//		Name string `dynamodbav:"name"`
+//	}
+//
+//	users := NewRepository[User](ddb, "users", schema)
+//	user, err := users.Get(ctx, "USER#1", nil)
+type Repository[T any] struct {
+	ddb    DynamoDB
+	table  string
+	schema TableSchema
+	opts   MarshalOptions
+	// Attribute marking soft-deleted items, soft delete is off when empty
+	softDelete string
+}
+
+// NewRepository returns a repository for the table, which defaults to the
+// schema table when empty.
+func NewRepository[T any](ddb DynamoDB, table string, schema TableSchema, opts ...MarshalOptions) *Repository[T] {
+	if table == "" {
+		table = schema.Table
+	}
+
+	var o MarshalOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	return &Repository[T]{ddb: ddb, table: table, schema: schema, opts: o}
+}
+
+// NewRepositoryFor returns a repository for the table with the schema derived
+// from the ddb tags of T, see SchemaOf.
+func NewRepositoryFor[T any](ddb DynamoDB, table string, opts ...MarshalOptions) (*Repository[T], error) {
+	schema, err := SchemaOf[T](table, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRepository[T](ddb, table, *schema, opts...), nil
+}
+
+// Schema returns the schema of the table.
+func (r *Repository[T]) Schema() TableSchema {
+	return r.schema
+}
+
+// Key returns the key of an item. The sort value is ignored when the schema
+// has no sort key.
+func (r *Repository[T]) Key(partition, sort any) Key {
+	key := Key{r.schema.PartitionKey.Name: partition}
+	if r.schema.SortKey != nil {
+		key[r.schema.SortKey.Name] = sort
+	}
+	return key
+}
+
+// Get fetches an item by its key, returning DynamoDBErrItemNotFound when it
+// does not exist.
+func (r *Repository[T]) Get(ctx context.Context, partition, sort any) (T, error) {
+	var out T
+
+	result, err := r.ddb.Get(ctx, GetOptions{
+		Table: r.table,
+		Key:   r.Key(partition, sort),
+	})
+	if err != nil {
+		return out, err
+	}
+	if r.deleted(result.Item) {
+		return out, DynamoDBErrItemNotFound
+	}
+
+	return UnmarshalItem[T](result.Item, r.opts)
+}
+
+// Put creates or replaces the item.
+func (r *Repository[T]) Put(ctx context.Context, item T, opts ...PutOptions) error {
+	av, err := MarshalItem(item, r.opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.ddb.Put(ctx, r.table, av, opts...)
+	return err
+}
+
+// Delete removes an item by its key, or marks it as deleted with soft delete.
+// Deleting a missing item is not an error.
+func (r *Repository[T]) Delete(ctx context.Context, partition, sort any) error {
+	if r.softDelete != "" {
+		return r.markDeleted(ctx, partition, sort)
+	}
+
+	_, err := r.ddb.Delete(ctx, DeleteOptions{
+		Table: r.table,
+		Key:   r.Key(partition, sort),
+	})
+	return err
+}
+
+// Update applies the update to an item and returns it as updated. With soft
+// delete, DynamoDBErrItemNotFound is returned for deleted items.
+func (r *Repository[T]) Update(ctx context.Context, partition, sort any, update *Update) (T, error) {
+	var out T
+
+	opts := UpdateOptions{
+		Table:        r.table,
+		Key:          r.Key(partition, sort),
+		Update:       update,
+		ReturnValues: ReturnAllNew,
+	}
+	if r.softDelete != "" {
+		opts.Condition = &Where{Conditions: []WhereCondition{NotExists(r.softDelete)}}
+	}
+
+	result, err := r.ddb.Update(ctx, opts)
+	if r.softDelete != "" && errors.Is(err, DynamoDBErrConditionFailed) {
+		return out, DynamoDBErrItemNotFound
+	}
+	if err != nil {
+		return out, err
+	}
+
+	return UnmarshalItem[T](result.Attributes, r.opts)
+}
+
+// QueryByPartition reads every item of the partition, skipping soft-deleted
+// items. The options may narrow the query with Sort, Where, Order and Fields,
+// while Table and Partition are set by the repository. Like QueryAll, reading
+// stops with DynamoDBErrMaxItems after 10000 items.
+func (r *Repository[T]) QueryByPartition(ctx context.Context, partition any, opts ...QueryOptions) ([]T, error) {
+	var o QueryOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	o.Table = r.table
+	o.Index = ""
+	o.Partition = &QueryKeyValue{Key: r.schema.PartitionKey.Name, Value: partition}
+	o.Where = r.live(o.Where)
+
+	result, err := r.ddb.QueryAll(ctx, o, 0)
+	if result == nil {
+		return nil, err
+	}
+
+	items, unmarshalErr := UnmarshalItems[T](result.Items, r.opts)
+	if unmarshalErr != nil {
+		return nil, unmarshalErr
+	}
+	return items, err
+}
+
+// QueryByIndex reads every item of the partition of an index declared in the
+// schema, like QueryByPartition.
+func (r *Repository[T]) QueryByIndex(ctx context.Context, index string, partition any, opts ...QueryOptions) ([]T, error) {
+	key, err := r.indexPartitionKey(index)
+	if err != nil {
+		return nil, err
+	}
+
+	var o QueryOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	o.Table = r.table
+	o.Index = index
+	o.Partition = &QueryKeyValue{Key: key, Value: partition}
+	o.Where = r.live(o.Where)
+
+	result, err := r.ddb.QueryAll(ctx, o, 0)
+	if result == nil {
+		return nil, err
+	}
+
+	items, unmarshalErr := UnmarshalItems[T](result.Items, r.opts)
+	if unmarshalErr != nil {
+		return nil, unmarshalErr
+	}
+	return items, err
+}
+
+// indexPartitionKey returns the partition key name of the index. Local indexes
+// share the partition key of the table.
+func (r *Repository[T]) indexPartitionKey(index string) (string, error) {
+	for _, global := range r.schema.GlobalIndexes {
+		if global.Name == index {
+			return global.PartitionKey.Name, nil
+		}
+	}
+	for _, local := range r.schema.LocalIndexes {
+		if local.Name == index {
+			return r.schema.PartitionKey.Name, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", DynamoDBErrIndexNotFound, index)
+}
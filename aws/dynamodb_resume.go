@@ -0,0 +1,60 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// SegmentEnd is checkpointed once every page of a scan segment was handled.
+const SegmentEnd = "SEGMENT_END"
+
+var DynamoDBErrJobNotSet = errors.New("checkpoint job not set")
+
+// segmentID names the checkpoint of a segment. The total is part of it, so a
+// job restarted with another number of segments starts over.
+func segmentID(job string, segment, totalSegments int32) string {
+	return fmt.Sprintf("%s#%d/%d", job, segment, totalSegments)
+}
+
+// loadSegment returns the cursor to resume the segment from, and whether the
+// segment was already scanned.
+func (d *dynamodbService) loadSegment(ctx context.Context, checkpointer Checkpointer, job string, segment, totalSegments int32) (string, bool, error) {
+	if checkpointer == nil {
+		return "", false, nil
+	}
+
+	checkpoint, err := checkpointer.Load(ctx, segmentID(job, segment, totalSegments))
+	if err != nil {
+		return "", false, fmt.Errorf("%w: %w", DynamoDBErrCheckpoint, err)
+	}
+	if checkpoint == SegmentEnd {
+		return "", true, nil
+	}
+
+	return checkpoint, false, nil
+}
+
+// saveSegment checkpoints the last key of a handled page, or SegmentEnd after
+// the last page. It is saved even when the context is canceled, so the work
+// done so far is kept.
+func (d *dynamodbService) saveSegment(ctx context.Context, checkpointer Checkpointer, job string, segment, totalSegments int32, lastKey Item) error {
+	if checkpointer == nil {
+		return nil
+	}
+
+	checkpoint := SegmentEnd
+	if len(lastKey) > 0 {
+		var err error
+		if checkpoint, err = encodeCursor(lastKey, d.cursor); err != nil {
+			return err
+		}
+	}
+
+	err := checkpointer.Checkpoint(context.WithoutCancel(ctx), segmentID(job, segment, totalSegments), checkpoint)
+	if err != nil {
+		return fmt.Errorf("%w: %w", DynamoDBErrCheckpoint, err)
+	}
+
+	return nil
+}
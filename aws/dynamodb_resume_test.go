@@ -0,0 +1,112 @@
+package aws
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// failCall returns a before hook failing the nth call of the operation.
+func failCall(operation string, n int) func(string, map[string]any) (any, error) {
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+	return func(op string, _ map[string]any) (any, error) {
+		if op != operation {
+			return nil, nil
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if calls++; calls == n {
+			return nil, fakeError("InternalServerError")
+		}
+		return nil, nil
+	}
+}
+
+func TestParallelScanResume(t *testing.T) {
+	ctx := context.Background()
+	d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}})
+	putItems(t, d, "users", 40)
+
+	opts := ParallelScanOptions{
+		ScanOptions:   ScanOptions{Table: "users", Limit: 3},
+		TotalSegments: 4,
+		Checkpointer:  NewMemoryCheckpointer(),
+		Job:           "export",
+	}
+
+	var read []Item
+	first := opts
+	first.OnItems = func(_ int32, items []Item) error {
+		read = append(read, items...)
+		return nil
+	}
+	fake.before = failCall("Scan", 6)
+	if _, err := d.ParallelScan(ctx, first); err == nil {
+		t.Fatal("ParallelScan() succeeded, want the failed page")
+	}
+	fake.before = nil
+
+	result, err := d.ParallelScan(ctx, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Items) == 40 {
+		t.Error("resumed ParallelScan() read the table again")
+	}
+	if got := itemIDs(append(read, result.Items...)); !slices.Equal(got, wantIDs(40)) {
+		t.Errorf("items read over both scans = %v, want each item once", got)
+	}
+
+	result, err = d.ParallelScan(ctx, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Items) != 0 {
+		t.Errorf("finished ParallelScan() read %d items again, want none", len(result.Items))
+	}
+}
+
+func TestCopyTableResume(t *testing.T) {
+	ctx := context.Background()
+	d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}, "copy": {"id"}})
+	putItems(t, d, "users", 60)
+
+	opts := CopyTableOptions{
+		TotalSegments: 1,
+		Checkpointer:  NewMemoryCheckpointer(),
+		Job:           "copy",
+	}
+	// A single segment read in pages of 10 items, each written in one batch
+	scanPage := func(op string, input map[string]any) (any, error) {
+		if op == "Scan" {
+			input["Limit"] = float64(10)
+		}
+		return nil, nil
+	}
+
+	failBatch := failCall("BatchWriteItem", 4)
+	fake.before = func(op string, input map[string]any) (any, error) {
+		scanPage(op, input)
+		return failBatch(op, input)
+	}
+	if _, err := d.CopyTable(ctx, "users", "copy", opts); err == nil {
+		t.Fatal("CopyTable() succeeded, want the failed batch")
+	}
+
+	fake.before = scanPage
+	result, err := d.CopyTable(ctx, "users", "copy", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Read != 30 {
+		t.Errorf("resumed CopyTable() read %d items, want the 30 after the 3 written pages", result.Read)
+	}
+	if fake.count("copy") != 60 {
+		t.Errorf("copy holds %d items, want 60", fake.count("copy"))
+	}
+}
@@ -0,0 +1,440 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+type (
+	ScanOptions struct {
+		Table  string
+		Index  string   // Optional index to scan instead of the base table
+		Limit  int32    // Desired number of items per page
+		Cursor string   // Base64-encoded LastEvaluatedKey for pagination
+		Fields []string // Attributes to return, all when empty
+		Where  *Where   // Optional filters
+		// Optional read capacity budget, shared by all segments of a parallel
+		// scan
+		Limiter *CapacityLimiter
+		// Optional cap on the pages, scanned items and capacity read, shared
+		// by all segments of a parallel scan
+		Budget *Budget
+		// Report the capacity used in the result
+		ReturnConsumedCapacity bool
+	}
+
+	ParallelScanOptions struct {
+		ScanOptions         // Cursor is ignored, Limit is the page size of each request
+		TotalSegments int32 // Number of segments the table is split into, defaults to 4
+		Concurrency   int   // Max segments scanned at once, defaults to TotalSegments
+		// Optional callback receiving each page as it arrives instead of
+		// merging the items into the result. Calls are serialized unless
+		// ConcurrentOnItems is set.
+		OnItems func(segment int32, items []Item) error
+		// Call OnItems from the segments at once, so a slow callback, e.g.,
+		// writing the items, doesn't hold back the other segments. OnItems
+		// must then be safe for concurrent use. The pages of a segment are
+		// still handled one at a time, in order.
+		ConcurrentOnItems bool
+		// Optional store of the progress of each segment, checkpointed after
+		// each page is handled, so a scan restarted with the same Job and
+		// TotalSegments resumes where it stopped. The result then only holds
+		// the items read since. Use a new Job to scan from the start again.
+		Checkpointer Checkpointer
+		Job          string // Names the checkpoints, required with Checkpointer
+	}
+
+	ScanStreamOptions struct {
+		ScanOptions         // Cursor is ignored, Limit is the page size of each request
+		TotalSegments int32 // Number of segments the table is split into, defaults to 4
+		Workers       int   // Segments scanned at once, defaults to TotalSegments
+		// Items buffered in the channel before the workers block, none by
+		// default
+		Buffer int
+	}
+
+	ScanResult struct {
+		Items            []Item
+		NextCursor       string // Empty when there are no more pages
+		ConsumedCapacity *ConsumedCapacity
+		Count            int64 // Items returned, summed over the pages
+		ScannedCount     int64 // Items evaluated before the filters, summed over the pages
+		// Counts of each page read, in order; segments of a parallel scan are
+		// interleaved
+		Pages []PageCount
+	}
+)
+
+var defaultTotalSegments int32 = 4
+
+var DynamoDBErrScan = errors.New("failed to perform scan")
+
+// Scan reads up to Limit items from the table or index, continuing from the
+// cursor if set.
+func (d *dynamodbService) Scan(ctx context.Context, opts ScanOptions) (*ScanResult, error) {
+	input, err := d.buildScanInput(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = int32(defaultLimit)
+	}
+
+	result := &ScanResult{}
+	for {
+		input.Limit = aws.Int32(limit - int32(len(result.Items)))
+
+		if err := opts.Limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		if err := opts.Budget.check(); err != nil {
+			return nil, err
+		}
+
+		response, err := d.client.Scan(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", DynamoDBErrScan, err)
+		}
+		opts.Limiter.consume(consumed(response.ConsumedCapacity)...)
+		if err := opts.Budget.spend(response.ScannedCount, response.ConsumedCapacity); err != nil {
+			return nil, err
+		}
+
+		if err := d.loadItems(ctx, opts.Table, response.Items...); err != nil {
+			return nil, err
+		}
+		result.Items = append(result.Items, response.Items...)
+		result.ConsumedCapacity = addCapacity(result.ConsumedCapacity, consumed(response.ConsumedCapacity)...)
+		result.addPage(response.Count, response.ScannedCount)
+		input.ExclusiveStartKey = response.LastEvaluatedKey
+
+		if len(response.LastEvaluatedKey) == 0 || int32(len(result.Items)) >= limit {
+			break
+		}
+	}
+
+	result.NextCursor, err = encodeCursor(input.ExclusiveStartKey, d.cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ParallelScan reads the whole table or index by scanning its segments
+// concurrently. The first error cancels the remaining segments.
+func (d *dynamodbService) ParallelScan(ctx context.Context, opts ParallelScanOptions) (*ScanResult, error) {
+	totalSegments := opts.TotalSegments
+	if totalSegments <= 0 {
+		totalSegments = defaultTotalSegments
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 || concurrency > int(totalSegments) {
+		concurrency = int(totalSegments)
+	}
+
+	scanOpts := opts.ScanOptions
+	scanOpts.Cursor = ""
+
+	// Validate once before fanning out
+	if _, err := d.buildScanInput(scanOpts); err != nil {
+		return nil, err
+	}
+	if opts.Checkpointer != nil && opts.Job == "" {
+		return nil, DynamoDBErrJobNotSet
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex // Guards result and firstErr
+		callbackMu sync.Mutex // Serializes OnItems
+		result     = &ScanResult{}
+		firstErr   error
+		sem        = make(chan struct{}, concurrency)
+	)
+
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	for segment := int32(0); segment < totalSegments; segment++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			cursor, done, err := d.loadSegment(ctx, opts.Checkpointer, opts.Job, segment, totalSegments)
+			if err != nil {
+				fail(err)
+				return
+			}
+			if done {
+				return
+			}
+
+			segmentOpts := scanOpts
+			segmentOpts.Cursor = cursor
+
+			handle := func(response *dynamodb.ScanOutput) error {
+				mu.Lock()
+				result.ConsumedCapacity = addCapacity(result.ConsumedCapacity, consumed(response.ConsumedCapacity)...)
+				result.addPage(response.Count, response.ScannedCount)
+				if opts.OnItems == nil {
+					result.Items = append(result.Items, response.Items...)
+				}
+				mu.Unlock()
+
+				if opts.OnItems == nil || len(response.Items) == 0 {
+					return nil
+				}
+				if !opts.ConcurrentOnItems {
+					callbackMu.Lock()
+					defer callbackMu.Unlock()
+				}
+				return opts.OnItems(segment, response.Items)
+			}
+
+			err = d.scanSegment(ctx, segmentOpts, segment, totalSegments, func(response *dynamodb.ScanOutput) error {
+				if err := handle(response); err != nil {
+					return err
+				}
+				return d.saveSegment(ctx, opts.Checkpointer, opts.Job, segment, totalSegments, response.LastEvaluatedKey)
+			})
+			if err != nil {
+				fail(err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ScanStream scans the whole table or index with parallel segment workers
+// and sends the items to the returned channel as they arrive, in no
+// particular order. Workers block while the channel is full, so a slow
+// consumer slows the scan down instead of buffering the table in memory.
+// The items channel is closed when the scan ends; the error channel then
+// receives the first error, if any, and is closed. Cancel the context to stop
+// reading early.
+func (d *dynamodbService) ScanStream(ctx context.Context, opts ScanStreamOptions) (<-chan Item, <-chan error) {
+	items := make(chan Item, max(opts.Buffer, 0))
+	errs := make(chan error, 1)
+
+	totalSegments := opts.TotalSegments
+	if totalSegments <= 0 {
+		totalSegments = defaultTotalSegments
+	}
+
+	workers := opts.Workers
+	if workers <= 0 || workers > int(totalSegments) {
+		workers = int(totalSegments)
+	}
+
+	scanOpts := opts.ScanOptions
+	scanOpts.Cursor = ""
+
+	// Validate once before starting the workers
+	if _, err := d.buildScanInput(scanOpts); err != nil {
+		close(items)
+		errs <- err
+		close(errs)
+		return items, errs
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		segments = make(chan int32)
+	)
+
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for segment := range segments {
+				err := d.scanSegment(ctx, scanOpts, segment, totalSegments, func(response *dynamodb.ScanOutput) error {
+					for _, item := range response.Items {
+						select {
+						case items <- item:
+						case <-ctx.Done():
+							return ctx.Err()
+						}
+					}
+					return nil
+				})
+				if err != nil {
+					fail(err)
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer cancel()
+
+	send:
+		for segment := range totalSegments {
+			select {
+			case segments <- segment:
+			case <-ctx.Done():
+				break send
+			}
+		}
+		close(segments)
+		wg.Wait()
+
+		close(items)
+		if firstErr == nil {
+			firstErr = ctx.Err()
+		}
+		if firstErr != nil {
+			errs <- firstErr
+		}
+		close(errs)
+	}()
+
+	return items, errs
+}
+
+// addPage records the counts reported for a page.
+func (r *ScanResult) addPage(count, scannedCount int32) {
+	r.Count += int64(count)
+	r.ScannedCount += int64(scannedCount)
+	r.Pages = append(r.Pages, PageCount{Count: int64(count), ScannedCount: int64(scannedCount)})
+}
+
+func (d *dynamodbService) scanSegment(ctx context.Context, opts ScanOptions, segment, totalSegments int32, fn func(*dynamodb.ScanOutput) error) error {
+	input, err := d.buildScanInput(opts)
+	if err != nil {
+		return err
+	}
+
+	input.Segment = aws.Int32(segment)
+	input.TotalSegments = aws.Int32(totalSegments)
+	if opts.Limit > 0 {
+		input.Limit = aws.Int32(opts.Limit)
+	}
+
+	paginator := dynamodb.NewScanPaginator(d.client, input)
+	for paginator.HasMorePages() {
+		if err := opts.Limiter.Wait(ctx); err != nil {
+			return err
+		}
+		if err := opts.Budget.check(); err != nil {
+			return err
+		}
+
+		response, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("%w: %w", DynamoDBErrScan, err)
+		}
+		opts.Limiter.consume(consumed(response.ConsumedCapacity)...)
+		if err := opts.Budget.spend(response.ScannedCount, response.ConsumedCapacity); err != nil {
+			return err
+		}
+
+		if err := d.loadItems(ctx, opts.Table, response.Items...); err != nil {
+			return err
+		}
+		if err := fn(response); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *dynamodbService) buildScanInput(opts ScanOptions) (*dynamodb.ScanInput, error) {
+	if opts.Table == "" {
+		return nil, DynamoDBErrTableNotSet
+	}
+
+	startKey, err := decodeCursor(opts.Cursor, d.cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &dynamodb.ScanInput{
+		TableName:              aws.String(opts.Table),
+		ExclusiveStartKey:      startKey,
+		ReturnConsumedCapacity: capacityMode(opts.ReturnConsumedCapacity || opts.Limiter != nil || opts.Budget.tracksCapacity()),
+	}
+
+	if opts.Index != "" {
+		input.IndexName = aws.String(opts.Index)
+	}
+
+	if opts.Where == nil && len(opts.Fields) == 0 {
+		return input, nil
+	}
+
+	builder := expression.NewBuilder()
+
+	if opts.Where != nil {
+		filterExpr, err := d.buildFilterExpression(*opts.Where)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", DynamoDBErrBuildFilterExpression, err)
+		}
+		builder = builder.WithFilter(filterExpr)
+	}
+
+	if len(opts.Fields) > 0 {
+		builder = builder.WithProjection(buildProjection(opts.Fields))
+	}
+
+	expr, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	input.ExpressionAttributeNames = expr.Names()
+	input.ExpressionAttributeValues = expr.Values()
+	input.FilterExpression = expr.Filter()
+	input.ProjectionExpression = expr.Projection()
+
+	return input, nil
+}
@@ -0,0 +1,219 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// putItems stores n items with the ids "0" to n-1 in the table.
+func putItems(t *testing.T, d *dynamodbService, table string, n int) {
+	t.Helper()
+
+	for i := range n {
+		if _, err := d.Put(context.Background(), table, map[string]any{"id": fmt.Sprint(i), "n": i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestParallelScanOnItems(t *testing.T) {
+	const segments = 4
+
+	tests := []struct {
+		concurrent bool
+		want       int // Max callbacks running at once
+	}{
+		{concurrent: false, want: 1},
+		{concurrent: true, want: segments},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint("concurrent=", tt.concurrent), func(t *testing.T) {
+			d, _ := newFakeDynamoDB(t, map[string][]string{"users": {"id"}})
+			putItems(t, d, "users", 4*segments)
+
+			var (
+				mu              sync.Mutex
+				running, most   int
+				read            int
+				allRunning      = make(chan struct{})
+				allRunningClose sync.Once
+			)
+			_, err := d.ParallelScan(context.Background(), ParallelScanOptions{
+				ScanOptions:       ScanOptions{Table: "users", Limit: 2},
+				TotalSegments:     segments,
+				ConcurrentOnItems: tt.concurrent,
+				OnItems: func(_ int32, items []Item) error {
+					mu.Lock()
+					running++
+					most = max(most, running)
+					read += len(items)
+					if running == segments {
+						allRunningClose.Do(func() { close(allRunning) })
+					}
+					mu.Unlock()
+
+					// Give the other segments time to call back meanwhile
+					select {
+					case <-allRunning:
+					case <-time.After(20 * time.Millisecond):
+					}
+
+					mu.Lock()
+					running--
+					mu.Unlock()
+					return nil
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if read != 4*segments {
+				t.Errorf("read %d items, want %d", read, 4*segments)
+			}
+			if most != tt.want {
+				t.Errorf("%d callbacks ran at once, want %d", most, tt.want)
+			}
+		})
+	}
+}
+
+// itemIDs returns the sorted ids of the items.
+func itemIDs(items []Item) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item["id"].(*types.AttributeValueMemberS).Value
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// wantIDs returns the sorted ids of putItems.
+func wantIDs(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprint(i)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestParallelScan(t *testing.T) {
+	d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}})
+	putItems(t, d, "users", 50)
+
+	result, err := d.ParallelScan(context.Background(), ParallelScanOptions{
+		ScanOptions:   ScanOptions{Table: "users", Limit: 3},
+		TotalSegments: 4,
+		Concurrency:   2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := itemIDs(result.Items); !slices.Equal(got, wantIDs(50)) {
+		t.Errorf("ParallelScan() items = %v, want each item once", got)
+	}
+	if result.Count != 50 || result.ScannedCount != 50 {
+		t.Errorf("ParallelScan() counts = %d, %d, want 50", result.Count, result.ScannedCount)
+	}
+	if fake.calls["Scan"] < 50/3 {
+		t.Errorf("ParallelScan() made %d requests, want pages of 3 items", fake.calls["Scan"])
+	}
+}
+
+func TestParallelScanError(t *testing.T) {
+	d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}})
+	putItems(t, d, "users", 20)
+
+	fake.before = func(operation string, input map[string]any) (any, error) {
+		if operation == "Scan" && input["Segment"] == float64(2) {
+			return nil, fakeError("ProvisionedThroughputExceededException")
+		}
+		return nil, nil
+	}
+
+	_, err := d.ParallelScan(context.Background(), ParallelScanOptions{
+		ScanOptions: ScanOptions{Table: "users"},
+	})
+	var throttled *types.ProvisionedThroughputExceededException
+	if !errors.As(err, &throttled) {
+		t.Errorf("ParallelScan() error = %v, want the error of the failed segment", err)
+	}
+}
+
+func TestScanStream(t *testing.T) {
+	d, _ := newFakeDynamoDB(t, map[string][]string{"users": {"id"}})
+	putItems(t, d, "users", 30)
+
+	items, errs := d.ScanStream(context.Background(), ScanStreamOptions{
+		ScanOptions: ScanOptions{Table: "users", Limit: 4},
+		Workers:     2,
+	})
+
+	var read []Item
+	for item := range items {
+		read = append(read, item)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+
+	if got := itemIDs(read); !slices.Equal(got, wantIDs(30)) {
+		t.Errorf("ScanStream() items = %v, want each item once", got)
+	}
+}
+
+func TestScanStreamError(t *testing.T) {
+	d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}})
+	putItems(t, d, "users", 30)
+
+	fake.before = func(operation string, input map[string]any) (any, error) {
+		if operation == "Scan" && input["Segment"] == float64(1) {
+			return nil, fakeError("ProvisionedThroughputExceededException")
+		}
+		return nil, nil
+	}
+
+	items, errs := d.ScanStream(context.Background(), ScanStreamOptions{
+		ScanOptions: ScanOptions{Table: "users"},
+	})
+	for range items {
+	}
+
+	var throttled *types.ProvisionedThroughputExceededException
+	if err := <-errs; !errors.As(err, &throttled) {
+		t.Errorf("ScanStream() error = %v, want the error of the failed segment", err)
+	}
+}
+
+func TestScanStreamCancel(t *testing.T) {
+	d, _ := newFakeDynamoDB(t, map[string][]string{"users": {"id"}})
+	putItems(t, d, "users", 30)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	items, errs := d.ScanStream(ctx, ScanStreamOptions{ScanOptions: ScanOptions{Table: "users"}})
+	<-items
+	cancel()
+
+	// The workers stop instead of blocking on the unread items
+	read := 1
+	for range items {
+		read++
+	}
+	if read == 30 {
+		t.Error("ScanStream() sent every item after the cancellation")
+	}
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("ScanStream() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,305 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	globalIndexLimit = 20 // Default max GSIs per table
+	localIndexLimit  = 5  // Max LSIs per table
+)
+
+// TableSchema declares a table, its indexes and TTL attribute. It can be kept
+// in YAML, e.g.,
+//
+//	table: movies
+//	partition_key: {name: year, type: N}
+//	sort_key: {name: title}
+//	global_indexes:
+//	  - name: GenreIndex
+//	    partition_key: {name: genre}
+//	ttl_attribute: expires_at
+type TableSchema struct {
+	Table         string            `yaml:"table"`
+	PartitionKey  KeyAttribute      `yaml:"partition_key"`
+	SortKey       *KeyAttribute     `yaml:"sort_key,omitempty"`
+	BillingMode   BillingMode       `yaml:"billing_mode,omitempty"` // Defaults to BillingPayPerRequest
+	Throughput    *Throughput       `yaml:"throughput,omitempty"`   // Required for BillingProvisioned
+	GlobalIndexes []GlobalIndex     `yaml:"global_indexes,omitempty"`
+	LocalIndexes  []LocalIndex      `yaml:"local_indexes,omitempty"`
+	TTLAttribute  string            `yaml:"ttl_attribute,omitempty"` // TTL is left disabled when empty
+	Tags          map[string]string `yaml:"tags,omitempty"`
+}
+
+var (
+	DynamoDBErrDescribeTTL    = errors.New("failed to describe time to live")
+	DynamoDBErrInvalidSchema  = errors.New("invalid table schema")
+	DynamoDBErrSchemaMismatch = errors.New("table does not match schema")
+)
+
+// ParseTableSchema reads and validates a schema from YAML.
+func ParseTableSchema(data []byte) (*TableSchema, error) {
+	var schema TableSchema
+	if err := yaml.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrInvalidSchema, err)
+	}
+
+	if err := schema.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &schema, nil
+}
+
+// YAML serializes the schema.
+func (s TableSchema) YAML() ([]byte, error) {
+	return yaml.Marshal(s)
+}
+
+// Validate checks the schema for mistakes DynamoDB would reject, such as
+// missing keys, duplicate index names, or an attribute used with two types.
+func (s TableSchema) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]any{DynamoDBErrInvalidSchema}, args...)...))
+	}
+
+	if s.Table == "" {
+		invalid("table not set")
+	}
+	if s.PartitionKey.Name == "" {
+		invalid("partition key not set")
+	}
+
+	switch s.BillingMode {
+	case "", BillingPayPerRequest:
+	case BillingProvisioned:
+		if s.Throughput == nil {
+			invalid("provisioned billing requires throughput")
+		}
+	default:
+		invalid("unsupported billing mode %q", s.BillingMode)
+	}
+
+	if len(s.GlobalIndexes) > globalIndexLimit {
+		invalid("more than %d global indexes", globalIndexLimit)
+	}
+	if len(s.LocalIndexes) > localIndexLimit {
+		invalid("more than %d local indexes", localIndexLimit)
+	}
+	if len(s.LocalIndexes) > 0 && s.SortKey == nil {
+		invalid("local indexes require a table sort key")
+	}
+
+	attributeTypes := map[string]AttributeType{}
+	checkKey := func(owner string, key KeyAttribute) {
+		if key.Name == "" {
+			invalid("%s key not set", owner)
+			return
+		}
+
+		attributeType := key.attributeType()
+		switch attributeType {
+		case AttributeString, AttributeNumber, AttributeBinary:
+		default:
+			invalid("%s key %s has unsupported type %q", owner, key.Name, key.Type)
+			return
+		}
+
+		if defined, ok := attributeTypes[key.Name]; ok && defined != attributeType {
+			invalid("attribute %s is both %s and %s", key.Name, defined, attributeType)
+		}
+		attributeTypes[key.Name] = attributeType
+	}
+
+	checkKey("partition", s.PartitionKey)
+	if s.SortKey != nil {
+		checkKey("sort", *s.SortKey)
+	}
+
+	names := map[string]bool{}
+	checkName := func(name string) {
+		if name == "" {
+			invalid("index name not set")
+		} else if names[name] {
+			invalid("duplicate index %s", name)
+		}
+		names[name] = true
+	}
+
+	for _, index := range s.GlobalIndexes {
+		checkName(index.Name)
+		checkKey(index.Name+" partition", index.PartitionKey)
+		if index.SortKey != nil {
+			checkKey(index.Name+" sort", *index.SortKey)
+		}
+	}
+	for _, index := range s.LocalIndexes {
+		checkName(index.Name)
+		checkKey(index.Name+" sort", index.SortKey)
+	}
+
+	return errors.Join(errs...)
+}
+
+// CreateTableOptions returns the options to create the table. The TTL
+// attribute is not part of them, see CreateTableFromSchema.
+func (s TableSchema) CreateTableOptions() CreateTableOptions {
+	return CreateTableOptions{
+		Table:         s.Table,
+		PartitionKey:  s.PartitionKey,
+		SortKey:       s.SortKey,
+		BillingMode:   s.BillingMode,
+		Throughput:    s.Throughput,
+		GlobalIndexes: s.GlobalIndexes,
+		LocalIndexes:  s.LocalIndexes,
+		Tags:          s.Tags,
+	}
+}
+
+// CreateTableFromSchema creates the table described by the schema. When the
+// schema has a TTL attribute it waits for the table to be ACTIVE and enables
+// TTL.
+func (d *dynamodbService) CreateTableFromSchema(ctx context.Context, schema TableSchema) (*TableDescription, error) {
+	if err := schema.Validate(); err != nil {
+		return nil, err
+	}
+
+	description, err := d.CreateTable(ctx, schema.CreateTableOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	if schema.TTLAttribute == "" {
+		return description, nil
+	}
+
+	description, err = d.WaitForTableActive(ctx, schema.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.EnableTTL(ctx, schema.Table, schema.TTLAttribute); err != nil {
+		return nil, err
+	}
+
+	return description, nil
+}
+
+// VerifySchema compares the existing table against the schema. Each
+// difference is reported as an error wrapping DynamoDBErrSchemaMismatch.
+func (d *dynamodbService) VerifySchema(ctx context.Context, schema TableSchema) error {
+	description, err := d.DescribeTable(ctx, schema.Table)
+	if err != nil {
+		return err
+	}
+
+	ttl, err := d.client.DescribeTimeToLive(ctx, &dynamodb.DescribeTimeToLiveInput{
+		TableName: aws.String(schema.Table),
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", DynamoDBErrDescribeTTL, err)
+	}
+
+	var ttlAttribute string
+	if t := ttl.TimeToLiveDescription; t != nil &&
+		(t.TimeToLiveStatus == types.TimeToLiveStatusEnabled || t.TimeToLiveStatus == types.TimeToLiveStatusEnabling) {
+		ttlAttribute = aws.ToString(t.AttributeName)
+	}
+
+	return schema.mismatches(description, ttlAttribute)
+}
+
+func (s TableSchema) mismatches(description *TableDescription, ttlAttribute string) error {
+	var errs []error
+	mismatch := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]any{DynamoDBErrSchemaMismatch}, args...)...))
+	}
+
+	if !sameKey(s.PartitionKey, &description.PartitionKey) {
+		mismatch("partition key is %s", description.PartitionKey.Name)
+	}
+	if !sameSortKey(s.SortKey, description.SortKey) {
+		mismatch("sort key differs")
+	}
+
+	billingMode := s.BillingMode
+	if billingMode == "" {
+		billingMode = BillingPayPerRequest
+	}
+	if billingMode != description.BillingMode {
+		mismatch("billing mode is %s", description.BillingMode)
+	}
+	if billingMode == BillingProvisioned && s.Throughput != nil && description.Throughput != nil &&
+		*s.Throughput != *description.Throughput {
+		mismatch("throughput is %d/%d", description.Throughput.Read, description.Throughput.Write)
+	}
+
+	if s.TTLAttribute != ttlAttribute {
+		mismatch("ttl attribute is %q", ttlAttribute)
+	}
+
+	indexes := make(map[string]IndexDescription, len(description.Indexes))
+	for _, index := range description.Indexes {
+		indexes[index.Name] = index
+	}
+
+	for _, index := range s.GlobalIndexes {
+		existing, ok := indexes[index.Name]
+		delete(indexes, index.Name)
+
+		switch {
+		case !ok || !existing.Global:
+			mismatch("global index %s is missing", index.Name)
+		case !sameKey(index.PartitionKey, &existing.PartitionKey) || !sameSortKey(index.SortKey, existing.SortKey):
+			mismatch("global index %s keys differ", index.Name)
+		case !sameProjection(index.Projection, existing.Projection):
+			mismatch("global index %s projects %s", index.Name, existing.Projection)
+		}
+	}
+
+	for _, index := range s.LocalIndexes {
+		existing, ok := indexes[index.Name]
+		delete(indexes, index.Name)
+
+		switch {
+		case !ok || existing.Global:
+			mismatch("local index %s is missing", index.Name)
+		case !sameSortKey(&index.SortKey, existing.SortKey):
+			mismatch("local index %s keys differ", index.Name)
+		case !sameProjection(index.Projection, existing.Projection):
+			mismatch("local index %s projects %s", index.Name, existing.Projection)
+		}
+	}
+
+	for name := range indexes {
+		mismatch("index %s is not in the schema", name)
+	}
+
+	return errors.Join(errs...)
+}
+
+func sameKey(key KeyAttribute, other *KeyAttribute) bool {
+	return other != nil && key.Name == other.Name && key.attributeType() == other.attributeType()
+}
+
+func sameSortKey(key, other *KeyAttribute) bool {
+	if key == nil || other == nil {
+		return key == nil && other == nil
+	}
+	return sameKey(*key, other)
+}
+
+func sameProjection(projectionType, other ProjectionType) bool {
+	if projectionType == "" {
+		projectionType = ProjectAll
+	}
+	return projectionType == other
+}
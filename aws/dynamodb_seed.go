@@ -0,0 +1,139 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// Fixtures are the items to seed by table. Fixture files map table names
+	// to lists of items in plain JSON or YAML, e.g.,
+	//
+	//	users:
+	//	  - id: USER#1
+	//	    name: Ada
+	Fixtures map[string][]Item
+
+	SeedOptions struct {
+		// Delete the existing items of the tables before writing the fixtures
+		Truncate bool
+	}
+
+	SeedResult struct {
+		Deleted map[string]int // Items removed by Truncate, by table
+		Written map[string]int // By table
+	}
+)
+
+var (
+	DynamoDBErrFixture = errors.New("invalid fixture")
+	DynamoDBErrSeed    = errors.New("failed to seed table")
+)
+
+// LoadFixtures reads fixture files, picking the format from the extension
+// (.json, .yaml or .yml). Items of the same table across files are merged.
+func LoadFixtures(paths ...string) (Fixtures, error) {
+	fixtures := Fixtures{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", DynamoDBErrFixture, err)
+		}
+
+		parsed, err := ParseFixtures(data, filepath.Ext(path))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for table, items := range parsed {
+			fixtures[table] = append(fixtures[table], items...)
+		}
+	}
+
+	return fixtures, nil
+}
+
+// ParseFixtures reads fixtures in the format of the extension, e.g., ".yaml".
+func ParseFixtures(data []byte, ext string) (Fixtures, error) {
+	var tables map[string][]map[string]any
+	switch strings.ToLower(ext) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&tables); err != nil {
+			return nil, fmt.Errorf("%w: %w", DynamoDBErrFixture, err)
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &tables); err != nil {
+			return nil, fmt.Errorf("%w: %w", DynamoDBErrFixture, err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", DynamoDBErrFixture, ext)
+	}
+
+	fixtures := make(Fixtures, len(tables))
+	for table, values := range tables {
+		items := make([]Item, len(values))
+		for i, value := range values {
+			item, err := attributevalue.MarshalMap(jsonNumbers(value))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s[%d]: %w", DynamoDBErrFixture, table, i, err)
+			}
+			items[i] = item
+		}
+		fixtures[table] = items
+	}
+
+	return fixtures, nil
+}
+
+// Seed writes the fixtures into their tables, which must exist. Tables are
+// seeded in name order.
+func (d *dynamodbService) Seed(ctx context.Context, fixtures Fixtures, opts ...SeedOptions) (*SeedResult, error) {
+	var o SeedOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	result := &SeedResult{Deleted: map[string]int{}, Written: map[string]int{}}
+	tables := make([]string, 0, len(fixtures))
+	for table := range fixtures {
+		tables = append(tables, table)
+	}
+	slices.Sort(tables)
+
+	for _, table := range tables {
+		if o.Truncate {
+			deleted, err := d.Truncate(ctx, table)
+			if err != nil {
+				return result, err
+			}
+			result.Deleted[table] = deleted
+		}
+
+		puts := make([]any, len(fixtures[table]))
+		for i, item := range fixtures[table] {
+			puts[i] = item
+		}
+
+		written, err := d.BatchWrite(ctx, BatchWriteOptions{Table: table, Puts: puts})
+		if err != nil {
+			return result, err
+		}
+		result.Written[table] = written.Written
+		if len(written.FailedPuts) > 0 {
+			return result, fmt.Errorf("%w: %s: %d items unprocessed", DynamoDBErrSeed, table, len(written.FailedPuts))
+		}
+	}
+
+	return result, nil
+}
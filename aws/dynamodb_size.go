@@ -0,0 +1,111 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const ItemSizeLimit = 400 * 1024 // Max size of an item in DynamoDB, in bytes
+
+// ItemTooLargeError reports an item rejected before it was sent because it
+// is larger than the max item size.
+type ItemTooLargeError struct {
+	Size  int // Bytes, as computed by ItemSize
+	Limit int
+}
+
+var DynamoDBErrItemTooLarge = errors.New("item too large")
+
+func (e *ItemTooLargeError) Error() string {
+	return fmt.Sprintf("%s: %d bytes, limit is %d", DynamoDBErrItemTooLarge, e.Size, e.Limit)
+}
+
+func (e *ItemTooLargeError) Unwrap() error {
+	return DynamoDBErrItemTooLarge
+}
+
+// ItemSize returns the size DynamoDB counts for the item, i.e., the lengths of
+// the attribute names plus the sizes of their values.
+func ItemSize(item Item) int {
+	var size int
+	for name, value := range item {
+		size += len(name) + attributeSize(value)
+	}
+	return size
+}
+
+// checkItemSize returns an *ItemTooLargeError when the item is larger than
+// limit. A limit of zero skips the check.
+func checkItemSize(item Item, limit int) error {
+	if limit <= 0 {
+		return nil
+	}
+
+	if size := ItemSize(item); size > limit {
+		return &ItemTooLargeError{Size: size, Limit: limit}
+	}
+	return nil
+}
+
+func attributeSize(value types.AttributeValue) int {
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		return len(v.Value)
+	case *types.AttributeValueMemberN:
+		return numberSize(v.Value)
+	case *types.AttributeValueMemberB:
+		return len(v.Value)
+	case *types.AttributeValueMemberBOOL, *types.AttributeValueMemberNULL:
+		return 1
+	case *types.AttributeValueMemberSS:
+		var size int
+		for _, s := range v.Value {
+			size += len(s)
+		}
+		return size
+	case *types.AttributeValueMemberNS:
+		var size int
+		for _, n := range v.Value {
+			size += numberSize(n)
+		}
+		return size
+	case *types.AttributeValueMemberBS:
+		var size int
+		for _, b := range v.Value {
+			size += len(b)
+		}
+		return size
+	case *types.AttributeValueMemberL:
+		// 3 bytes for the list plus 1 byte per element
+		size := 3
+		for _, element := range v.Value {
+			size += 1 + attributeSize(element)
+		}
+		return size
+	case *types.AttributeValueMemberM:
+		size := 3
+		for name, element := range v.Value {
+			size += 1 + len(name) + attributeSize(element)
+		}
+		return size
+	default:
+		return 0
+	}
+}
+
+// numberSize is 1 byte per 2 significant digits plus 1 byte.
+func numberSize(n string) int {
+	n = strings.TrimLeft(n, "+-")
+	if i := strings.IndexAny(n, "eE"); i >= 0 {
+		n = n[:i]
+	}
+
+	digits := strings.Trim(strings.Replace(n, ".", "", 1), "0")
+	if digits == "" {
+		return 1
+	}
+	return (len(digits)+1)/2 + 1
+}
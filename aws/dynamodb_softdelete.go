@@ -0,0 +1,158 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+)
+
+const DefaultSoftDeleteAttribute = "deleted_at"
+
+// WithSoftDelete returns a copy of the repository whose deletes set the
+// attribute to the deletion time, stored like MarshalOptions.TimeFormat,
+// instead of removing the items. Get, Update and the queries then skip the
+// deleted items, while Restore and Purge undo or complete the deletes. The
+// attribute defaults to "deleted_at".
+func (r *Repository[T]) WithSoftDelete(attribute string) *Repository[T] {
+	if attribute == "" {
+		attribute = DefaultSoftDeleteAttribute
+	}
+
+	soft := *r
+	soft.softDelete = attribute
+	return &soft
+}
+
+// Restore removes the deletion mark of a soft-deleted item.
+func (r *Repository[T]) Restore(ctx context.Context, partition, sort any) error {
+	if r.softDelete == "" {
+		return nil
+	}
+
+	return r.ddb.RemoveAttributes(ctx, r.table, r.Key(partition, sort), r.softDelete)
+}
+
+// HardDelete removes an item by its key, even with soft delete.
+func (r *Repository[T]) HardDelete(ctx context.Context, partition, sort any) error {
+	_, err := r.ddb.Delete(ctx, DeleteOptions{
+		Table: r.table,
+		Key:   r.Key(partition, sort),
+	})
+	return err
+}
+
+// Purge removes the items soft-deleted before the time, e.g.,
+// time.Now().AddDate(0, 0, -30) to keep deleted items for 30 days, and
+// returns how many were removed. The table is scanned in parallel, so purges
+// are best run off-peak.
+func (r *Repository[T]) Purge(ctx context.Context, before time.Time) (int, error) {
+	if r.softDelete == "" {
+		return 0, nil
+	}
+
+	names := []string{r.schema.PartitionKey.Name}
+	if r.schema.SortKey != nil {
+		names = append(names, r.schema.SortKey.Name)
+	}
+
+	var (
+		mu     sync.Mutex
+		purged int
+	)
+	_, err := r.ddb.ParallelScan(ctx, ParallelScanOptions{
+		ScanOptions: ScanOptions{
+			Table:  r.table,
+			Fields: names,
+			Where: &Where{Conditions: []WhereCondition{
+				Lt(r.softDelete, EncodeTime(before.UTC(), r.opts.TimeFormat)),
+			}},
+		},
+		ConcurrentOnItems: true,
+		OnItems: func(_ int32, items []Item) error {
+			keys := make([]Key, len(items))
+			for i, item := range items {
+				key, err := keyValues(itemKey(item, names))
+				if err != nil {
+					return err
+				}
+				keys[i] = key
+			}
+
+			result, err := r.ddb.BatchWrite(ctx, BatchWriteOptions{Table: r.table, Deletes: keys})
+			if err != nil {
+				return err
+			}
+			if len(result.FailedDeletes) > 0 {
+				return DynamoDBErrUnprocessed
+			}
+
+			mu.Lock()
+			purged += result.Written
+			mu.Unlock()
+			return nil
+		},
+	})
+
+	return purged, err
+}
+
+// markDeleted sets the deletion time of an existing item that is not deleted
+// yet, so deleting twice keeps the first time.
+func (r *Repository[T]) markDeleted(ctx context.Context, partition, sort any) error {
+	_, err := r.ddb.Update(ctx, UpdateOptions{
+		Table:  r.table,
+		Key:    r.Key(partition, sort),
+		Update: NewUpdate().Set(r.softDelete, EncodeTime(time.Now().UTC(), r.opts.TimeFormat)),
+		Condition: &Where{Conditions: []WhereCondition{
+			Exists(r.schema.PartitionKey.Name),
+			NotExists(r.softDelete),
+		}},
+	})
+	if errors.Is(err, DynamoDBErrConditionFailed) {
+		return nil
+	}
+
+	return err
+}
+
+// deleted reports whether the item is soft-deleted.
+func (r *Repository[T]) deleted(item Item) bool {
+	if r.softDelete == "" {
+		return false
+	}
+
+	_, ok := item[r.softDelete]
+	return ok
+}
+
+// live narrows the filters of a query to the items that are not
+// soft-deleted.
+func (r *Repository[T]) live(where *Where) *Where {
+	if r.softDelete == "" {
+		return where
+	}
+
+	live := &Where{Conditions: []WhereCondition{NotExists(r.softDelete)}}
+	if where != nil {
+		live.Groups = []Where{*where}
+	}
+	return live
+}
+
+// keyValues converts a key read from the table back into a Key, keeping
+// numbers exact.
+func keyValues(key Item) (Key, error) {
+	values := Key{}
+	err := attributevalue.UnmarshalMapWithOptions(key, &values, func(o *attributevalue.DecoderOptions) {
+		o.UseNumber = true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrUnmarshal, err)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,103 @@
+package aws
+
+import (
+	"context"
+	"errors"
+)
+
+// SparseIndex is a GSI holding only the items that have its key attributes,
+// e.g., open orders flagged with an "open_since" sort key. Setting the
+// attributes adds an item to the index and removing them takes it out, each
+// with a single update of the item, e.g.,
+//
+//	open := NewSparseIndex(ddb, "orders", "OpenOrders", "status", "open_since")
+//	err := open.Add(ctx, Key{"PK": "ORDER#1"}, "OPEN", time.Now().Unix())
+//	result, err := open.Query(ctx, "OPEN")
+type SparseIndex struct {
+	ddb          DynamoDB
+	table        string
+	index        string
+	partitionKey string
+	sortKey      string // Empty when the index has no sort key
+}
+
+// NewSparseIndex returns the index of the table keyed on the attributes.
+// The sort key is empty when the index has none.
+func NewSparseIndex(ddb DynamoDB, table, index, partitionKey, sortKey string) *SparseIndex {
+	return &SparseIndex{
+		ddb:          ddb,
+		table:        table,
+		index:        index,
+		partitionKey: partitionKey,
+		sortKey:      sortKey,
+	}
+}
+
+// Add sets the index key attributes of an existing item, adding it to the
+// index. The sort value is ignored when the index has no sort key.
+// DynamoDBErrItemNotFound is returned when the item does not exist instead of
+// creating it.
+func (s *SparseIndex) Add(ctx context.Context, key Key, partition, sort any) error {
+	update := NewUpdate().Set(s.partitionKey, partition)
+	if s.sortKey != "" {
+		update.Set(s.sortKey, sort)
+	}
+
+	var exists []WhereCondition
+	for name := range key {
+		exists = append(exists, Exists(name))
+	}
+
+	_, err := s.ddb.Update(ctx, UpdateOptions{
+		Table:     s.table,
+		Key:       key,
+		Update:    update,
+		Condition: &Where{Conditions: exists},
+	})
+	if errors.Is(err, DynamoDBErrConditionFailed) {
+		return DynamoDBErrItemNotFound
+	}
+
+	return err
+}
+
+// Remove deletes the index key attributes of the item, taking it out of the
+// index while keeping the item.
+func (s *SparseIndex) Remove(ctx context.Context, key Key) error {
+	fields := []string{s.partitionKey}
+	if s.sortKey != "" {
+		fields = append(fields, s.sortKey)
+	}
+
+	return s.ddb.RemoveAttributes(ctx, s.table, key, fields...)
+}
+
+// Query reads every item of the index partition, up to the QueryAll limit.
+// The options may narrow the query with Sort, Where, Order, Fields and
+// Hydrate, while Table, Index and Partition are set by the index.
+func (s *SparseIndex) Query(ctx context.Context, partition any, opts ...QueryOptions) (*QueryResult, error) {
+	var o QueryOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	o.Table = s.table
+	o.Index = s.index
+	o.Partition = &QueryKeyValue{Key: s.partitionKey, Value: partition}
+
+	return s.ddb.QueryAll(ctx, o, 0)
+}
+
+// Scan reads every item in the index, which is only the items that have its
+// key attributes, in parallel.
+func (s *SparseIndex) Scan(ctx context.Context, opts ...ParallelScanOptions) (*ScanResult, error) {
+	var o ParallelScanOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	o.Table = s.table
+	o.Index = s.index
+
+	return s.ddb.ParallelScan(ctx, o)
+}
@@ -0,0 +1,515 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	streamtypes "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+)
+
+// ShardEnd is checkpointed once every record of a closed shard was handled.
+const ShardEnd = "SHARD_END"
+
+const (
+	StreamInsert StreamEventName = "INSERT"
+	StreamModify StreamEventName = "MODIFY"
+	StreamRemove StreamEventName = "REMOVE"
+)
+
+var (
+	streamPollInterval    = time.Second      // Wait between empty reads of an open shard
+	streamRefreshInterval = 30 * time.Second // Wait between shard discoveries
+	streamBatchSize       = int32(1000)      // Max records per GetRecords request
+)
+
+type (
+	StreamEventName string
+
+	// StreamRecord is a change of an item. A StreamConsumer decrypts the
+	// images and reads back their offloaded attributes like Get does, using
+	// the Encryption and Offload of its Config.
+	StreamRecord struct {
+		EventID        string
+		EventName      StreamEventName
+		ShardID        string
+		SequenceNumber string
+		Keys           Item
+		NewImage       Item // Set for INSERT and MODIFY when the stream includes new images
+		OldImage       Item // Set for MODIFY and REMOVE when the stream includes old images
+		CreatedAt      time.Time
+	}
+
+	// StreamHandler is called for each record, in order within a shard. An
+	// error stops the consumer without checkpointing the record.
+	StreamHandler func(ctx context.Context, record StreamRecord) error
+
+	// Checkpointer stores the last handled sequence number of each shard so a
+	// restarted consumer resumes where it stopped.
+	Checkpointer interface {
+		Checkpoint(ctx context.Context, shardID, sequenceNumber string) error
+		// Returns an empty string when the shard has no checkpoint
+		Load(ctx context.Context, shardID string) (string, error)
+	}
+
+	StreamConsumerOptions struct {
+		Table     string // Used to look up the latest stream when StreamARN is empty
+		StreamARN string
+		Handler   StreamHandler
+		// Defaults to an in-memory checkpointer, which starts over on restart
+		Checkpointer Checkpointer
+		// Start new shards at the latest record instead of the oldest one,
+		// also when their iterator expires before a record was handled
+		StartFromLatest bool
+		PollInterval    time.Duration // Defaults to 1s
+		RefreshInterval time.Duration // Defaults to 30s
+		BatchSize       int32         // Defaults to 1000
+	}
+
+	// StreamConsumer reads every shard of a DynamoDB stream, handling parent
+	// shards before their children so records of an item stay in order.
+	StreamConsumer struct {
+		client *dynamodbstreams.Client
+		ddb    *dynamodbService // Describes the table and loads the images
+		opts   StreamConsumerOptions
+
+		mu     sync.Mutex
+		shards map[string]*shardState
+	}
+
+	shardState struct {
+		parent  string
+		started bool
+		done    bool
+	}
+
+	// MemoryCheckpointer keeps checkpoints in memory.
+	MemoryCheckpointer struct {
+		mu          sync.Mutex
+		checkpoints map[string]string
+	}
+
+	// TableCheckpointer keeps checkpoints in a DynamoDB table with a string
+	// partition key named "id". Items are stored as {"id": "<consumer>#<shard>",
+	// "sequence_number": "..."}.
+	TableCheckpointer struct {
+		ddb      DynamoDB
+		table    string
+		consumer string
+	}
+
+	checkpointItem struct {
+		ID             string `dynamodbav:"id"`
+		SequenceNumber string `dynamodbav:"sequence_number"`
+	}
+)
+
+var (
+	DynamoDBErrCheckpoint      = errors.New("failed to checkpoint")
+	DynamoDBErrHandlerNotSet   = errors.New("stream handler not set")
+	DynamoDBErrReadStream      = errors.New("failed to read stream")
+	DynamoDBErrStreamNotActive = errors.New("stream not enabled on table")
+)
+
+func NewStreamConsumer(config Config, opts StreamConsumerOptions) *StreamConsumer {
+	awsConfig := load(&config)
+
+	if opts.Checkpointer == nil {
+		opts.Checkpointer = NewMemoryCheckpointer()
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = streamPollInterval
+	}
+	if opts.RefreshInterval <= 0 {
+		opts.RefreshInterval = streamRefreshInterval
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = streamBatchSize
+	}
+
+	return &StreamConsumer{
+		client: dynamodbstreams.NewFromConfig(awsConfig),
+		ddb:    newDynamoDBService(config, awsConfig),
+		opts:   opts,
+		shards: map[string]*shardState{},
+	}
+}
+
+// Run consumes the stream until the context is cancelled or the handler
+// fails. On cancellation the records being handled are finished and
+// checkpointed before Run returns nil.
+func (c *StreamConsumer) Run(ctx context.Context) error {
+	if c.opts.Handler == nil {
+		return DynamoDBErrHandlerNotSet
+	}
+
+	streamARN, err := c.streamARN(ctx)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	ticker := time.NewTicker(c.opts.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.discover(ctx, streamARN); err != nil && ctx.Err() == nil {
+			fail(err)
+		}
+
+		for _, shardID := range c.ready() {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := c.consume(ctx, streamARN, shardID); err != nil {
+					fail(err)
+				}
+			}()
+		}
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return firstErr
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *StreamConsumer) streamARN(ctx context.Context) (string, error) {
+	if c.opts.StreamARN != "" {
+		return c.opts.StreamARN, nil
+	}
+	if c.opts.Table == "" {
+		return "", DynamoDBErrTableNotSet
+	}
+
+	response, err := c.ddb.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(c.opts.Table)})
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", DynamoDBErrDescribeTable, err)
+	}
+	if response.Table == nil || response.Table.LatestStreamArn == nil {
+		return "", DynamoDBErrStreamNotActive
+	}
+
+	return aws.ToString(response.Table.LatestStreamArn), nil
+}
+
+// discover adds the shards of the stream that are not known yet.
+func (c *StreamConsumer) discover(ctx context.Context, streamARN string) error {
+	input := &dynamodbstreams.DescribeStreamInput{StreamArn: aws.String(streamARN)}
+
+	for {
+		response, err := c.client.DescribeStream(ctx, input)
+		if err != nil {
+			return fmt.Errorf("%w: %w", DynamoDBErrReadStream, err)
+		}
+
+		c.mu.Lock()
+		for _, shard := range response.StreamDescription.Shards {
+			id := aws.ToString(shard.ShardId)
+			if _, ok := c.shards[id]; !ok {
+				c.shards[id] = &shardState{parent: aws.ToString(shard.ParentShardId)}
+			}
+		}
+		c.mu.Unlock()
+
+		if response.StreamDescription.LastEvaluatedShardId == nil {
+			return nil
+		}
+		input.ExclusiveStartShardId = response.StreamDescription.LastEvaluatedShardId
+	}
+}
+
+// ready marks and returns the shards that can be consumed, those whose parent
+// is done or no longer part of the stream.
+func (c *StreamConsumer) ready() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var ids []string
+	for id, shard := range c.shards {
+		if shard.started {
+			continue
+		}
+		if parent, ok := c.shards[shard.parent]; ok && !parent.done {
+			continue
+		}
+
+		shard.started = true
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+func (c *StreamConsumer) finish(shardID string) {
+	c.mu.Lock()
+	c.shards[shardID].done = true
+	c.mu.Unlock()
+}
+
+// consume reads the shard until it is closed or the context is cancelled.
+func (c *StreamConsumer) consume(ctx context.Context, streamARN, shardID string) error {
+	checkpoint, err := c.opts.Checkpointer.Load(ctx, shardID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", DynamoDBErrCheckpoint, err)
+	}
+	if checkpoint == ShardEnd {
+		c.finish(shardID)
+		return nil
+	}
+
+	iterator, err := c.iterator(ctx, streamARN, shardID, checkpoint, c.opts.StartFromLatest)
+	if err != nil {
+		return err
+	}
+
+	for iterator != nil {
+		response, err := c.client.GetRecords(ctx, &dynamodbstreams.GetRecordsInput{
+			ShardIterator: iterator,
+			Limit:         aws.Int32(c.opts.BatchSize),
+		})
+
+		var expired *streamtypes.ExpiredIteratorException
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case errors.As(err, &expired):
+			// Resume after the last record handled with a fresh iterator, or
+			// where the consumer starts when none was handled yet.
+			if iterator, err = c.iterator(ctx, streamARN, shardID, checkpoint, c.opts.StartFromLatest); err != nil {
+				return err
+			}
+			continue
+		case err != nil:
+			return fmt.Errorf("%w: %w", DynamoDBErrReadStream, err)
+		}
+
+		for _, r := range response.Records {
+			record := streamRecord(shardID, r)
+			if err := c.load(ctx, streamTable(streamARN), record); err != nil {
+				return err
+			}
+			if err := c.opts.Handler(ctx, record); err != nil {
+				return err
+			}
+			checkpoint = record.SequenceNumber
+		}
+
+		iterator = response.NextShardIterator
+		if iterator == nil {
+			checkpoint = ShardEnd
+		}
+
+		if len(response.Records) > 0 || iterator == nil {
+			// Save progress even when shutting down
+			if err := c.opts.Checkpointer.Checkpoint(context.WithoutCancel(ctx), shardID, checkpoint); err != nil {
+				return fmt.Errorf("%w: %w", DynamoDBErrCheckpoint, err)
+			}
+		}
+
+		if len(response.Records) == 0 && iterator != nil {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(c.opts.PollInterval):
+			}
+		}
+	}
+
+	c.finish(shardID)
+	return nil
+}
+
+// load decrypts and rehydrates the images of the record in place, like the
+// items read by Get.
+func (c *StreamConsumer) load(ctx context.Context, table string, record StreamRecord) error {
+	var images []Item
+	for _, image := range []Item{record.NewImage, record.OldImage} {
+		if image != nil {
+			images = append(images, image)
+		}
+	}
+
+	if err := c.ddb.loadItems(ctx, table, images...); err != nil {
+		return fmt.Errorf("%w: record %s: %w", DynamoDBErrReadStream, record.EventID, err)
+	}
+	return nil
+}
+
+// iterator returns an iterator after the checkpoint, or without one, at the
+// latest or oldest record of the shard.
+func (c *StreamConsumer) iterator(ctx context.Context, streamARN, shardID, checkpoint string, latest bool) (*string, error) {
+	input := &dynamodbstreams.GetShardIteratorInput{
+		StreamArn:         aws.String(streamARN),
+		ShardId:           aws.String(shardID),
+		ShardIteratorType: streamtypes.ShardIteratorTypeTrimHorizon,
+	}
+
+	switch {
+	case checkpoint != "":
+		input.ShardIteratorType = streamtypes.ShardIteratorTypeAfterSequenceNumber
+		input.SequenceNumber = aws.String(checkpoint)
+	case latest:
+		input.ShardIteratorType = streamtypes.ShardIteratorTypeLatest
+	}
+
+	response, err := c.client.GetShardIterator(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrReadStream, err)
+	}
+
+	return response.ShardIterator, nil
+}
+
+func NewMemoryCheckpointer() *MemoryCheckpointer {
+	return &MemoryCheckpointer{checkpoints: map[string]string{}}
+}
+
+func (m *MemoryCheckpointer) Checkpoint(_ context.Context, shardID, sequenceNumber string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.checkpoints[shardID] = sequenceNumber
+	return nil
+}
+
+func (m *MemoryCheckpointer) Load(_ context.Context, shardID string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.checkpoints[shardID], nil
+}
+
+// NewTableCheckpointer stores the checkpoints of the named consumer in the
+// table, so several consumers can share it.
+func NewTableCheckpointer(ddb DynamoDB, table, consumer string) *TableCheckpointer {
+	return &TableCheckpointer{ddb: ddb, table: table, consumer: consumer}
+}
+
+func (t *TableCheckpointer) Checkpoint(ctx context.Context, shardID, sequenceNumber string) error {
+	_, err := t.ddb.Put(ctx, t.table, checkpointItem{
+		ID:             t.id(shardID),
+		SequenceNumber: sequenceNumber,
+	})
+	return err
+}
+
+func (t *TableCheckpointer) Load(ctx context.Context, shardID string) (string, error) {
+	result, err := t.ddb.Get(ctx, GetOptions{
+		Table:          t.table,
+		Key:            Key{"id": t.id(shardID)},
+		ConsistentRead: true,
+	})
+	if errors.Is(err, DynamoDBErrItemNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	item, err := UnmarshalItem[checkpointItem](result.Item)
+	if err != nil {
+		return "", err
+	}
+
+	return item.SequenceNumber, nil
+}
+
+func (t *TableCheckpointer) id(shardID string) string {
+	return t.consumer + "#" + shardID
+}
+
+// streamTable returns the table of the stream from its ARN, e.g.,
+// "arn:aws:dynamodb:us-east-1:123456789012:table/users/stream/2025-01-01T00:00:00.000".
+func streamTable(streamARN string) string {
+	_, resource, _ := strings.Cut(streamARN, ":table/")
+	table, _, _ := strings.Cut(resource, "/")
+	return table
+}
+
+func streamRecord(shardID string, r streamtypes.Record) StreamRecord {
+	record := StreamRecord{
+		EventID:   aws.ToString(r.EventID),
+		EventName: StreamEventName(r.EventName),
+		ShardID:   shardID,
+	}
+
+	if r.Dynamodb != nil {
+		record.SequenceNumber = aws.ToString(r.Dynamodb.SequenceNumber)
+		record.Keys = streamItem(r.Dynamodb.Keys)
+		record.NewImage = streamItem(r.Dynamodb.NewImage)
+		record.OldImage = streamItem(r.Dynamodb.OldImage)
+		record.CreatedAt = aws.ToTime(r.Dynamodb.ApproximateCreationDateTime)
+	}
+
+	return record
+}
+
+// streamItem converts a stream image, which uses the attribute value types of
+// the streams API, into an Item.
+func streamItem(image map[string]streamtypes.AttributeValue) Item {
+	if image == nil {
+		return nil
+	}
+
+	item := make(Item, len(image))
+	for name, value := range image {
+		item[name] = streamValue(value)
+	}
+
+	return item
+}
+
+func streamValue(value streamtypes.AttributeValue) types.AttributeValue {
+	switch v := value.(type) {
+	case *streamtypes.AttributeValueMemberS:
+		return &types.AttributeValueMemberS{Value: v.Value}
+	case *streamtypes.AttributeValueMemberN:
+		return &types.AttributeValueMemberN{Value: v.Value}
+	case *streamtypes.AttributeValueMemberB:
+		return &types.AttributeValueMemberB{Value: v.Value}
+	case *streamtypes.AttributeValueMemberBOOL:
+		return &types.AttributeValueMemberBOOL{Value: v.Value}
+	case *streamtypes.AttributeValueMemberNULL:
+		return &types.AttributeValueMemberNULL{Value: v.Value}
+	case *streamtypes.AttributeValueMemberSS:
+		return &types.AttributeValueMemberSS{Value: v.Value}
+	case *streamtypes.AttributeValueMemberNS:
+		return &types.AttributeValueMemberNS{Value: v.Value}
+	case *streamtypes.AttributeValueMemberBS:
+		return &types.AttributeValueMemberBS{Value: v.Value}
+	case *streamtypes.AttributeValueMemberL:
+		list := make([]types.AttributeValue, len(v.Value))
+		for i, element := range v.Value {
+			list[i] = streamValue(element)
+		}
+		return &types.AttributeValueMemberL{Value: list}
+	case *streamtypes.AttributeValueMemberM:
+		return &types.AttributeValueMemberM{Value: streamItem(v.Value)}
+	default:
+		return &types.AttributeValueMemberNULL{Value: true}
+	}
+}
@@ -0,0 +1,210 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// consumeRecords runs the consumer until it handled n records or failed.
+func consumeRecords(t *testing.T, consumer *StreamConsumer, n int) ([]StreamRecord, error) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var records []StreamRecord
+	consumer.opts.Handler = func(_ context.Context, record StreamRecord) error {
+		records = append(records, record)
+		if len(records) == n {
+			cancel()
+		}
+		return nil
+	}
+
+	err := consumer.Run(ctx)
+	if len(records) < n && err == nil {
+		t.Fatalf("handled %d records, want %d", len(records), n)
+	}
+	return records, err
+}
+
+func TestStreamConsumerLoadsImages(t *testing.T) {
+	ctx := context.Background()
+	d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}})
+	d.encryption = &EncryptionConfig{KeyID: "alias/test", Fields: []string{"ssn"}}
+
+	if _, err := d.Put(ctx, "users", map[string]any{"id": "1", "ssn": "123-45-6789"}); err != nil {
+		t.Fatal(err)
+	}
+	key := map[string]any{"id": map[string]any{"S": "1"}}
+	stored := fake.stored("users", key)
+	if _, ok := stored["ssn"].(map[string]any)["B"]; !ok {
+		t.Fatalf("stored ssn = %v, want ciphertext", stored["ssn"])
+	}
+	fake.record(StreamModify, key, stored, stored)
+
+	records, err := consumeRecords(t, newFakeStreamConsumer(d, fake, StreamConsumerOptions{}), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, image := range map[string]Item{"new": records[0].NewImage, "old": records[0].OldImage} {
+		ssn, ok := image["ssn"].(*types.AttributeValueMemberS)
+		if !ok || ssn.Value != "123-45-6789" {
+			t.Errorf("%s image ssn = %#v, want the plaintext", name, image["ssn"])
+		}
+		if _, ok := image[encryptedKey]; ok {
+			t.Errorf("%s image still holds the data key", name)
+		}
+	}
+}
+
+func TestStreamTable(t *testing.T) {
+	if got := streamTable(fakeStreamARN); got != "users" {
+		t.Errorf("streamTable() = %q, want users", got)
+	}
+	if got := streamTable("not an arn"); got != "" {
+		t.Errorf("streamTable() = %q, want empty", got)
+	}
+}
+
+func TestStreamConsumerExpiredIterator(t *testing.T) {
+	d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}})
+	user := func(id string) map[string]any {
+		return map[string]any{"id": map[string]any{"S": id}}
+	}
+	fake.record(StreamInsert, user("1"), user("1"), nil)
+
+	// The first iterator expires after a second record was written, and a
+	// third one is written once the shard is read again.
+	var reads int
+	fake.before = func(operation string, _ map[string]any) (any, error) {
+		if operation != "GetRecords" {
+			return nil, nil
+		}
+		switch reads++; reads {
+		case 1:
+			fake.record(StreamInsert, user("2"), user("2"), nil)
+			return nil, fakeError("ExpiredIteratorException")
+		case 2:
+			fake.record(StreamInsert, user("3"), user("3"), nil)
+		}
+		return nil, nil
+	}
+
+	consumer := newFakeStreamConsumer(d, fake, StreamConsumerOptions{StartFromLatest: true})
+	records, err := consumeRecords(t, consumer, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id := records[0].Keys["id"].(*types.AttributeValueMemberS).Value; id != "3" {
+		t.Errorf("first record handled = %s, want 3 from the latest iterator", id)
+	}
+}
+
+// userRecords adds INSERT records of the users with the ids to the stream.
+func userRecords(fake *fakeDynamoDB, ids ...string) {
+	for _, id := range ids {
+		image := map[string]any{"id": map[string]any{"S": id}}
+		fake.record(StreamInsert, image, image, nil)
+	}
+}
+
+func TestStreamConsumer(t *testing.T) {
+	d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}})
+	userRecords(fake, "1", "2", "3")
+	fake.closed = true
+
+	checkpointer := NewMemoryCheckpointer()
+	consumer := newFakeStreamConsumer(d, fake, StreamConsumerOptions{Checkpointer: checkpointer, BatchSize: 2})
+	records, err := consumeRecords(t, consumer, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, record := range records {
+		if id := record.Keys["id"].(*types.AttributeValueMemberS).Value; id != fmt.Sprint(i+1) {
+			t.Errorf("record %d has id %s, want the records in order", i, id)
+		}
+		if record.EventName != StreamInsert || record.ShardID != fakeShardID || record.NewImage == nil {
+			t.Errorf("record %d = %+v, want an INSERT of the shard with its image", i, record)
+		}
+	}
+
+	if checkpoint, _ := checkpointer.Load(context.Background(), fakeShardID); checkpoint != ShardEnd {
+		t.Errorf("checkpoint = %q, want ShardEnd after the closed shard", checkpoint)
+	}
+}
+
+func TestStreamConsumerResume(t *testing.T) {
+	ctx := context.Background()
+	d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}})
+	userRecords(fake, "1", "2", "3", "4")
+
+	checkpointer := NewMemoryCheckpointer()
+	if err := checkpointer.Checkpoint(ctx, fakeShardID, "2"); err != nil {
+		t.Fatal(err)
+	}
+
+	consumer := newFakeStreamConsumer(d, fake, StreamConsumerOptions{Checkpointer: checkpointer})
+	records, err := consumeRecords(t, consumer, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if records[0].SequenceNumber != "3" || records[1].SequenceNumber != "4" {
+		t.Errorf("records = %s, %s, want 3 and 4 after the checkpoint", records[0].SequenceNumber, records[1].SequenceNumber)
+	}
+	if checkpoint, _ := checkpointer.Load(ctx, fakeShardID); checkpoint != "4" {
+		t.Errorf("checkpoint = %q, want 4", checkpoint)
+	}
+}
+
+func TestStreamConsumerHandlerError(t *testing.T) {
+	d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}})
+	userRecords(fake, "1", "2", "3")
+
+	failed := errors.New("handler failed")
+	checkpointer := NewMemoryCheckpointer()
+	consumer := newFakeStreamConsumer(d, fake, StreamConsumerOptions{Checkpointer: checkpointer, BatchSize: 1})
+	consumer.opts.Handler = func(_ context.Context, record StreamRecord) error {
+		if record.SequenceNumber == "2" {
+			return failed
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := consumer.Run(ctx); !errors.Is(err, failed) {
+		t.Fatalf("Run() error = %v, want the handler error", err)
+	}
+
+	if checkpoint, _ := checkpointer.Load(context.Background(), fakeShardID); checkpoint != "1" {
+		t.Errorf("checkpoint = %q, want 1 before the failed record", checkpoint)
+	}
+}
+
+func TestTableCheckpointer(t *testing.T) {
+	ctx := context.Background()
+	d, _ := newFakeDynamoDB(t, map[string][]string{"checkpoints": {"id"}})
+
+	orders := NewTableCheckpointer(d, "checkpoints", "orders")
+	if err := orders.Checkpoint(ctx, fakeShardID, "42"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := orders.Load(ctx, fakeShardID); err != nil || got != "42" {
+		t.Errorf("Load() = %q, %v, want 42", got, err)
+	}
+	// Consumers sharing the table keep their own checkpoints
+	if got, err := NewTableCheckpointer(d, "checkpoints", "audit").Load(ctx, fakeShardID); err != nil || got != "" {
+		t.Errorf("Load() of another consumer = %q, %v, want none", got, err)
+	}
+}
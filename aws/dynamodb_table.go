@@ -0,0 +1,584 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	AttributeString AttributeType = "S"
+	AttributeNumber AttributeType = "N"
+	AttributeBinary AttributeType = "B"
+)
+
+const (
+	BillingPayPerRequest BillingMode = "PAY_PER_REQUEST"
+	BillingProvisioned   BillingMode = "PROVISIONED"
+)
+
+const (
+	ProjectAll      ProjectionType = "ALL"
+	ProjectKeysOnly ProjectionType = "KEYS_ONLY"
+	ProjectInclude  ProjectionType = "INCLUDE"
+)
+
+var tablePollInterval = 5 * time.Second
+
+type (
+	AttributeType string
+
+	BillingMode string
+
+	ProjectionType string
+
+	KeyAttribute struct {
+		Name string        `yaml:"name"`
+		Type AttributeType `yaml:"type,omitempty"` // Defaults to AttributeString
+	}
+
+	Throughput struct {
+		Read  int64 `yaml:"read"`
+		Write int64 `yaml:"write"`
+	}
+
+	CreateTableOptions struct {
+		Table         string
+		PartitionKey  KeyAttribute
+		SortKey       *KeyAttribute
+		BillingMode   BillingMode // Defaults to BillingPayPerRequest
+		Throughput    *Throughput // Required for BillingProvisioned
+		GlobalIndexes []GlobalIndex
+		LocalIndexes  []LocalIndex
+		Tags          map[string]string
+	}
+
+	GlobalIndex struct {
+		Name             string         `yaml:"name"`
+		PartitionKey     KeyAttribute   `yaml:"partition_key"`
+		SortKey          *KeyAttribute  `yaml:"sort_key,omitempty"`
+		Projection       ProjectionType `yaml:"projection,omitempty"`         // Defaults to ProjectAll
+		NonKeyAttributes []string       `yaml:"non_key_attributes,omitempty"` // Attributes projected with ProjectInclude
+		// Defaults to the table throughput on provisioned tables
+		Throughput *Throughput `yaml:"throughput,omitempty"`
+	}
+
+	// LocalIndex shares the partition key of the table, with another sort key.
+	LocalIndex struct {
+		Name             string         `yaml:"name"`
+		SortKey          KeyAttribute   `yaml:"sort_key"`
+		Projection       ProjectionType `yaml:"projection,omitempty"`         // Defaults to ProjectAll
+		NonKeyAttributes []string       `yaml:"non_key_attributes,omitempty"` // Attributes projected with ProjectInclude
+	}
+
+	UpdateTableOptions struct {
+		Table         string
+		BillingMode   BillingMode   // Optional billing mode switch
+		Throughput    *Throughput   // Table throughput, required when switching to BillingProvisioned
+		CreateIndexes []GlobalIndex // GSIs to add
+		DeleteIndexes []string      // GSI names to remove
+		Wait          bool          // Block until the table and its indexes are ACTIVE
+	}
+
+	TableDescription struct {
+		Name         string
+		ARN          string
+		Status       string // e.g., "CREATING" or "ACTIVE"
+		PartitionKey KeyAttribute
+		SortKey      *KeyAttribute
+		BillingMode  BillingMode
+		Throughput   *Throughput // Set for provisioned tables
+		Indexes      []IndexDescription
+		Replicas     []Replica // Regions of a global table
+		ItemCount    int64
+		SizeBytes    int64
+		CreatedAt    time.Time
+	}
+
+	IndexDescription struct {
+		Name         string
+		Global       bool   // False for local secondary indexes
+		Status       string // Empty for local secondary indexes
+		PartitionKey KeyAttribute
+		SortKey      *KeyAttribute
+		Projection   ProjectionType
+	}
+)
+
+var (
+	DynamoDBErrCreateTable   = errors.New("failed to create table")
+	DynamoDBErrDeleteTable   = errors.New("failed to delete table")
+	DynamoDBErrDescribeTable = errors.New("failed to describe table")
+	DynamoDBErrIndexNotFound = errors.New("index not found")
+	DynamoDBErrKeyNotSet     = errors.New("partition key not set")
+	DynamoDBErrUpdateTable   = errors.New("failed to update table")
+)
+
+// CreateTable creates the table and returns its description. The table is
+// usually still being created when this returns.
+func (d *dynamodbService) CreateTable(ctx context.Context, opts CreateTableOptions) (*TableDescription, error) {
+	if opts.Table == "" {
+		return nil, DynamoDBErrTableNotSet
+	}
+	if opts.PartitionKey.Name == "" {
+		return nil, DynamoDBErrKeyNotSet
+	}
+
+	schema, definitions := keySchema(opts.PartitionKey, opts.SortKey)
+
+	input := &dynamodb.CreateTableInput{
+		TableName:            aws.String(opts.Table),
+		KeySchema:            schema,
+		AttributeDefinitions: definitions,
+		BillingMode:          types.BillingModePayPerRequest,
+	}
+
+	if opts.BillingMode == BillingProvisioned {
+		if opts.Throughput == nil {
+			return nil, fmt.Errorf("%w: provisioned billing requires throughput", DynamoDBErrCreateTable)
+		}
+
+		input.BillingMode = types.BillingModeProvisioned
+		input.ProvisionedThroughput = opts.Throughput.provisioned()
+	}
+
+	for _, index := range opts.GlobalIndexes {
+		if index.Name == "" || index.PartitionKey.Name == "" {
+			return nil, DynamoDBErrKeyNotSet
+		}
+		if index.Throughput == nil && input.BillingMode == types.BillingModeProvisioned {
+			index.Throughput = opts.Throughput
+		}
+
+		create, indexDefinitions := index.create()
+		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, types.GlobalSecondaryIndex{
+			IndexName:             create.IndexName,
+			KeySchema:             create.KeySchema,
+			Projection:            create.Projection,
+			ProvisionedThroughput: create.ProvisionedThroughput,
+		})
+		input.AttributeDefinitions = mergeDefinitions(input.AttributeDefinitions, indexDefinitions...)
+	}
+
+	for _, index := range opts.LocalIndexes {
+		if index.Name == "" || index.SortKey.Name == "" {
+			return nil, DynamoDBErrKeyNotSet
+		}
+
+		indexSchema, indexDefinitions := keySchema(opts.PartitionKey, &index.SortKey)
+		input.LocalSecondaryIndexes = append(input.LocalSecondaryIndexes, types.LocalSecondaryIndex{
+			IndexName:  aws.String(index.Name),
+			KeySchema:  indexSchema,
+			Projection: projection(index.Projection, index.NonKeyAttributes),
+		})
+		input.AttributeDefinitions = mergeDefinitions(input.AttributeDefinitions, indexDefinitions...)
+	}
+
+	for key, value := range opts.Tags {
+		input.Tags = append(input.Tags, types.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+
+	response, err := d.client.CreateTable(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrCreateTable, err)
+	}
+
+	return describeTable(response.TableDescription), nil
+}
+
+// DeleteTable deletes the table and all of its items.
+func (d *dynamodbService) DeleteTable(ctx context.Context, table string) error {
+	if table == "" {
+		return DynamoDBErrTableNotSet
+	}
+
+	defer d.invalidate(table)
+
+	if _, err := d.client.DeleteTable(ctx, &dynamodb.DeleteTableInput{TableName: aws.String(table)}); err != nil {
+		return fmt.Errorf("%w: %w", DynamoDBErrDeleteTable, err)
+	}
+
+	return nil
+}
+
+// Truncate deletes every item of the table, reading only the keys, and
+// returns the number of items deleted. Unlike deleting and recreating the
+// table, its indexes, streams and settings are kept, though every delete
+// consumes write capacity.
+func (d *dynamodbService) Truncate(ctx context.Context, table string) (int, error) {
+	description, err := d.DescribeTable(ctx, table)
+	if err != nil {
+		return 0, err
+	}
+
+	fields := []string{description.PartitionKey.Name}
+	if description.SortKey != nil {
+		fields = append(fields, description.SortKey.Name)
+	}
+
+	var (
+		mu      sync.Mutex
+		deleted int
+	)
+	_, err = d.ParallelScan(ctx, ParallelScanOptions{
+		ScanOptions:       ScanOptions{Table: table, Fields: fields},
+		ConcurrentOnItems: true,
+		OnItems: func(_ int32, items []Item) error {
+			opts := BatchWriteOptions{Table: table}
+			result := &BatchWriteResult{}
+			for batch := range slices.Chunk(items, batchWriteLimit) {
+				requests := make([]types.WriteRequest, len(batch))
+				for i, key := range batch {
+					requests[i] = types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: key}}
+				}
+
+				unprocessed, err := d.batchWrite(ctx, opts, requests, result)
+				if err != nil {
+					return err
+				}
+				if len(unprocessed) > 0 {
+					return fmt.Errorf("%w: %s: %d deletes unprocessed", DynamoDBErrBatchWrite, table, len(unprocessed))
+				}
+
+				mu.Lock()
+				deleted += len(batch)
+				mu.Unlock()
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return deleted, err
+	}
+
+	return deleted, nil
+}
+
+// DescribeTable returns the key schema, billing and indexes of the table.
+func (d *dynamodbService) DescribeTable(ctx context.Context, table string) (*TableDescription, error) {
+	if table == "" {
+		return nil, DynamoDBErrTableNotSet
+	}
+
+	response, err := d.client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrDescribeTable, err)
+	}
+
+	return describeTable(response.Table), nil
+}
+
+// UpdateTable changes the billing mode, throughput and GSIs of the table.
+// DynamoDB only allows a single index change per request, so each change is
+// sent on its own, waiting for the table to be ACTIVE in between.
+func (d *dynamodbService) UpdateTable(ctx context.Context, opts UpdateTableOptions) (*TableDescription, error) {
+	if opts.Table == "" {
+		return nil, DynamoDBErrTableNotSet
+	}
+
+	var steps []*dynamodb.UpdateTableInput
+
+	if opts.BillingMode != "" || opts.Throughput != nil {
+		input := &dynamodb.UpdateTableInput{TableName: aws.String(opts.Table)}
+		if opts.BillingMode != "" {
+			input.BillingMode = types.BillingMode(opts.BillingMode)
+		}
+		if opts.Throughput != nil {
+			input.ProvisionedThroughput = opts.Throughput.provisioned()
+		}
+		steps = append(steps, input)
+	}
+
+	for _, index := range opts.CreateIndexes {
+		if index.Name == "" || index.PartitionKey.Name == "" {
+			return nil, DynamoDBErrKeyNotSet
+		}
+
+		create, definitions := index.create()
+		steps = append(steps, &dynamodb.UpdateTableInput{
+			TableName:                   aws.String(opts.Table),
+			AttributeDefinitions:        definitions,
+			GlobalSecondaryIndexUpdates: []types.GlobalSecondaryIndexUpdate{{Create: create}},
+		})
+	}
+
+	for _, name := range opts.DeleteIndexes {
+		steps = append(steps, &dynamodb.UpdateTableInput{
+			TableName: aws.String(opts.Table),
+			GlobalSecondaryIndexUpdates: []types.GlobalSecondaryIndexUpdate{{
+				Delete: &types.DeleteGlobalSecondaryIndexAction{IndexName: aws.String(name)},
+			}},
+		})
+	}
+
+	var description *TableDescription
+	for i, input := range steps {
+		if i > 0 {
+			if _, err := d.WaitForTableActive(ctx, opts.Table); err != nil {
+				return nil, err
+			}
+		}
+
+		response, err := d.client.UpdateTable(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", DynamoDBErrUpdateTable, err)
+		}
+		description = describeTable(response.TableDescription)
+	}
+
+	if opts.Wait || description == nil {
+		return d.WaitForTableActive(ctx, opts.Table)
+	}
+
+	return description, nil
+}
+
+// TableExists reports whether the table exists, in any status.
+func (d *dynamodbService) TableExists(ctx context.Context, table string) (bool, error) {
+	_, err := d.DescribeTable(ctx, table)
+	if err == nil {
+		return true, nil
+	}
+
+	var notFound *types.ResourceNotFoundException
+	if errors.As(err, &notFound) {
+		return false, nil
+	}
+
+	return false, err
+}
+
+// WaitForTableActive blocks until the table and all of its GSIs are ACTIVE,
+// or the context is done.
+func (d *dynamodbService) WaitForTableActive(ctx context.Context, table string) (*TableDescription, error) {
+	return d.waitFor(ctx, table, func(description *TableDescription) (bool, error) {
+		return description.active(), nil
+	})
+}
+
+// WaitForIndexActive blocks until the GSI is ACTIVE, or the context is done.
+// DynamoDBErrIndexNotFound is returned when the table has no such index.
+func (d *dynamodbService) WaitForIndexActive(ctx context.Context, table, index string) error {
+	_, err := d.waitFor(ctx, table, func(description *TableDescription) (bool, error) {
+		for _, i := range description.Indexes {
+			if i.Name == index {
+				return !i.Global || i.Status == string(types.IndexStatusActive), nil
+			}
+		}
+		return false, fmt.Errorf("%w: %s", DynamoDBErrIndexNotFound, index)
+	})
+
+	return err
+}
+
+// waitFor polls the table description until done reports true.
+func (d *dynamodbService) waitFor(ctx context.Context, table string, done func(*TableDescription) (bool, error)) (*TableDescription, error) {
+	ticker := time.NewTicker(tablePollInterval)
+	defer ticker.Stop()
+
+	for {
+		description, err := d.DescribeTable(ctx, table)
+		if err != nil {
+			return nil, err
+		}
+
+		ok, err := done(description)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return description, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (t *TableDescription) active() bool {
+	if t.Status != string(types.TableStatusActive) {
+		return false
+	}
+
+	for _, index := range t.Indexes {
+		if index.Global && index.Status != string(types.IndexStatusActive) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (i GlobalIndex) create() (*types.CreateGlobalSecondaryIndexAction, []types.AttributeDefinition) {
+	schema, definitions := keySchema(i.PartitionKey, i.SortKey)
+
+	create := &types.CreateGlobalSecondaryIndexAction{
+		IndexName:  aws.String(i.Name),
+		KeySchema:  schema,
+		Projection: projection(i.Projection, i.NonKeyAttributes),
+	}
+	if i.Throughput != nil {
+		create.ProvisionedThroughput = i.Throughput.provisioned()
+	}
+
+	return create, definitions
+}
+
+func projection(projectionType ProjectionType, nonKeyAttributes []string) *types.Projection {
+	if projectionType == "" {
+		projectionType = ProjectAll
+	}
+
+	p := &types.Projection{ProjectionType: types.ProjectionType(projectionType)}
+	if projectionType == ProjectInclude {
+		p.NonKeyAttributes = nonKeyAttributes
+	}
+
+	return p
+}
+
+func (t Throughput) provisioned() *types.ProvisionedThroughput {
+	return &types.ProvisionedThroughput{
+		ReadCapacityUnits:  aws.Int64(t.Read),
+		WriteCapacityUnits: aws.Int64(t.Write),
+	}
+}
+
+func keySchema(partition KeyAttribute, sort *KeyAttribute) ([]types.KeySchemaElement, []types.AttributeDefinition) {
+	schema := []types.KeySchemaElement{{
+		AttributeName: aws.String(partition.Name),
+		KeyType:       types.KeyTypeHash,
+	}}
+	definitions := []types.AttributeDefinition{partition.definition()}
+
+	if sort != nil && sort.Name != "" {
+		schema = append(schema, types.KeySchemaElement{
+			AttributeName: aws.String(sort.Name),
+			KeyType:       types.KeyTypeRange,
+		})
+		definitions = append(definitions, sort.definition())
+	}
+
+	return schema, definitions
+}
+
+// mergeDefinitions adds the attribute definitions that are not defined yet.
+func mergeDefinitions(definitions []types.AttributeDefinition, more ...types.AttributeDefinition) []types.AttributeDefinition {
+	for _, definition := range more {
+		defined := slices.ContainsFunc(definitions, func(d types.AttributeDefinition) bool {
+			return aws.ToString(d.AttributeName) == aws.ToString(definition.AttributeName)
+		})
+		if !defined {
+			definitions = append(definitions, definition)
+		}
+	}
+
+	return definitions
+}
+
+func (k KeyAttribute) definition() types.AttributeDefinition {
+	return types.AttributeDefinition{
+		AttributeName: aws.String(k.Name),
+		AttributeType: types.ScalarAttributeType(k.attributeType()),
+	}
+}
+
+func (k KeyAttribute) attributeType() AttributeType {
+	if k.Type == "" {
+		return AttributeString
+	}
+	return k.Type
+}
+
+func describeTable(table *types.TableDescription) *TableDescription {
+	if table == nil {
+		return nil
+	}
+
+	attributeTypes := make(map[string]AttributeType, len(table.AttributeDefinitions))
+	for _, definition := range table.AttributeDefinitions {
+		attributeTypes[aws.ToString(definition.AttributeName)] = AttributeType(definition.AttributeType)
+	}
+
+	description := &TableDescription{
+		Name:        aws.ToString(table.TableName),
+		ARN:         aws.ToString(table.TableArn),
+		Status:      string(table.TableStatus),
+		BillingMode: BillingProvisioned,
+		ItemCount:   aws.ToInt64(table.ItemCount),
+		SizeBytes:   aws.ToInt64(table.TableSizeBytes),
+		CreatedAt:   aws.ToTime(table.CreationDateTime),
+	}
+	if len(table.Replicas) > 0 {
+		description.Replicas = describeReplicas(table.Replicas)
+	}
+	description.PartitionKey, description.SortKey = describeKeys(table.KeySchema, attributeTypes)
+
+	if table.BillingModeSummary != nil && table.BillingModeSummary.BillingMode == types.BillingModePayPerRequest {
+		description.BillingMode = BillingPayPerRequest
+	}
+	if description.BillingMode == BillingProvisioned && table.ProvisionedThroughput != nil {
+		description.Throughput = &Throughput{
+			Read:  aws.ToInt64(table.ProvisionedThroughput.ReadCapacityUnits),
+			Write: aws.ToInt64(table.ProvisionedThroughput.WriteCapacityUnits),
+		}
+	}
+
+	for _, index := range table.GlobalSecondaryIndexes {
+		i := IndexDescription{
+			Name:       aws.ToString(index.IndexName),
+			Global:     true,
+			Status:     string(index.IndexStatus),
+			Projection: describeProjection(index.Projection),
+		}
+		i.PartitionKey, i.SortKey = describeKeys(index.KeySchema, attributeTypes)
+		description.Indexes = append(description.Indexes, i)
+	}
+	for _, index := range table.LocalSecondaryIndexes {
+		i := IndexDescription{
+			Name:       aws.ToString(index.IndexName),
+			Projection: describeProjection(index.Projection),
+		}
+		i.PartitionKey, i.SortKey = describeKeys(index.KeySchema, attributeTypes)
+		description.Indexes = append(description.Indexes, i)
+	}
+
+	return description
+}
+
+func describeProjection(p *types.Projection) ProjectionType {
+	if p == nil {
+		return ""
+	}
+	return ProjectionType(p.ProjectionType)
+}
+
+func describeKeys(schema []types.KeySchemaElement, attributeTypes map[string]AttributeType) (KeyAttribute, *KeyAttribute) {
+	var (
+		partition KeyAttribute
+		sort      *KeyAttribute
+	)
+
+	for _, element := range schema {
+		name := aws.ToString(element.AttributeName)
+		key := KeyAttribute{Name: name, Type: attributeTypes[name]}
+
+		if element.KeyType == types.KeyTypeHash {
+			partition = key
+		} else {
+			sort = &key
+		}
+	}
+
+	return partition, sort
+}
@@ -0,0 +1,22 @@
+package aws
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTruncate(t *testing.T) {
+	d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}})
+	putItems(t, d, "users", 60)
+
+	deleted, err := d.Truncate(context.Background(), "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 60 {
+		t.Errorf("deleted %d items, want 60", deleted)
+	}
+	if n := fake.count("users"); n != 0 {
+		t.Errorf("%d items left", n)
+	}
+}
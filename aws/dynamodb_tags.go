@@ -0,0 +1,183 @@
+package aws
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// keyTag marks the key attributes of a model, e.g., `ddb:"pk"`. A field can
+// have several roles separated by commas, e.g., `ddb:"sk,gsi1pk"`.
+const keyTag = "ddb"
+
+// SchemaOf derives the key schema of a table from the ddb tags of T:
+//
+//	pk, sk                partition and sort key of the table
+//	<index>pk, <index>sk  partition and sort key of a global index, e.g., gsi1pk
+//	lsi<n>sk              sort key of a local index, e.g., lsi1sk
+//
+// Attribute names come from the dynamodbav tag (or MarshalOptions.TagKey),
+// falling back to the field name, and their types from the field types.
+// Billing, throughput and projections are left to their defaults.
+func SchemaOf[T any](table string, opts ...MarshalOptions) (*TableSchema, error) {
+	var o MarshalOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	t := reflect.TypeFor[T]()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %s is not a struct", DynamoDBErrInvalidSchema, t)
+	}
+
+	schema := &TableSchema{Table: table}
+	globals := map[string]*GlobalIndex{}
+	var globalNames []string
+
+	err := keyFields(t, o, func(role string, key KeyAttribute) error {
+		switch {
+		case role == "pk":
+			schema.PartitionKey = key
+		case role == "sk":
+			schema.SortKey = &key
+		case strings.HasPrefix(role, "lsi") && strings.HasSuffix(role, "sk"):
+			schema.LocalIndexes = append(schema.LocalIndexes, LocalIndex{
+				Name:    strings.TrimSuffix(role, "sk"),
+				SortKey: key,
+			})
+		case strings.HasSuffix(role, "pk"), strings.HasSuffix(role, "sk"):
+			name := role[:len(role)-2]
+			index, ok := globals[name]
+			if !ok {
+				index = &GlobalIndex{Name: name}
+				globals[name] = index
+				globalNames = append(globalNames, name)
+			}
+
+			if strings.HasSuffix(role, "pk") {
+				index.PartitionKey = key
+			} else {
+				index.SortKey = &key
+			}
+		default:
+			return fmt.Errorf("%w: unknown key role %q", DynamoDBErrInvalidSchema, role)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range globalNames {
+		schema.GlobalIndexes = append(schema.GlobalIndexes, *globals[name])
+	}
+
+	if err := schema.Validate(); err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+// keyFields calls fn for each key role of the struct fields, including the
+// fields of embedded structs.
+func keyFields(t reflect.Type, opts MarshalOptions, fn func(role string, key KeyAttribute) error) error {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, skip := attributeName(field, opts)
+		if skip {
+			continue
+		}
+
+		// Embedded structs are flattened even when unexported
+		if field.Anonymous && field.Tag.Get(keyTag) == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if err := keyFields(embedded, opts, fn); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		tag := field.Tag.Get(keyTag)
+		if tag == "" || !field.IsExported() {
+			continue
+		}
+
+		attributeType, err := keyType(field.Type, opts)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", DynamoDBErrInvalidSchema, field.Name, err)
+		}
+
+		for role := range strings.SplitSeq(tag, ",") {
+			role = strings.ToLower(strings.TrimSpace(role))
+			if role == "" {
+				continue
+			}
+			if err := fn(role, KeyAttribute{Name: name, Type: attributeType}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// attributeName returns the name the field is stored under, and whether it is
+// skipped with a "-" tag.
+func attributeName(field reflect.StructField, opts MarshalOptions) (string, bool) {
+	tags := []string{field.Tag.Get("dynamodbav")}
+	if opts.TagKey != "" {
+		// The custom tag key wins, like in the encoder
+		tags = append(tags, field.Tag.Get(opts.TagKey))
+	}
+
+	name := field.Name
+	for _, tag := range tags {
+		tagName, _, _ := strings.Cut(tag, ",")
+		if tagName == "-" {
+			return "", true
+		}
+		if tagName != "" {
+			name = tagName
+		}
+	}
+	return name, false
+}
+
+// keyType returns the key attribute type the field is marshaled as.
+func keyType(t reflect.Type, opts MarshalOptions) (AttributeType, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == reflect.TypeFor[time.Time]() {
+		if opts.TimeFormat == TimeUnix || opts.TimeFormat == TimeUnixMilli {
+			return AttributeNumber, nil
+		}
+		return AttributeString, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return AttributeString, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return AttributeNumber, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return AttributeBinary, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s cannot be a key", t)
+}
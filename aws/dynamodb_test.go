@@ -0,0 +1,98 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+)
+
+func TestBuildSortKeyCondition(t *testing.T) {
+	d := &dynamodbService{}
+
+	tests := []struct {
+		sort    QueryKeyValue
+		want    string
+		wantErr bool
+	}{
+		{sort: QueryKeyValue{Key: "sk", Value: "a"}, want: "#0 = :0"},
+		{sort: QueryKeyValue{Key: "sk", Value: "a", Operator: Equal}, want: "#0 = :0"},
+		{sort: QueryKeyValue{Key: "sk", Value: 1, Operator: LessThan}, want: "#0 < :0"},
+		{sort: QueryKeyValue{Key: "sk", Value: 1, Operator: LessThanEqual}, want: "#0 <= :0"},
+		{sort: QueryKeyValue{Key: "sk", Value: 1, Operator: GreaterThan}, want: "#0 > :0"},
+		{sort: QueryKeyValue{Key: "sk", Value: 1, Operator: GreaterThanEqual}, want: "#0 >= :0"},
+		{sort: QueryKeyValue{Key: "sk", Value: 1, Value2: 5, Operator: Between}, want: "#0 BETWEEN :0 AND :1"},
+		{sort: QueryKeyValue{Key: "sk", Value: "ORDER#", Operator: BeginsWith}, want: "begins_with (#0, :0)"},
+		{sort: QueryKeyValue{Key: "sk", Value: 1, Operator: Between}, wantErr: true},
+		{sort: QueryKeyValue{Key: "sk", Value: 1, Operator: Contains}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.sort.Operator), func(t *testing.T) {
+			condition, err := d.buildSortKeyCondition(tt.sort)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expr, err := expression.NewBuilder().WithKeyCondition(condition).Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := *expr.KeyCondition(); got != tt.want {
+				t.Errorf("condition = %q, want %q", got, tt.want)
+			}
+			if got := expr.Names()["#0"]; got != "sk" {
+				t.Errorf("name = %q, want sk", got)
+			}
+		})
+	}
+}
+
+func TestBuildConditionInChunks(t *testing.T) {
+	d := &dynamodbService{}
+
+	tests := []struct {
+		values int
+		chunks int
+	}{
+		{values: 1, chunks: 1},
+		{values: inLimit, chunks: 1},
+		{values: inLimit + 1, chunks: 2},
+		{values: 2*inLimit + 50, chunks: 3},
+	}
+	for _, tt := range tests {
+		values := make([]any, tt.values)
+		for i := range values {
+			values[i] = i
+		}
+
+		condition, err := d.buildSingleCondition(WhereCondition{Field: "status", Operator: In, Values: values})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expr, err := expression.NewBuilder().WithCondition(condition).Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := *expr.Condition()
+		if n := strings.Count(got, " IN ("); n != tt.chunks {
+			t.Errorf("%d values: %d IN lists, want %d", tt.values, n, tt.chunks)
+		}
+		if n := strings.Count(got, " OR "); n != tt.chunks-1 {
+			t.Errorf("%d values: %d ORs, want %d", tt.values, n, tt.chunks-1)
+		}
+		if n := len(expr.Values()); n != tt.values {
+			t.Errorf("%d values: %d expression values", tt.values, n)
+		}
+	}
+
+	if _, err := d.buildSingleCondition(WhereCondition{Field: "status", Operator: In}); err == nil {
+		t.Error("want an error for an empty IN")
+	}
+}
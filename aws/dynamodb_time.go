@@ -0,0 +1,58 @@
+package aws
+
+import "time"
+
+// timeLayout is RFC 3339 with fixed nanoseconds. Formatted in UTC, strings
+// are the same length and sort in time order, which time.RFC3339Nano doesn't
+// as it drops trailing zeros, e.g., "...:00Z" sorts after "...:00.5Z".
+const timeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+// EncodeTime returns the time as MarshalItem stores it with the format, e.g.,
+// to build sort keys the time-range queries can match. TimeRFC3339 strings
+// are in UTC with fixed nanoseconds, so they sort in time order.
+func EncodeTime(t time.Time, format TimeFormat) any {
+	switch format {
+	case TimeUnix:
+		return t.Unix()
+	case TimeUnixMilli:
+		return t.UnixMilli()
+	default:
+		return formatTime(t)
+	}
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// encode converts time values, leaving any other value as is.
+func (f TimeFormat) encode(value any) any {
+	switch t := value.(type) {
+	case time.Time:
+		return EncodeTime(t, f)
+	case *time.Time:
+		if t == nil {
+			return value
+		}
+		return EncodeTime(*t, f)
+	default:
+		return value
+	}
+}
+
+// TimeBetween matches sort keys from one time to another, inclusive, e.g.,
+// QueryOptions{Sort: TimeBetween("created_at", start, end)}.
+func TimeBetween(key string, from, to time.Time) *QueryKeyValue {
+	return &QueryKeyValue{Key: key, Value: from, Value2: to, Operator: Between}
+}
+
+// TimeSince matches sort keys at or after the time.
+func TimeSince(key string, since time.Time) *QueryKeyValue {
+	return &QueryKeyValue{Key: key, Value: since, Operator: GreaterThanEqual}
+}
+
+// LastHours matches sort keys within the last hours, e.g., LastHours("ts", 24)
+// for the last day.
+func LastHours(key string, hours int) *QueryKeyValue {
+	return TimeSince(key, time.Now().Add(-time.Duration(hours)*time.Hour))
+}
@@ -0,0 +1,160 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestEncodeTime(t *testing.T) {
+	at := time.Date(2025, 1, 1, 12, 30, 0, 500_000_000, time.FixedZone("UTC+2", 2*60*60))
+
+	tests := []struct {
+		format TimeFormat
+		want   any
+	}{
+		{"", "2025-01-01T10:30:00.500000000Z"},
+		{TimeRFC3339, "2025-01-01T10:30:00.500000000Z"},
+		{TimeUnix, at.Unix()},
+		{TimeUnixMilli, at.UnixMilli()},
+	}
+	for _, tt := range tests {
+		if got := EncodeTime(at, tt.format); got != tt.want {
+			t.Errorf("EncodeTime(%q) = %v, want %v", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeTimeSortOrder(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	times := []time.Time{
+		base,
+		base.Add(time.Nanosecond),
+		base.Add(500 * time.Millisecond),
+		base.Add(time.Second),
+		base.Add(time.Second + time.Microsecond),
+		// Other offsets are converted to UTC
+		base.Add(2 * time.Second).In(time.FixedZone("UTC-5", -5*60*60)),
+		base.Add(time.Hour),
+	}
+
+	encoded := make([]string, len(times))
+	for i, at := range times {
+		encoded[i] = EncodeTime(at, TimeRFC3339).(string)
+	}
+	if !slices.IsSorted(encoded) {
+		t.Errorf("encoded times are not in time order: %q", encoded)
+	}
+}
+
+func TestMarshalItemTime(t *testing.T) {
+	at := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	item, err := MarshalItem(struct {
+		At time.Time `dynamodbav:"at"`
+	}{At: at})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, ok := item["at"].(*types.AttributeValueMemberS)
+	if !ok || s.Value != EncodeTime(at, TimeRFC3339) {
+		t.Errorf("at = %#v, want %q", item["at"], EncodeTime(at, TimeRFC3339))
+	}
+
+	decoded, err := UnmarshalItem[struct {
+		At time.Time `dynamodbav:"at"`
+	}](item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.At.Equal(at) {
+		t.Errorf("decoded %v, want %v", decoded.At, at)
+	}
+}
+
+func TestTimeKeyRoundTrip(t *testing.T) {
+	type event struct {
+		ID   string    `dynamodbav:"id"`
+		At   time.Time `dynamodbav:"at"`
+		Name string    `dynamodbav:"name"`
+	}
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60))
+
+	for _, format := range []TimeFormat{"", TimeRFC3339, TimeUnix, TimeUnixMilli} {
+		t.Run(string(format), func(t *testing.T) {
+			ctx := context.Background()
+			d, _ := newFakeDynamoDB(t, map[string][]string{"events": {"id", "at"}})
+			d.marshal = MarshalOptions{TimeFormat: format}
+
+			if _, err := d.Put(ctx, "events", event{ID: "1", At: at, Name: "created"}); err != nil {
+				t.Fatal(err)
+			}
+
+			key := Key{"id": "1", "at": at}
+			result, err := d.Get(ctx, GetOptions{Table: "events", Key: key})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := UnmarshalItem[event](result.Item, d.marshal)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Name != "created" || !got.At.Equal(at) {
+				t.Errorf("Get() = %+v", got)
+			}
+
+			if _, err := d.Delete(ctx, DeleteOptions{Table: "events", Key: key}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := d.Get(ctx, GetOptions{Table: "events", Key: key}); !errors.Is(err, DynamoDBErrItemNotFound) {
+				t.Errorf("Get() after Delete error = %v, want DynamoDBErrItemNotFound", err)
+			}
+		})
+	}
+}
+
+func TestTimeValuesMatchStoredTimes(t *testing.T) {
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, format := range []TimeFormat{TimeRFC3339, TimeUnix, TimeUnixMilli} {
+		d := &dynamodbService{marshal: MarshalOptions{TimeFormat: format}}
+
+		item, err := MarshalItem(map[string]any{"at": at}, d.marshal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stored := item["at"]
+
+		condition, err := d.buildSingleCondition(WhereCondition{Field: "at", Operator: GreaterThan, Value: at})
+		if err != nil {
+			t.Fatal(err)
+		}
+		update, err := d.buildUpdateExpression(*NewUpdate().Set("at", at))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expr, err := expression.NewBuilder().WithCondition(condition).WithUpdate(update).Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, value := range expr.Values() {
+			if !attributeEqual(value, stored) {
+				t.Errorf("%s: %s = %#v, want %#v", format, name, value, stored)
+			}
+		}
+
+		params, err := statementParameters([]any{at}, d.marshal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !attributeEqual(params[0], stored) {
+			t.Errorf("%s: parameter = %#v, want %#v", format, params[0], stored)
+		}
+	}
+}
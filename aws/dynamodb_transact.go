@@ -0,0 +1,336 @@
+package aws
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var transactLimit = 100 // Max items per transaction
+
+const transactTokenLength = 36 // Max length of a client request token
+
+type (
+	TransactGetItem struct {
+		Table  string
+		Key    Key
+		Fields []string // Attributes to return, all when empty
+	}
+
+	TransactGetOptions struct {
+		Items []TransactGetItem // Up to 100 items, across any tables
+		// Report the capacity used in the result
+		ReturnConsumedCapacity bool
+	}
+
+	TransactGetResult struct {
+		Items            []Item // Same order as the requested items, nil when missing
+		ConsumedCapacity *ConsumedCapacity
+	}
+
+	// TransactWriteItem is a single action of a transaction: a Put, an
+	// Update or a Delete of the item with Key, or a condition check when only
+	// Key and Condition are set.
+	TransactWriteItem struct {
+		Table     string
+		Put       any     // Struct, map or attribute value map to write
+		Update    *Update // Applied to the item with Key
+		Delete    bool    // Deletes the item with Key
+		Key       Key     // Item to update, delete or check
+		Condition *Where  // Optional for writes, required for condition checks
+	}
+
+	TransactWriteOptions struct {
+		Items []TransactWriteItem // Up to 100 items, across any tables
+		// Optional idempotency token. Retrying with the same token within 10
+		// minutes succeeds without applying the writes again. See
+		// TransactionToken to derive one from the items.
+		ClientRequestToken string
+		// Report the capacity used in the result
+		ReturnConsumedCapacity bool
+	}
+
+	TransactWriteResult struct {
+		ConsumedCapacity *ConsumedCapacity
+	}
+)
+
+var (
+	DynamoDBErrTransactGet   = errors.New("failed to transact get items")
+	DynamoDBErrTransactItem  = errors.New("invalid transaction item")
+	DynamoDBErrTransactLimit = errors.New("too many items in transaction")
+	DynamoDBErrTransactToken = errors.New("client request token reused with different items")
+	DynamoDBErrTransactWrite = errors.New("failed to transact write items")
+)
+
+// TransactGet reads the items atomically. The results are in the same order
+// as the requested items, with nil for items that do not exist.
+func (d *dynamodbService) TransactGet(ctx context.Context, opts TransactGetOptions) (*TransactGetResult, error) {
+	if len(opts.Items) == 0 {
+		return &TransactGetResult{}, nil
+	}
+	if len(opts.Items) > transactLimit {
+		return nil, DynamoDBErrTransactLimit
+	}
+
+	gets := make([]types.TransactGetItem, len(opts.Items))
+	for i, item := range opts.Items {
+		if item.Table == "" {
+			return nil, DynamoDBErrTableNotSet
+		}
+
+		key, err := item.Key.marshal(d.marshal)
+		if err != nil {
+			return nil, err
+		}
+
+		get := &types.Get{
+			TableName: aws.String(item.Table),
+			Key:       key,
+		}
+
+		if len(item.Fields) > 0 {
+			expr, err := expression.NewBuilder().WithProjection(buildProjection(item.Fields)).Build()
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", DynamoDBErrBuildProjection, err)
+			}
+
+			get.ProjectionExpression = expr.Projection()
+			get.ExpressionAttributeNames = expr.Names()
+		}
+
+		gets[i] = types.TransactGetItem{Get: get}
+	}
+
+	response, err := d.client.TransactGetItems(ctx, &dynamodb.TransactGetItemsInput{
+		TransactItems:          gets,
+		ReturnConsumedCapacity: capacityMode(opts.ReturnConsumedCapacity),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrTransactGet, err)
+	}
+
+	result := &TransactGetResult{
+		Items:            make([]Item, len(opts.Items)),
+		ConsumedCapacity: addCapacity(nil, response.ConsumedCapacity...),
+	}
+	for i, got := range response.Responses {
+		result.Items[i] = got.Item
+		if err := d.loadItems(ctx, opts.Items[i].Table, got.Item); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// TransactWrite applies the writes atomically: either all of them succeed or
+// none do. When the transaction is canceled, e.g., by a failed condition, a
+// *TransactionCanceledError tells which items caused it.
+func (d *dynamodbService) TransactWrite(ctx context.Context, opts TransactWriteOptions) (*TransactWriteResult, error) {
+	if len(opts.Items) == 0 {
+		return &TransactWriteResult{}, nil
+	}
+	if len(opts.Items) > transactLimit {
+		return nil, DynamoDBErrTransactLimit
+	}
+
+	tables := make([]string, len(opts.Items))
+	for i, item := range opts.Items {
+		tables[i] = item.Table
+	}
+	defer d.invalidate(tables...)
+
+	writes := make([]types.TransactWriteItem, len(opts.Items))
+	for i, item := range opts.Items {
+		write, err := d.transactWriteItem(ctx, item)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		writes[i] = write
+	}
+
+	input := &dynamodb.TransactWriteItemsInput{
+		TransactItems:          writes,
+		ReturnConsumedCapacity: capacityMode(opts.ReturnConsumedCapacity),
+	}
+	if opts.ClientRequestToken != "" {
+		input.ClientRequestToken = aws.String(opts.ClientRequestToken)
+	}
+
+	response, err := d.client.TransactWriteItems(ctx, input)
+	if err != nil {
+		var (
+			canceled *types.TransactionCanceledException
+			mismatch *types.IdempotentParameterMismatchException
+		)
+		switch {
+		case errors.As(err, &canceled):
+			return nil, transactionCanceledError(DynamoDBErrTransactWrite, canceled)
+		case errors.As(err, &mismatch):
+			return nil, fmt.Errorf("%w: %w: %w", DynamoDBErrTransactWrite, DynamoDBErrTransactToken, err)
+		}
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrTransactWrite, err)
+	}
+
+	return &TransactWriteResult{
+		ConsumedCapacity: addCapacity(nil, response.ConsumedCapacity...),
+	}, nil
+}
+
+func (d *dynamodbService) transactWriteItem(ctx context.Context, item TransactWriteItem) (types.TransactWriteItem, error) {
+	if item.Table == "" {
+		return types.TransactWriteItem{}, DynamoDBErrTableNotSet
+	}
+
+	actions := 0
+	for _, set := range []bool{item.Put != nil, item.Update != nil, item.Delete} {
+		if set {
+			actions++
+		}
+	}
+	if actions > 1 || (actions == 0 && item.Condition == nil) {
+		return types.TransactWriteItem{}, fmt.Errorf("%w: set one of Put, Update, Delete or Condition", DynamoDBErrTransactItem)
+	}
+
+	if item.Put != nil {
+		av, err := MarshalItem(item.Put, d.marshal)
+		if err != nil {
+			return types.TransactWriteItem{}, err
+		}
+		av, err = d.storeItem(ctx, item.Table, av)
+		if err != nil {
+			return types.TransactWriteItem{}, err
+		}
+
+		put := &types.Put{TableName: aws.String(item.Table), Item: av}
+		if item.Condition != nil {
+			expr, err := d.buildConditionExpression(*item.Condition)
+			if err != nil {
+				return types.TransactWriteItem{}, err
+			}
+			put.ConditionExpression = expr.Condition()
+			put.ExpressionAttributeNames = expr.Names()
+			put.ExpressionAttributeValues = expr.Values()
+		}
+		return types.TransactWriteItem{Put: put}, nil
+	}
+
+	key, err := item.Key.marshal(d.marshal)
+	if err != nil {
+		return types.TransactWriteItem{}, err
+	}
+
+	if item.Update != nil {
+		update, err := d.buildUpdateExpression(*item.Update)
+		if err != nil {
+			return types.TransactWriteItem{}, fmt.Errorf("%w: %w", DynamoDBErrBuildUpdateExpression, err)
+		}
+
+		builder := expression.NewBuilder().WithUpdate(update)
+		if item.Condition != nil {
+			cond, err := d.buildFilterExpression(*item.Condition)
+			if err != nil {
+				return types.TransactWriteItem{}, fmt.Errorf("%w: %w", DynamoDBErrBuildConditionExpression, err)
+			}
+			builder = builder.WithCondition(cond)
+		}
+
+		expr, err := builder.Build()
+		if err != nil {
+			return types.TransactWriteItem{}, fmt.Errorf("%w: %w", DynamoDBErrBuildUpdateExpression, err)
+		}
+
+		return types.TransactWriteItem{Update: &types.Update{
+			TableName:                 aws.String(item.Table),
+			Key:                       key,
+			UpdateExpression:          expr.Update(),
+			ConditionExpression:       expr.Condition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+		}}, nil
+	}
+
+	var expr expression.Expression
+	if item.Condition != nil {
+		expr, err = d.buildConditionExpression(*item.Condition)
+		if err != nil {
+			return types.TransactWriteItem{}, err
+		}
+	}
+
+	if item.Delete {
+		return types.TransactWriteItem{Delete: &types.Delete{
+			TableName:                 aws.String(item.Table),
+			Key:                       key,
+			ConditionExpression:       expr.Condition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+		}}, nil
+	}
+
+	return types.TransactWriteItem{ConditionCheck: &types.ConditionCheck{
+		TableName:                 aws.String(item.Table),
+		Key:                       key,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}}, nil
+}
+
+// TransactionToken derives a client request token from the items, so a
+// transaction retried with the same items gets the same token and is not
+// applied twice, e.g., after a timeout. Transactions that should apply the
+// same writes more than once must use their own tokens instead.
+func TransactionToken(items ...TransactWriteItem) (string, error) {
+	type canonical struct {
+		Table     string
+		Put       map[string]any `json:",omitempty"`
+		Update    []UpdateAction `json:",omitempty"`
+		Delete    bool           `json:",omitempty"`
+		Key       map[string]any `json:",omitempty"`
+		Condition *Where         `json:",omitempty"`
+	}
+
+	entries := make([]canonical, len(items))
+	for i, item := range items {
+		entries[i] = canonical{Table: item.Table, Delete: item.Delete, Condition: item.Condition}
+
+		// Items are compared by their attribute values, so a struct and the
+		// equivalent map get the same token
+		if item.Put != nil {
+			av, err := MarshalItem(item.Put)
+			if err != nil {
+				return "", err
+			}
+			entries[i].Put = encodeAttributeValue(&types.AttributeValueMemberM{Value: av})
+		}
+		if item.Key != nil {
+			key, err := item.Key.marshal(MarshalOptions{})
+			if err != nil {
+				return "", err
+			}
+			entries[i].Key = encodeAttributeValue(&types.AttributeValueMemberM{Value: key})
+		}
+		if item.Update != nil {
+			entries[i].Update = item.Update.Actions
+		}
+	}
+
+	// Maps are encoded with sorted keys, so the encoding is stable
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", DynamoDBErrMarshal, err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:transactTokenLength], nil
+}
@@ -0,0 +1,166 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestTransactGet(t *testing.T) {
+	ctx := context.Background()
+	d, _ := newFakeDynamoDB(t, map[string][]string{"users": {"id"}, "orders": {"user", "id"}})
+
+	if _, err := d.Put(ctx, "users", map[string]any{"id": "1", "name": "Ada", "email": "ada@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Put(ctx, "orders", map[string]any{"user": "1", "id": "o1", "total": 10}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := d.TransactGet(ctx, TransactGetOptions{Items: []TransactGetItem{
+		{Table: "users", Key: Key{"id": "1"}, Fields: []string{"name"}},
+		{Table: "users", Key: Key{"id": "2"}},
+		{Table: "orders", Key: Key{"user": "1", "id": "o1"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Items) != 3 {
+		t.Fatalf("TransactGet() returned %d items, want 3", len(result.Items))
+	}
+	if name, ok := result.Items[0]["name"].(*types.AttributeValueMemberS); !ok || name.Value != "Ada" || len(result.Items[0]) != 1 {
+		t.Errorf("TransactGet() item 0 = %v, want only the name", result.Items[0])
+	}
+	if result.Items[1] != nil {
+		t.Errorf("TransactGet() item 1 = %v, want nil for the missing item", result.Items[1])
+	}
+	if total, ok := result.Items[2]["total"].(*types.AttributeValueMemberN); !ok || total.Value != "10" {
+		t.Errorf("TransactGet() item 2 = %v, want the order", result.Items[2])
+	}
+}
+
+func TestTransactGetLimit(t *testing.T) {
+	d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}})
+
+	items := make([]TransactGetItem, transactLimit+1)
+	for i := range items {
+		items[i] = TransactGetItem{Table: "users", Key: Key{"id": i}}
+	}
+
+	if _, err := d.TransactGet(context.Background(), TransactGetOptions{Items: items}); !errors.Is(err, DynamoDBErrTransactLimit) {
+		t.Errorf("TransactGet() error = %v, want DynamoDBErrTransactLimit", err)
+	}
+	if fake.calls["TransactGetItems"] != 0 {
+		t.Error("TransactGet() sent the transaction")
+	}
+}
+
+func TestTransactWrite(t *testing.T) {
+	ctx := context.Background()
+	d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}, "orders": {"user", "id"}})
+	putItems(t, d, "users", 2)
+
+	_, err := d.TransactWrite(ctx, TransactWriteOptions{Items: []TransactWriteItem{
+		{Table: "orders", Put: map[string]any{"user": "1", "id": "o1"}},
+		{Table: "users", Key: Key{"id": "0"}, Delete: true},
+		{Table: "users", Key: Key{"id": "1"}, Condition: &Where{Conditions: []WhereCondition{Exists("id")}}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fake.count("orders") != 1 || fake.count("users") != 1 {
+		t.Errorf("TransactWrite() left %d orders and %d users, want 1 each", fake.count("orders"), fake.count("users"))
+	}
+}
+
+func TestTransactWriteIdempotent(t *testing.T) {
+	ctx := context.Background()
+	d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}})
+
+	items := []TransactWriteItem{{Table: "users", Put: map[string]any{"id": "1", "name": "Ada"}}}
+	token, err := TransactionToken(items...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.TransactWrite(ctx, TransactWriteOptions{Items: items, ClientRequestToken: token}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A retry with the same token is not applied again
+	if _, err := d.Delete(ctx, DeleteOptions{Table: "users", Key: Key{"id": "1"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.TransactWrite(ctx, TransactWriteOptions{Items: items, ClientRequestToken: token}); err != nil {
+		t.Fatalf("TransactWrite() retry error = %v", err)
+	}
+	if fake.count("users") != 0 {
+		t.Error("TransactWrite() retry applied the writes again")
+	}
+
+	// The same token with other items is rejected
+	other := []TransactWriteItem{{Table: "users", Put: map[string]any{"id": "2"}}}
+	_, err = d.TransactWrite(ctx, TransactWriteOptions{Items: other, ClientRequestToken: token})
+	if !errors.Is(err, DynamoDBErrTransactToken) || !errors.Is(err, DynamoDBErrTransactWrite) {
+		t.Errorf("TransactWrite() error = %v, want DynamoDBErrTransactToken", err)
+	}
+}
+
+func TestTransactWriteCanceled(t *testing.T) {
+	d, fake := newFakeDynamoDB(t, map[string][]string{"users": {"id"}})
+	fake.before = func(operation string, _ map[string]any) (any, error) {
+		if operation == "TransactWriteItems" {
+			return nil, fakeCanceled{"None", "ConditionalCheckFailed"}
+		}
+		return nil, nil
+	}
+
+	_, err := d.TransactWrite(context.Background(), TransactWriteOptions{Items: []TransactWriteItem{
+		{Table: "users", Put: map[string]any{"id": "1"}},
+		{Table: "users", Key: Key{"id": "2"}, Delete: true, Condition: &Where{Conditions: []WhereCondition{Exists("id")}}},
+	}})
+
+	var canceled *TransactionCanceledError
+	if !errors.As(err, &canceled) || !errors.Is(err, DynamoDBErrTransactWrite) {
+		t.Fatalf("TransactWrite() error = %v, want a *TransactionCanceledError", err)
+	}
+	if canceled.Reasons[0] != nil {
+		t.Errorf("reason 0 = %v, want nil for the item that did not fail", canceled.Reasons[0])
+	}
+	if reason := canceled.Reasons[1]; reason == nil || reason.Code != "ConditionalCheckFailed" || reason.Index != 1 {
+		t.Errorf("reason 1 = %+v, want the failed condition", reason)
+	}
+}
+
+func TestTransactionToken(t *testing.T) {
+	type user struct {
+		ID   string `dynamodbav:"id"`
+		Name string `dynamodbav:"name"`
+	}
+
+	token := func(items ...TransactWriteItem) string {
+		t.Helper()
+		token, err := TransactionToken(items...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	fromMap := token(TransactWriteItem{Table: "users", Put: map[string]any{"id": "1", "name": "Ada"}})
+	fromStruct := token(TransactWriteItem{Table: "users", Put: user{ID: "1", Name: "Ada"}})
+	other := token(TransactWriteItem{Table: "users", Put: user{ID: "1", Name: "Grace"}})
+
+	if len(fromMap) != transactTokenLength {
+		t.Errorf("token length = %d, want %d", len(fromMap), transactTokenLength)
+	}
+	if fromMap != fromStruct {
+		t.Errorf("tokens of a map and the equivalent struct = %s, %s, want equal", fromMap, fromStruct)
+	}
+	if fromMap == other {
+		t.Error("tokens of different items are equal")
+	}
+}
@@ -0,0 +1,74 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var (
+	DynamoDBErrAttributeNotSet = errors.New("attribute not set")
+	DynamoDBErrUpdateTTL       = errors.New("failed to update time to live")
+)
+
+// EnableTTL turns on time to live for the table using the attribute, which
+// must hold the expiry as epoch seconds.
+func (d *dynamodbService) EnableTTL(ctx context.Context, table, attribute string) error {
+	return d.updateTTL(ctx, table, attribute, true)
+}
+
+// DisableTTL turns off time to live for the table.
+func (d *dynamodbService) DisableTTL(ctx context.Context, table, attribute string) error {
+	return d.updateTTL(ctx, table, attribute, false)
+}
+
+func (d *dynamodbService) updateTTL(ctx context.Context, table, attribute string, enabled bool) error {
+	if table == "" {
+		return DynamoDBErrTableNotSet
+	}
+	if attribute == "" {
+		return DynamoDBErrAttributeNotSet
+	}
+
+	_, err := d.client.UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String(table),
+		TimeToLiveSpecification: &types.TimeToLiveSpecification{
+			AttributeName: aws.String(attribute),
+			Enabled:       aws.Bool(enabled),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", DynamoDBErrUpdateTTL, err)
+	}
+
+	return nil
+}
+
+// ExpiresAt returns the TTL value for the time, for use in struct fields.
+func ExpiresAt(t time.Time) int64 {
+	return t.Unix()
+}
+
+// ExpiresIn returns the TTL value for the duration from now.
+func ExpiresIn(d time.Duration) int64 {
+	return ExpiresAt(time.Now().Add(d))
+}
+
+// WithExpiry returns a copy of the item with the TTL attribute set to the
+// time.
+func WithExpiry(item Item, attribute string, t time.Time) Item {
+	expiring := maps.Clone(item)
+	if expiring == nil {
+		expiring = Item{}
+	}
+	expiring[attribute] = &types.AttributeValueMemberN{Value: strconv.FormatInt(ExpiresAt(t), 10)}
+
+	return expiring
+}
@@ -0,0 +1,33 @@
+package aws
+
+import (
+	"context"
+	"iter"
+)
+
+// QueryAs runs the query and unmarshals the items into T, e.g.,
+// QueryAs[Movie](ctx, ddb, opts).
+func QueryAs[T any](ctx context.Context, ddb DynamoDB, opts QueryOptions) ([]T, error) {
+	result, err := ddb.Query(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return UnmarshalItems[T](result.Items)
+}
+
+// QueryItemsAs iterates over the query items unmarshaled into T, reading one
+// page at a time.
+func QueryItemsAs[T any](ctx context.Context, ddb DynamoDB, opts QueryOptions) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for item, err := range ddb.QueryItems(ctx, opts) {
+			var out T
+			if err == nil {
+				out, err = UnmarshalItem[T](item)
+			}
+			if !yield(out, err) || err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	UpdateSet    UpdateActionType = "SET"
+	UpdateRemove UpdateActionType = "REMOVE"
+	UpdateAdd    UpdateActionType = "ADD"
+	UpdateDelete UpdateActionType = "DELETE"
+
+	UpdateAppend         UpdateActionType = "LIST_APPEND"
+	UpdatePrepend        UpdateActionType = "LIST_PREPEND"
+	UpdateSetIfNotExists UpdateActionType = "SET_IF_NOT_EXISTS"
+)
+
+type (
+	UpdateActionType string
+
+	UpdateAction struct {
+		Type  UpdateActionType
+		Field string // Attribute path, e.g., "meta.tags" or "items[0]"
+		Value any    // Unused for REMOVE
+	}
+
+	// Update collects the actions applied to an item, e.g.,
+	// NewUpdate().Set("status", "done").Remove("lock")
+	Update struct {
+		Actions []UpdateAction
+	}
+
+	UpdateOptions struct {
+		Table        string
+		Key          Key
+		Update       *Update
+		Condition    *Where      // Optional condition that must hold for the update to succeed
+		ReturnValues ReturnValue // e.g., ReturnAllNew to get the updated item back
+		// Enables optimistic locking on this number attribute. The update only
+		// succeeds if the stored version equals Version, and the version is
+		// incremented. Mismatches return DynamoDBErrVersionConflict.
+		VersionField string
+		Version      int64 // Expected current version, zero when the item is new
+		// Report the capacity used in the result
+		ReturnConsumedCapacity bool
+	}
+
+	UpdateResult struct {
+		Attributes       Item  // Populated based on UpdateOptions.ReturnValues, see Unmarshal
+		Version          int64 // New version when VersionField is set
+		ConsumedCapacity *ConsumedCapacity
+	}
+)
+
+func NewUpdate() *Update {
+	return &Update{}
+}
+
+// Set replaces the value of the field.
+func (u *Update) Set(field string, value any) *Update {
+	u.Actions = append(u.Actions, UpdateAction{Type: UpdateSet, Field: field, Value: value})
+	return u
+}
+
+// Remove deletes the fields from the item.
+func (u *Update) Remove(fields ...string) *Update {
+	for _, field := range fields {
+		u.Actions = append(u.Actions, UpdateAction{Type: UpdateRemove, Field: field})
+	}
+	return u
+}
+
+// Add increments a number field or adds elements to a set field. Slices are
+// sent as sets.
+func (u *Update) Add(field string, value any) *Update {
+	u.Actions = append(u.Actions, UpdateAction{Type: UpdateAdd, Field: field, Value: value})
+	return u
+}
+
+// Delete removes elements from a set field. Slices are sent as sets.
+func (u *Update) Delete(field string, value any) *Update {
+	u.Actions = append(u.Actions, UpdateAction{Type: UpdateDelete, Field: field, Value: value})
+	return u
+}
+
+// Append adds the values to the end of a list field, creating the list if
+// needed. Values must be a slice.
+func (u *Update) Append(field string, values any) *Update {
+	u.Actions = append(u.Actions, UpdateAction{Type: UpdateAppend, Field: field, Value: values})
+	return u
+}
+
+// Prepend adds the values to the start of a list field, creating the list if
+// needed. Values must be a slice.
+func (u *Update) Prepend(field string, values any) *Update {
+	u.Actions = append(u.Actions, UpdateAction{Type: UpdatePrepend, Field: field, Value: values})
+	return u
+}
+
+// SetIfNotExists sets the field only when it does not exist yet, e.g., for a
+// created_at timestamp.
+func (u *Update) SetIfNotExists(field string, value any) *Update {
+	u.Actions = append(u.Actions, UpdateAction{Type: UpdateSetIfNotExists, Field: field, Value: value})
+	return u
+}
+
+// Update modifies the attributes of a single item, creating it if it does not
+// exist yet unless a condition prevents it.
+func (d *dynamodbService) Update(ctx context.Context, opts UpdateOptions) (*UpdateResult, error) {
+	if opts.Table == "" {
+		return nil, DynamoDBErrTableNotSet
+	}
+	err := checkReturnValues(opts.ReturnValues, ReturnNone, ReturnAllOld, ReturnUpdatedOld, ReturnAllNew, ReturnUpdatedNew)
+	if err != nil {
+		return nil, err
+	}
+	defer d.invalidate(opts.Table)
+
+	key, err := opts.Key.marshal(d.marshal)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Update == nil {
+		return nil, DynamoDBErrBuildUpdateExpression
+	}
+
+	actions := *opts.Update
+	if opts.VersionField != "" {
+		// Copy the actions so the caller's update is left untouched
+		actions.Actions = append(slices.Clone(actions.Actions), UpdateAction{
+			Type:  UpdateSet,
+			Field: opts.VersionField,
+			Value: opts.Version + 1,
+		})
+		opts.Condition = versionCondition(opts.Condition, opts.VersionField, opts.Version)
+	}
+
+	update, err := d.buildUpdateExpression(actions)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrBuildUpdateExpression, err)
+	}
+
+	builder := expression.NewBuilder().WithUpdate(update)
+
+	if opts.Condition != nil {
+		cond, err := d.buildFilterExpression(*opts.Condition)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", DynamoDBErrBuildConditionExpression, err)
+		}
+		builder = builder.WithCondition(cond)
+	}
+
+	expr, err := builder.Build()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", DynamoDBErrBuildUpdateExpression, err)
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(opts.Table),
+		Key:                       key,
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ReturnConsumedCapacity:    capacityMode(opts.ReturnConsumedCapacity),
+	}
+
+	if opts.ReturnValues != "" {
+		input.ReturnValues = types.ReturnValue(opts.ReturnValues)
+	}
+
+	before, err := d.beforeWrite(ctx, opts.Table, key)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := d.client.UpdateItem(ctx, input)
+	if err != nil {
+		err = writeError(DynamoDBErrUpdateItem, err)
+		if opts.VersionField != "" {
+			err = versionError(err)
+		}
+		return nil, err
+	}
+	if err := d.loadItems(ctx, opts.Table, response.Attributes); err != nil {
+		return nil, err
+	}
+
+	result := &UpdateResult{
+		Attributes:       response.Attributes,
+		ConsumedCapacity: addCapacity(nil, consumed(response.ConsumedCapacity)...),
+	}
+	if opts.VersionField != "" {
+		result.Version = opts.Version + 1
+	}
+	if err := d.afterWrite(ctx, AuditUpdate, opts.Table, key, before, nil); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// Unmarshal converts the returned attributes into out, a pointer to a struct
+// or map. With ReturnUpdatedOld or ReturnUpdatedNew only the updated fields
+// are set. DynamoDBErrItemNotFound is returned when nothing was returned.
+func (r *UpdateResult) Unmarshal(out any, opts ...MarshalOptions) error {
+	return unmarshalAttributes(r.Attributes, out, opts...)
+}
+
+// Increment atomically adds delta to a top-level number field, starting from
+// zero when the field or item does not exist, and returns the new value.
+func (d *dynamodbService) Increment(ctx context.Context, table string, key Key, field string, delta int64) (int64, error) {
+	result, err := d.Update(ctx, UpdateOptions{
+		Table:        table,
+		Key:          key,
+		Update:       NewUpdate().Add(field, delta),
+		ReturnValues: ReturnUpdatedNew,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var value int64
+	if err := attributevalue.Unmarshal(result.Attributes[field], &value); err != nil {
+		return 0, fmt.Errorf("%w: %w", DynamoDBErrUnmarshal, err)
+	}
+
+	return value, nil
+}
+
+// RemoveAttributes deletes the fields from an existing item. Fields can be
+// nested paths, e.g., "meta.flags[2]". DynamoDBErrItemNotFound is returned
+// when the item does not exist instead of creating it.
+func (d *dynamodbService) RemoveAttributes(ctx context.Context, table string, key Key, fields ...string) error {
+	if len(fields) == 0 {
+		return DynamoDBErrBuildUpdateExpression
+	}
+
+	var exists []WhereCondition
+	for name := range key {
+		exists = append(exists, WhereCondition{Field: name, Operator: AttributeExists})
+	}
+
+	_, err := d.Update(ctx, UpdateOptions{
+		Table:     table,
+		Key:       key,
+		Update:    NewUpdate().Remove(fields...),
+		Condition: &Where{Conditions: exists},
+	})
+	if errors.Is(err, DynamoDBErrConditionFailed) {
+		return DynamoDBErrItemNotFound
+	}
+
+	return err
+}
+
+func (d *dynamodbService) buildUpdateExpression(update Update) (expression.UpdateBuilder, error) {
+	if len(update.Actions) == 0 {
+		return expression.UpdateBuilder{}, errors.New("no update actions provided")
+	}
+
+	var builder expression.UpdateBuilder
+	for _, action := range update.Actions {
+		if action.Field == "" {
+			return expression.UpdateBuilder{}, errors.New("update action requires a field")
+		}
+
+		name := expression.Name(action.Field)
+
+		switch action.Type {
+		case UpdateSet:
+			builder = builder.Set(name, d.value(action.Value))
+		case UpdateRemove:
+			builder = builder.Remove(name)
+		case UpdateAdd:
+			builder = builder.Add(name, d.value(setValue(action.Value)))
+		case UpdateDelete:
+			builder = builder.Delete(name, d.value(setValue(action.Value)))
+		case UpdateAppend:
+			builder = builder.Set(name, expression.ListAppend(name.IfNotExists(emptyList()), d.value(action.Value)))
+		case UpdatePrepend:
+			builder = builder.Set(name, expression.ListAppend(d.value(action.Value), name.IfNotExists(emptyList())))
+		case UpdateSetIfNotExists:
+			builder = builder.Set(name, name.IfNotExists(d.value(action.Value)))
+		default:
+			return expression.UpdateBuilder{}, fmt.Errorf("unsupported update action: %s", action.Type)
+		}
+	}
+
+	return builder, nil
+}
+
+func emptyList() expression.ValueBuilder {
+	return expression.Value(&types.AttributeValueMemberL{Value: []types.AttributeValue{}})
+}
+
+// setValue converts slices into their DynamoDB set representation since
+// ADD and DELETE only operate on sets, with a single []byte being a set of
+// one binary value. Other values are returned as is.
+func setValue(value any) any {
+	switch v := value.(type) {
+	case []string:
+		return &types.AttributeValueMemberSS{Value: v}
+	case []byte:
+		return &types.AttributeValueMemberBS{Value: [][]byte{v}}
+	case [][]byte:
+		return &types.AttributeValueMemberBS{Value: v}
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice {
+		return value
+	}
+
+	switch rv.Type().Elem().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		numbers := make([]string, rv.Len())
+		for i := range numbers {
+			numbers[i] = fmt.Sprint(rv.Index(i).Interface())
+		}
+		return &types.AttributeValueMemberNS{Value: numbers}
+	}
+
+	return value
+}
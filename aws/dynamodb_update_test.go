@@ -0,0 +1,32 @@
+package aws
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestSetValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  any
+	}{
+		{"strings", []string{"a", "b"}, &types.AttributeValueMemberSS{Value: []string{"a", "b"}}},
+		{"bytes", []byte{1, 2}, &types.AttributeValueMemberBS{Value: [][]byte{{1, 2}}}},
+		{"byte slices", [][]byte{{1}, {2}}, &types.AttributeValueMemberBS{Value: [][]byte{{1}, {2}}}},
+		{"ints", []int{1, 2}, &types.AttributeValueMemberNS{Value: []string{"1", "2"}}},
+		{"floats", []float64{1.5}, &types.AttributeValueMemberNS{Value: []string{"1.5"}}},
+		{"number", 5, 5},
+		{"string", "a", "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := setValue(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("setValue(%v) = %#v, want %#v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var DynamoDBErrVersionConflict = errors.New("version conflict")
+
+// versionCondition adds the optimistic locking check to the condition. A zero
+// version means the item must not exist yet.
+func versionCondition(condition *Where, field string, version int64) *Where {
+	check := WhereCondition{Field: field, Operator: AttributeNotExists}
+	if version > 0 {
+		check = WhereCondition{Field: field, Operator: Equal, Value: version}
+	}
+
+	where := &Where{Conditions: []WhereCondition{check}}
+	if condition != nil {
+		where.Groups = []Where{*condition}
+	}
+
+	return where
+}
+
+// nextVersion reads the current version from the item and returns a copy of
+// the item holding the incremented version.
+func nextVersion(item Item, field string) (Item, int64, error) {
+	var version int64
+
+	if av, ok := item[field]; ok {
+		n, ok := av.(*types.AttributeValueMemberN)
+		if !ok {
+			return nil, 0, fmt.Errorf("%w: version field %s is not a number", DynamoDBErrMarshal, field)
+		}
+
+		v, err := strconv.ParseInt(n.Value, 10, 64)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: %w", DynamoDBErrMarshal, err)
+		}
+		version = v
+	}
+
+	next := maps.Clone(item)
+	next[field] = &types.AttributeValueMemberN{Value: strconv.FormatInt(version+1, 10)}
+
+	return next, version, nil
+}
+
+// versionError reports failed conditions as version conflicts.
+func versionError(err error) error {
+	if errors.Is(err, DynamoDBErrConditionFailed) {
+		return fmt.Errorf("%w: %w", DynamoDBErrVersionConflict, err)
+	}
+	return err
+}
@@ -0,0 +1,138 @@
+package aws
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go/middleware"
+)
+
+type (
+	// RequestMetrics describe a single API request. Operations reading pages,
+	// like Query and Scan, send a request per page.
+	RequestMetrics struct {
+		Operation string // API operation, e.g., "Query" or "BatchWriteItem"
+		Table     string // Empty for requests without a single table
+		Latency   time.Duration
+		Attempts  int // Including retries
+		Items     int // Items returned, for reads
+		// Items evaluated before the filters, for queries and scans
+		ScannedItems     int
+		ConsumedCapacity *ConsumedCapacity // Set when the request asked for it
+		Err              error
+	}
+
+	// MetricsHook receives the metrics of every request once it completes.
+	MetricsHook func(ctx context.Context, metrics RequestMetrics)
+)
+
+// recordMetrics returns an API option that times the requests, including
+// their retries, and passes their metrics to the hook.
+func recordMetrics(hook MetricsHook) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		operation := stack.ID()
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("Metrics",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (
+				middleware.InitializeOutput, middleware.Metadata, error,
+			) {
+				start := time.Now()
+				out, metadata, err := next.HandleInitialize(ctx, in)
+
+				metrics := RequestMetrics{
+					Operation: operation,
+					Table:     requestTable(in.Parameters),
+					Latency:   time.Since(start),
+					Attempts:  1,
+					Err:       err,
+				}
+				if results, ok := retry.GetAttemptResults(metadata); ok && len(results.Results) > 0 {
+					metrics.Attempts = len(results.Results)
+				}
+				if err == nil {
+					metrics.outputMetrics(out.Result)
+				}
+
+				hook(ctx, metrics)
+				return out, metadata, err
+			},
+		), middleware.Before)
+	}
+}
+
+func requestTable(input any) string {
+	switch in := input.(type) {
+	case *dynamodb.QueryInput:
+		return aws.ToString(in.TableName)
+	case *dynamodb.ScanInput:
+		return aws.ToString(in.TableName)
+	case *dynamodb.GetItemInput:
+		return aws.ToString(in.TableName)
+	case *dynamodb.PutItemInput:
+		return aws.ToString(in.TableName)
+	case *dynamodb.UpdateItemInput:
+		return aws.ToString(in.TableName)
+	case *dynamodb.DeleteItemInput:
+		return aws.ToString(in.TableName)
+	case *dynamodb.DescribeTableInput:
+		return aws.ToString(in.TableName)
+	case *dynamodb.BatchGetItemInput:
+		return singleTable(in.RequestItems)
+	case *dynamodb.BatchWriteItemInput:
+		return singleTable(in.RequestItems)
+	default:
+		return ""
+	}
+}
+
+// singleTable returns the table of a batch request when there is only one.
+func singleTable[V any](requests map[string]V) string {
+	if len(requests) != 1 {
+		return ""
+	}
+	for table := range requests {
+		return table
+	}
+	return ""
+}
+
+func (m *RequestMetrics) outputMetrics(output any) {
+	switch out := output.(type) {
+	case *dynamodb.QueryOutput:
+		m.Items = int(out.Count)
+		m.ScannedItems = int(out.ScannedCount)
+		m.ConsumedCapacity = addCapacity(nil, consumed(out.ConsumedCapacity)...)
+	case *dynamodb.ScanOutput:
+		m.Items = int(out.Count)
+		m.ScannedItems = int(out.ScannedCount)
+		m.ConsumedCapacity = addCapacity(nil, consumed(out.ConsumedCapacity)...)
+	case *dynamodb.GetItemOutput:
+		if out.Item != nil {
+			m.Items = 1
+		}
+		m.ConsumedCapacity = addCapacity(nil, consumed(out.ConsumedCapacity)...)
+	case *dynamodb.BatchGetItemOutput:
+		for _, items := range out.Responses {
+			m.Items += len(items)
+		}
+		m.ConsumedCapacity = addCapacity(nil, out.ConsumedCapacity...)
+	case *dynamodb.PutItemOutput:
+		m.ConsumedCapacity = addCapacity(nil, consumed(out.ConsumedCapacity)...)
+	case *dynamodb.UpdateItemOutput:
+		m.ConsumedCapacity = addCapacity(nil, consumed(out.ConsumedCapacity)...)
+	case *dynamodb.DeleteItemOutput:
+		m.ConsumedCapacity = addCapacity(nil, consumed(out.ConsumedCapacity)...)
+	case *dynamodb.BatchWriteItemOutput:
+		m.ConsumedCapacity = addCapacity(nil, out.ConsumedCapacity...)
+	case *dynamodb.TransactGetItemsOutput:
+		m.Items = len(out.Responses)
+		m.ConsumedCapacity = addCapacity(nil, out.ConsumedCapacity...)
+	case *dynamodb.TransactWriteItemsOutput:
+		m.ConsumedCapacity = addCapacity(nil, out.ConsumedCapacity...)
+	case *dynamodb.ExecuteStatementOutput:
+		m.Items = len(out.Items)
+		m.ConsumedCapacity = addCapacity(nil, consumed(out.ConsumedCapacity)...)
+	}
+}
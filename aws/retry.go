@@ -0,0 +1,122 @@
+package aws
+
+import (
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+const (
+	RetryStandard RetryMode = "STANDARD" // Retries with jittered exponential backoff, the default
+	// Also slows down the client when requests are throttled, so bursts back
+	// off instead of burning their attempts
+	RetryAdaptive RetryMode = "ADAPTIVE"
+)
+
+var throttleBaseBackoff = 100 * time.Millisecond
+
+// throttleCodes are the error codes that mean a request was throttled.
+var throttleCodes = map[string]struct{}{
+	"ProvisionedThroughputExceededException": {},
+	"ThrottlingException":                    {},
+	"RequestLimitExceeded":                   {},
+	"TooManyRequestsException":               {},
+	"SlowDown":                               {},
+}
+
+type (
+	RetryMode string
+
+	RetryConfig struct {
+		MaxAttempts int           // Attempts per request including the first, defaults to 3
+		MaxBackoff  time.Duration // Upper bound of the delay between attempts, defaults to 20s
+		Mode        RetryMode     // Defaults to RetryStandard
+		// Max attempts for specific operations, e.g., {"BatchWriteItem": 10}
+		Operations map[string]int
+	}
+)
+
+// IsThrottle reports whether the request failed because it was throttled,
+// e.g., with ProvisionedThroughputExceededException.
+func IsThrottle(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	_, ok := throttleCodes[apiErr.ErrorCode()]
+	return ok
+}
+
+// throttled leaves errors that are not throttles to the other checks.
+func throttled(err error) aws.Ternary {
+	if IsThrottle(err) {
+		return aws.TrueTernary
+	}
+	return aws.UnknownTernary
+}
+
+// retryer builds the retryer of the clients. Throttled requests are always
+// retried and start from a longer delay than other failures.
+func (r *RetryConfig) retryer() aws.Retryer {
+	standard := func(o *retry.StandardOptions) {
+		if r.MaxAttempts > 0 {
+			o.MaxAttempts = r.MaxAttempts
+		}
+		if r.MaxBackoff > 0 {
+			o.MaxBackoff = r.MaxBackoff
+		}
+
+		o.Backoff = throttleBackoff{
+			backoff: retry.NewExponentialJitterBackoff(o.MaxBackoff),
+			max:     o.MaxBackoff,
+		}
+		o.Retryables = append(o.Retryables, retry.IsErrorRetryableFunc(throttled))
+	}
+
+	if r.Mode == RetryAdaptive {
+		return retry.NewAdaptiveMode(func(o *retry.AdaptiveModeOptions) {
+			o.StandardOptions = append(o.StandardOptions, standard)
+			o.Throttles = append(o.Throttles, retry.IsErrorThrottleFunc(throttled))
+		})
+	}
+
+	return retry.NewStandard(standard)
+}
+
+// operationRetries returns an API option that overrides the max attempts of
+// the configured operations.
+func (r *RetryConfig) operationRetries(retryer aws.Retryer) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		attempts, ok := r.Operations[stack.ID()]
+		if !ok {
+			return nil
+		}
+
+		attempt := retry.NewAttemptMiddleware(retry.AddWithMaxAttempts(retryer, attempts), smithyhttp.RequestCloner)
+		_, err := stack.Finalize.Swap(attempt.ID(), attempt)
+		return err
+	}
+}
+
+type throttleBackoff struct {
+	backoff retry.BackoffDelayer
+	max     time.Duration
+}
+
+// BackoffDelay waits longer after throttles, doubling from 100ms up to the max
+// backoff with full jitter, giving the table capacity time to recover.
+func (b throttleBackoff) BackoffDelay(attempt int, err error) (time.Duration, error) {
+	if !IsThrottle(err) {
+		return b.backoff.BackoffDelay(attempt, err)
+	}
+
+	delay := min(throttleBaseBackoff<<min(attempt, 16), b.max)
+	return time.Duration(rand.Int64N(int64(delay)) + 1), nil
+}
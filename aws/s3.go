@@ -0,0 +1,231 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type (
+	PutObjectOptions struct {
+		Bucket       string
+		Key          string
+		Body         io.Reader
+		ContentType  string            // Optional, e.g., "application/json"
+		CacheControl string            // Optional, e.g., "max-age=3600"
+		Metadata     map[string]string // Optional user metadata, sent as x-amz-meta-* headers
+		Tags         map[string]string // Optional, up to 10
+		// Optional, the default encryption of the bucket applies when not set
+		Encryption *ServerSideEncryption
+	}
+
+	PutObjectResult struct {
+		ETag      string
+		VersionID string // Empty unless the bucket is versioned
+	}
+
+	// ObjectInfo describes an object without its content. Listed objects only
+	// have the key, size, ETag, last modified time and storage class.
+	ObjectInfo struct {
+		Key          string
+		Size         int64
+		ETag         string
+		ContentType  string
+		LastModified time.Time
+		Metadata     map[string]string
+		VersionID    string
+		StorageClass string
+		// Set on the "folders" of a list with a delimiter, whose key is the
+		// common prefix, e.g., "logs/2025/" when listing "logs/" by "/"
+		IsPrefix bool
+	}
+
+	// Object is an object and its content. Close the body once read.
+	Object struct {
+		ObjectInfo
+		Body io.ReadCloser
+	}
+
+	s3Service struct {
+		client    *s3.Client
+		transfer  TransferConfig
+		bandwidth *CapacityLimiter // Bytes per second of uploads and downloads
+	}
+)
+
+var (
+	S3ErrBucketNotSet   = errors.New("bucket not set")
+	S3ErrDeleteObject   = errors.New("failed to delete object")
+	S3ErrGetObject      = errors.New("failed to get object")
+	S3ErrHeadObject     = errors.New("failed to head object")
+	S3ErrKeyNotSet      = errors.New("object key not set")
+	S3ErrObjectNotFound = errors.New("object not found")
+	S3ErrPutObject      = errors.New("failed to put object")
+)
+
+// NewS3 returns an S3 client. With a custom endpoint, e.g., MinIO or
+// LocalStack, buckets are addressed by path instead of by subdomain.
+func NewS3(config Config) S3 {
+	awsConfig := load(&config)
+	service := &s3Service{
+		client: s3.NewFromConfig(awsConfig, func(o *s3.Options) {
+			if config.Retry != nil {
+				o.APIOptions = append(o.APIOptions, config.Retry.operationRetries(o.Retryer))
+			}
+			o.UsePathStyle = config.Endpoint != ""
+		}),
+	}
+
+	if config.Transfer != nil {
+		service.transfer = *config.Transfer
+		if config.Transfer.MaxBytesPerSecond > 0 {
+			service.bandwidth = NewCapacityLimiter(float64(config.Transfer.MaxBytesPerSecond))
+		}
+	}
+
+	return service
+}
+
+// Put uploads the body as the object in a single request, replacing any
+// object with the same key. Use Upload for bodies larger than a few hundred
+// megabytes or of unknown size.
+func (s *s3Service) Put(ctx context.Context, opts PutObjectOptions) (*PutObjectResult, error) {
+	if err := checkObject(opts.Bucket, opts.Key); err != nil {
+		return nil, err
+	}
+
+	algorithm, keyID, bucketKey, err := opts.Encryption.headers()
+	if err != nil {
+		return nil, err
+	}
+
+	input := &s3.PutObjectInput{
+		Bucket:               aws.String(opts.Bucket),
+		Key:                  aws.String(opts.Key),
+		Body:                 opts.Body,
+		Metadata:             opts.Metadata,
+		Tagging:              tagging(opts.Tags),
+		ServerSideEncryption: algorithm,
+		SSEKMSKeyId:          keyID,
+		BucketKeyEnabled:     bucketKey,
+	}
+	if opts.ContentType != "" {
+		input.ContentType = aws.String(opts.ContentType)
+	}
+	if opts.CacheControl != "" {
+		input.CacheControl = aws.String(opts.CacheControl)
+	}
+
+	response, err := s.client.PutObject(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", S3ErrPutObject, err)
+	}
+
+	return &PutObjectResult{
+		ETag:      aws.ToString(response.ETag),
+		VersionID: aws.ToString(response.VersionId),
+	}, nil
+}
+
+// Get returns the object with its content, or S3ErrObjectNotFound when it
+// does not exist. The caller must close the body.
+func (s *s3Service) Get(ctx context.Context, bucket, key string) (*Object, error) {
+	if err := checkObject(bucket, key); err != nil {
+		return nil, err
+	}
+
+	response, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, objectError(S3ErrGetObject, err)
+	}
+
+	return &Object{
+		ObjectInfo: ObjectInfo{
+			Key:          key,
+			Size:         aws.ToInt64(response.ContentLength),
+			ETag:         aws.ToString(response.ETag),
+			ContentType:  aws.ToString(response.ContentType),
+			LastModified: aws.ToTime(response.LastModified),
+			Metadata:     response.Metadata,
+			VersionID:    aws.ToString(response.VersionId),
+			StorageClass: string(response.StorageClass),
+		},
+		Body: response.Body,
+	}, nil
+}
+
+// Head returns the description of the object without its content, or
+// S3ErrObjectNotFound when it does not exist.
+func (s *s3Service) Head(ctx context.Context, bucket, key string) (*ObjectInfo, error) {
+	if err := checkObject(bucket, key); err != nil {
+		return nil, err
+	}
+
+	response, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, objectError(S3ErrHeadObject, err)
+	}
+
+	return &ObjectInfo{
+		Key:          key,
+		Size:         aws.ToInt64(response.ContentLength),
+		ETag:         aws.ToString(response.ETag),
+		ContentType:  aws.ToString(response.ContentType),
+		LastModified: aws.ToTime(response.LastModified),
+		Metadata:     response.Metadata,
+		VersionID:    aws.ToString(response.VersionId),
+		StorageClass: string(response.StorageClass),
+	}, nil
+}
+
+// Delete removes the object. Deleting a missing object is not an error.
+func (s *s3Service) Delete(ctx context.Context, bucket, key string) error {
+	if err := checkObject(bucket, key); err != nil {
+		return err
+	}
+
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", S3ErrDeleteObject, err)
+	}
+
+	return nil
+}
+
+func checkObject(bucket, key string) error {
+	if bucket == "" {
+		return S3ErrBucketNotSet
+	}
+	if key == "" {
+		return S3ErrKeyNotSet
+	}
+	return nil
+}
+
+// objectError wraps the error in the sentinel, or in S3ErrObjectNotFound when
+// the object does not exist. HEAD responses have no body, so their missing
+// objects are NotFound instead of NoSuchKey.
+func objectError(sentinel, err error) error {
+	var noSuchKey *types.NoSuchKey
+	var notFound *types.NotFound
+	if errors.As(err, &noSuchKey) || errors.As(err, &notFound) {
+		return fmt.Errorf("%w: %w", S3ErrObjectNotFound, err)
+	}
+
+	return fmt.Errorf("%w: %w", sentinel, err)
+}
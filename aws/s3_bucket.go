@@ -0,0 +1,209 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type SSEAlgorithm string
+
+const (
+	SSES3  SSEAlgorithm = "AES256"  // Keys managed by S3
+	SSEKMS SSEAlgorithm = "aws:kms" // Keys managed by KMS
+
+	bucketWaitTimeout = 2 * time.Minute
+)
+
+type (
+	CreateBucketOptions struct {
+		Bucket string
+		Region string // Optional, defaults to the region of the client
+		// Keeps every version of overwritten and deleted objects
+		Versioning bool
+		// Blocks public ACLs and bucket policies, so no object can be made
+		// public
+		BlockPublicAccess bool
+		// Optional default encryption of new objects. S3 encrypts with SSE-S3
+		// when not set.
+		Encryption *ServerSideEncryption
+	}
+
+	ServerSideEncryption struct {
+		Algorithm SSEAlgorithm
+		KMSKeyID  string // SSE-KMS key ID, ARN or alias, defaults to the aws/s3 key
+		// Reuses a bucket-level key with SSE-KMS, cutting KMS requests and
+		// costs
+		BucketKey bool
+	}
+)
+
+var (
+	S3ErrCreateBucket = errors.New("failed to create bucket")
+	S3ErrEncryption   = errors.New("invalid server-side encryption")
+	S3ErrHeadBucket   = errors.New("failed to head bucket")
+)
+
+// CreateBucket creates the bucket, waits for it to exist and applies the
+// configuration of the options. A bucket the account already owns is only
+// configured, so environments can be bootstrapped repeatedly.
+func (s *s3Service) CreateBucket(ctx context.Context, opts CreateBucketOptions) error {
+	if opts.Bucket == "" {
+		return S3ErrBucketNotSet
+	}
+	// Validated first, so invalid options never leave a half-set-up bucket
+	if opts.Encryption != nil {
+		if err := opts.Encryption.validate(); err != nil {
+			return err
+		}
+	}
+
+	region := opts.Region
+	if region == "" {
+		region = s.client.Options().Region
+	}
+
+	input := &s3.CreateBucketInput{Bucket: aws.String(opts.Bucket)}
+	// us-east-1 is the default location and can't be set as a constraint
+	if region != "" && region != "us-east-1" {
+		input.CreateBucketConfiguration = &types.CreateBucketConfiguration{
+			LocationConstraint: types.BucketLocationConstraint(region),
+		}
+	}
+
+	_, err := s.client.CreateBucket(ctx, input, withRegion(region))
+	var owned *types.BucketAlreadyOwnedByYou
+	if err != nil && !errors.As(err, &owned) {
+		return fmt.Errorf("%w: %w", S3ErrCreateBucket, err)
+	}
+
+	waiter := s3.NewBucketExistsWaiter(s.client)
+	err = waiter.Wait(ctx, &s3.HeadBucketInput{Bucket: aws.String(opts.Bucket)}, bucketWaitTimeout, withRegionWaiter(region))
+	if err != nil {
+		return fmt.Errorf("%w: %w", S3ErrCreateBucket, err)
+	}
+
+	if opts.BlockPublicAccess {
+		_, err := s.client.PutPublicAccessBlock(ctx, &s3.PutPublicAccessBlockInput{
+			Bucket: aws.String(opts.Bucket),
+			PublicAccessBlockConfiguration: &types.PublicAccessBlockConfiguration{
+				BlockPublicAcls:       aws.Bool(true),
+				BlockPublicPolicy:     aws.Bool(true),
+				IgnorePublicAcls:      aws.Bool(true),
+				RestrictPublicBuckets: aws.Bool(true),
+			},
+		}, withRegion(region))
+		if err != nil {
+			return fmt.Errorf("%w: public access block: %w", S3ErrCreateBucket, err)
+		}
+	}
+
+	if opts.Versioning {
+		_, err := s.client.PutBucketVersioning(ctx, &s3.PutBucketVersioningInput{
+			Bucket:                  aws.String(opts.Bucket),
+			VersioningConfiguration: &types.VersioningConfiguration{Status: types.BucketVersioningStatusEnabled},
+		}, withRegion(region))
+		if err != nil {
+			return fmt.Errorf("%w: versioning: %w", S3ErrCreateBucket, err)
+		}
+	}
+
+	if opts.Encryption != nil {
+		rule := types.ServerSideEncryptionByDefault{SSEAlgorithm: types.ServerSideEncryption(opts.Encryption.Algorithm)}
+		if opts.Encryption.KMSKeyID != "" {
+			rule.KMSMasterKeyID = aws.String(opts.Encryption.KMSKeyID)
+		}
+
+		_, err := s.client.PutBucketEncryption(ctx, &s3.PutBucketEncryptionInput{
+			Bucket: aws.String(opts.Bucket),
+			ServerSideEncryptionConfiguration: &types.ServerSideEncryptionConfiguration{
+				Rules: []types.ServerSideEncryptionRule{{
+					ApplyServerSideEncryptionByDefault: &rule,
+					BucketKeyEnabled:                   aws.Bool(opts.Encryption.BucketKey),
+				}},
+			},
+		}, withRegion(region))
+		if err != nil {
+			return fmt.Errorf("%w: encryption: %w", S3ErrCreateBucket, err)
+		}
+	}
+
+	return nil
+}
+
+// BucketExists reports whether the bucket exists and the caller can access
+// it.
+func (s *s3Service) BucketExists(ctx context.Context, bucket string) (bool, error) {
+	if bucket == "" {
+		return false, S3ErrBucketNotSet
+	}
+
+	_, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(bucket)})
+	if err == nil {
+		return true, nil
+	}
+
+	var notFound *types.NotFound
+	if errors.As(err, &notFound) {
+		return false, nil
+	}
+
+	return false, fmt.Errorf("%w: %w", S3ErrHeadBucket, err)
+}
+
+// headers returns the encryption headers of a write, empty when e is nil so
+// the default encryption of the bucket applies.
+func (e *ServerSideEncryption) headers() (types.ServerSideEncryption, *string, *bool, error) {
+	if e == nil {
+		return "", nil, nil, nil
+	}
+	if err := e.validate(); err != nil {
+		return "", nil, nil, err
+	}
+
+	var keyID *string
+	if e.KMSKeyID != "" {
+		keyID = aws.String(e.KMSKeyID)
+	}
+	var bucketKey *bool
+	if e.BucketKey {
+		bucketKey = aws.Bool(true)
+	}
+
+	return types.ServerSideEncryption(e.Algorithm), keyID, bucketKey, nil
+}
+
+func (e *ServerSideEncryption) validate() error {
+	switch e.Algorithm {
+	case SSES3:
+		if e.KMSKeyID != "" || e.BucketKey {
+			return fmt.Errorf("%w: KMS key and bucket key need %s", S3ErrEncryption, SSEKMS)
+		}
+	case SSEKMS:
+	default:
+		return fmt.Errorf("%w: unknown algorithm %q", S3ErrEncryption, e.Algorithm)
+	}
+
+	return nil
+}
+
+// withRegion sends a request to the region of the bucket, which may differ
+// from the region of the client.
+func withRegion(region string) func(*s3.Options) {
+	return func(o *s3.Options) {
+		if region != "" {
+			o.Region = region
+		}
+	}
+}
+
+func withRegionWaiter(region string) func(*s3.BucketExistsWaiterOptions) {
+	return func(o *s3.BucketExistsWaiterOptions) {
+		o.ClientOptions = append(o.ClientOptions, withRegion(region))
+	}
+}
@@ -0,0 +1,297 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const (
+	// maxCopySize is the largest object a single CopyObject request copies
+	maxCopySize int64 = 5 * 1024 * 1024 * 1024
+	// maxUploadParts is the max parts of a multipart upload
+	maxUploadParts         = 10000
+	defaultCopyPartSize    = 512 * 1024 * 1024
+	defaultCopyConcurrency = 5
+	minCopyPartSize        = 5 * 1024 * 1024
+)
+
+type (
+	CopyObjectOptions struct {
+		SourceBucket string
+		SourceKey    string
+		Bucket       string // Defaults to the source bucket
+		Key          string
+		// Optional, replaces the metadata and content type of the source. Both
+		// are kept when neither is set.
+		ContentType string
+		Metadata    map[string]string
+		// Optional encryption of the copy. S3 doesn't keep the encryption of
+		// the source, so the default encryption of the destination bucket
+		// applies when not set.
+		Encryption *ServerSideEncryption
+		// Optional storage class of the copy, S3 doesn't keep the one of the
+		// source either and defaults to STANDARD
+		StorageClass StorageClass
+		// Optional, replaces the tags of the source when not nil. The tags of
+		// objects copied in parts are only set this way, S3 doesn't copy them.
+		Tags map[string]string
+		// Size of the parts of a multipart copy, used for objects larger than
+		// 5 GiB. Defaults to TransferConfig.PartSize or 512 MiB and grows to
+		// fit in 10000 parts.
+		PartSize int64
+		// Max parts copied in parallel, defaults to TransferConfig.Concurrency
+		// or 5
+		Concurrency int
+	}
+
+	CopyObjectResult struct {
+		ETag      string
+		VersionID string // Empty unless the destination bucket is versioned
+	}
+)
+
+var S3ErrCopyObject = errors.New("failed to copy object")
+
+// Copy copies an object within S3, across buckets too, without downloading
+// it. Objects larger than 5 GiB are copied in parts, in parallel, and a
+// failed multipart copy is aborted.
+func (s *s3Service) Copy(ctx context.Context, opts CopyObjectOptions) (*CopyObjectResult, error) {
+	if opts.Bucket == "" {
+		opts.Bucket = opts.SourceBucket
+	}
+	if err := checkObject(opts.SourceBucket, opts.SourceKey); err != nil {
+		return nil, err
+	}
+	if err := checkObject(opts.Bucket, opts.Key); err != nil {
+		return nil, err
+	}
+	if opts.PartSize != 0 && opts.PartSize < minCopyPartSize {
+		return nil, fmt.Errorf("%w: part size must be at least %d bytes", S3ErrCopyObject, minCopyPartSize)
+	}
+
+	algorithm, keyID, bucketKey, err := opts.Encryption.headers()
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := s.Head(ctx, opts.SourceBucket, opts.SourceKey)
+	if err != nil {
+		return nil, err
+	}
+	if source.Size > maxCopySize {
+		return s.copyParts(ctx, opts, source)
+	}
+
+	input := &s3.CopyObjectInput{
+		Bucket:               aws.String(opts.Bucket),
+		Key:                  aws.String(opts.Key),
+		CopySource:           aws.String(copySource(opts.SourceBucket, opts.SourceKey)),
+		ServerSideEncryption: algorithm,
+		SSEKMSKeyId:          keyID,
+		BucketKeyEnabled:     bucketKey,
+		StorageClass:         types.StorageClass(opts.StorageClass),
+	}
+	if opts.Tags != nil {
+		input.TaggingDirective = types.TaggingDirectiveReplace
+		input.Tagging = tagging(opts.Tags)
+	}
+	if opts.ContentType != "" || opts.Metadata != nil {
+		input.MetadataDirective = types.MetadataDirectiveReplace
+		input.Metadata = opts.Metadata
+		if opts.ContentType != "" {
+			input.ContentType = aws.String(opts.ContentType)
+		}
+	}
+
+	response, err := s.client.CopyObject(ctx, input)
+	if err != nil {
+		return nil, objectError(S3ErrCopyObject, err)
+	}
+
+	result := &CopyObjectResult{VersionID: aws.ToString(response.VersionId)}
+	if response.CopyObjectResult != nil {
+		result.ETag = aws.ToString(response.CopyObjectResult.ETag)
+	}
+
+	return result, nil
+}
+
+// Move copies the object, then deletes the source. If the delete fails, the
+// object is left in both places.
+func (s *s3Service) Move(ctx context.Context, opts CopyObjectOptions) (*CopyObjectResult, error) {
+	if (opts.Bucket == "" || opts.Bucket == opts.SourceBucket) && opts.Key == opts.SourceKey {
+		return nil, fmt.Errorf("%w: source and destination are the same", S3ErrCopyObject)
+	}
+
+	result, err := s.Copy(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Delete(ctx, opts.SourceBucket, opts.SourceKey); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// copyParts copies the source as a multipart upload of ranges of it. The
+// metadata of the source isn't copied by S3 then, so it is set on the upload.
+func (s *s3Service) copyParts(ctx context.Context, opts CopyObjectOptions, source *ObjectInfo) (*CopyObjectResult, error) {
+	partSize := opts.PartSize
+	if partSize == 0 {
+		partSize = s.transfer.PartSize
+	}
+	if partSize == 0 {
+		partSize = defaultCopyPartSize
+	}
+	if minimum := (source.Size + maxUploadParts - 1) / maxUploadParts; partSize < minimum {
+		partSize = minimum
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = s.transfer.Concurrency
+	}
+	if concurrency <= 0 {
+		concurrency = defaultCopyConcurrency
+	}
+
+	metadata, contentType := source.Metadata, source.ContentType
+	if opts.ContentType != "" || opts.Metadata != nil {
+		metadata, contentType = opts.Metadata, opts.ContentType
+	}
+
+	// Validated by Copy
+	algorithm, keyID, bucketKey, _ := opts.Encryption.headers()
+
+	create := &s3.CreateMultipartUploadInput{
+		Bucket:               aws.String(opts.Bucket),
+		Key:                  aws.String(opts.Key),
+		Metadata:             metadata,
+		ServerSideEncryption: algorithm,
+		SSEKMSKeyId:          keyID,
+		BucketKeyEnabled:     bucketKey,
+		StorageClass:         types.StorageClass(opts.StorageClass),
+		Tagging:              tagging(opts.Tags),
+	}
+	if contentType != "" {
+		create.ContentType = aws.String(contentType)
+	}
+
+	upload, err := s.client.CreateMultipartUpload(ctx, create)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", S3ErrCopyObject, err)
+	}
+
+	parts, err := s.copyPartRanges(ctx, opts, upload.UploadId, source.Size, partSize, concurrency)
+	if err != nil {
+		// Aborted even when the context is canceled, so no parts are billed
+		_, _ = s.client.AbortMultipartUpload(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(opts.Bucket),
+			Key:      aws.String(opts.Key),
+			UploadId: upload.UploadId,
+		})
+		return nil, err
+	}
+
+	response, err := s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(opts.Bucket),
+		Key:             aws.String(opts.Key),
+		UploadId:        upload.UploadId,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", S3ErrCopyObject, err)
+	}
+
+	return &CopyObjectResult{
+		ETag:      aws.ToString(response.ETag),
+		VersionID: aws.ToString(response.VersionId),
+	}, nil
+}
+
+func (s *s3Service) copyPartRanges(ctx context.Context, opts CopyObjectOptions, uploadID *string, size, partSize int64, concurrency int) ([]types.CompletedPart, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		sem      = make(chan struct{}, concurrency)
+		parts    = make([]types.CompletedPart, (size+partSize-1)/partSize)
+	)
+
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	for i := range parts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			start := int64(i) * partSize
+			end := min(start+partSize, size) - 1
+			partNumber := aws.Int32(int32(i + 1))
+
+			response, err := s.client.UploadPartCopy(ctx, &s3.UploadPartCopyInput{
+				Bucket:          aws.String(opts.Bucket),
+				Key:             aws.String(opts.Key),
+				UploadId:        uploadID,
+				PartNumber:      partNumber,
+				CopySource:      aws.String(copySource(opts.SourceBucket, opts.SourceKey)),
+				CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+			})
+			if err != nil {
+				fail(fmt.Errorf("%w: part %d: %w", S3ErrCopyObject, i+1, err))
+				return
+			}
+
+			parts[i] = types.CompletedPart{
+				ETag:       response.CopyPartResult.ETag,
+				PartNumber: partNumber,
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return parts, nil
+}
+
+// copySource returns the URL-encoded "bucket/key" of a copy source.
+func copySource(bucket, key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return bucket + "/" + strings.Join(segments, "/")
+}
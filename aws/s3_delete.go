@@ -0,0 +1,120 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// deleteObjectsLimit is the max keys of a DeleteObjects request
+const deleteObjectsLimit = 1000
+
+type (
+	DeleteObjectsResult struct {
+		Deleted int
+		Failed  []DeleteFailure // Keys S3 refused to delete, e.g., AccessDenied
+	}
+
+	DeleteFailure struct {
+		Key     string
+		Code    string
+		Message string
+	}
+)
+
+var (
+	S3ErrDeleteObjects = errors.New("failed to delete objects")
+	S3ErrPrefixNotSet  = errors.New("prefix not set")
+)
+
+// DeleteObjects removes the objects in requests of up to 1000 keys. Keys S3
+// refuses to delete are reported in Failed instead of failing the call, while
+// an error means a whole request failed, with the result counting the
+// objects deleted before it.
+func (s *s3Service) DeleteObjects(ctx context.Context, bucket string, keys []string) (*DeleteObjectsResult, error) {
+	if bucket == "" {
+		return nil, S3ErrBucketNotSet
+	}
+
+	result := &DeleteObjectsResult{}
+	for batch := range slices.Chunk(keys, deleteObjectsLimit) {
+		if err := s.deleteBatch(ctx, bucket, batch, result); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// DeletePrefix removes every object under the prefix, deleting each page of
+// the listing as it is read. The prefix is required, so a whole bucket can't
+// be emptied by mistake.
+func (s *s3Service) DeletePrefix(ctx context.Context, bucket, prefix string) (*DeleteObjectsResult, error) {
+	if bucket == "" {
+		return nil, S3ErrBucketNotSet
+	}
+	if prefix == "" {
+		return nil, S3ErrPrefixNotSet
+	}
+
+	result := &DeleteObjectsResult{}
+	batch := make([]string, 0, deleteObjectsLimit)
+	for object, err := range s.List(ctx, bucket, prefix) {
+		if err != nil {
+			return result, err
+		}
+
+		batch = append(batch, object.Key)
+		if len(batch) < deleteObjectsLimit {
+			continue
+		}
+		if err := s.deleteBatch(ctx, bucket, batch, result); err != nil {
+			return result, err
+		}
+		batch = batch[:0]
+	}
+
+	if len(batch) > 0 {
+		if err := s.deleteBatch(ctx, bucket, batch, result); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// deleteBatch sends a quiet DeleteObjects request, so only the failed keys
+// are returned, and adds its outcome to the result.
+func (s *s3Service) deleteBatch(ctx context.Context, bucket string, keys []string, result *DeleteObjectsResult) error {
+	objects := make([]types.ObjectIdentifier, len(keys))
+	for i, key := range keys {
+		objects[i] = types.ObjectIdentifier{Key: aws.String(key)}
+	}
+
+	response, err := s.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+		Bucket: aws.String(bucket),
+		Delete: &types.Delete{
+			Objects: objects,
+			Quiet:   aws.Bool(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", S3ErrDeleteObjects, err)
+	}
+
+	for _, failure := range response.Errors {
+		result.Failed = append(result.Failed, DeleteFailure{
+			Key:     aws.ToString(failure.Key),
+			Code:    aws.ToString(failure.Code),
+			Message: aws.ToString(failure.Message),
+		})
+	}
+	result.Deleted += len(keys) - len(response.Errors)
+
+	return nil
+}
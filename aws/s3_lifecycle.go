@@ -0,0 +1,231 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+type StorageClass string
+
+const (
+	StorageStandardIA         StorageClass = "STANDARD_IA"
+	StorageOneZoneIA          StorageClass = "ONEZONE_IA"
+	StorageIntelligentTiering StorageClass = "INTELLIGENT_TIERING"
+	StorageGlacierIR          StorageClass = "GLACIER_IR" // Glacier Instant Retrieval
+	StorageGlacier            StorageClass = "GLACIER"    // Glacier Flexible Retrieval
+	StorageDeepArchive        StorageClass = "DEEP_ARCHIVE"
+)
+
+type (
+	// LifecycleRule moves or expires the objects under its prefix and with
+	// its tags as they age, e.g.:
+	//
+	//	aws.LifecycleRule{
+	//		ID:     "archive-logs",
+	//		Prefix: "logs/",
+	//		Transitions: []aws.LifecycleTransition{
+	//			{Days: 30, StorageClass: aws.StorageStandardIA},
+	//			{Days: 90, StorageClass: aws.StorageGlacier},
+	//		},
+	//		ExpirationDays:            365,
+	//		AbortIncompleteUploadDays: 7,
+	//	}
+	LifecycleRule struct {
+		ID       string
+		Prefix   string            // Optional, every object when empty
+		Tags     map[string]string // Optional, objects having all of these tags
+		Disabled bool
+		// Optional, in ascending order of days
+		Transitions    []LifecycleTransition
+		ExpirationDays int32 // Optional, days after creation to delete objects
+		// Optional, days after an object is overwritten or deleted to delete
+		// its previous versions, in versioned buckets
+		NoncurrentExpirationDays int32
+		// Optional, days after multipart uploads start to abort the ones never
+		// completed, deleting their parts
+		AbortIncompleteUploadDays int32
+	}
+
+	LifecycleTransition struct {
+		Days         int32 // Days after creation
+		StorageClass StorageClass
+	}
+)
+
+var (
+	S3ErrGetLifecycle = errors.New("failed to get lifecycle rules")
+	S3ErrSetLifecycle = errors.New("failed to set lifecycle rules")
+)
+
+// SetLifecycle replaces the lifecycle rules of the bucket. An empty list
+// removes them all.
+func (s *s3Service) SetLifecycle(ctx context.Context, bucket string, rules []LifecycleRule) error {
+	if bucket == "" {
+		return S3ErrBucketNotSet
+	}
+
+	if len(rules) == 0 {
+		_, err := s.client.DeleteBucketLifecycle(ctx, &s3.DeleteBucketLifecycleInput{Bucket: aws.String(bucket)})
+		if err != nil {
+			return fmt.Errorf("%w: %w", S3ErrSetLifecycle, err)
+		}
+		return nil
+	}
+
+	configuration := &types.BucketLifecycleConfiguration{}
+	for _, rule := range rules {
+		built, err := rule.build()
+		if err != nil {
+			return err
+		}
+		configuration.Rules = append(configuration.Rules, built)
+	}
+
+	_, err := s.client.PutBucketLifecycleConfiguration(ctx, &s3.PutBucketLifecycleConfigurationInput{
+		Bucket:                 aws.String(bucket),
+		LifecycleConfiguration: configuration,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", S3ErrSetLifecycle, err)
+	}
+
+	return nil
+}
+
+// Lifecycle returns the lifecycle rules of the bucket, none when it has no
+// lifecycle configuration.
+func (s *s3Service) Lifecycle(ctx context.Context, bucket string) ([]LifecycleRule, error) {
+	if bucket == "" {
+		return nil, S3ErrBucketNotSet
+	}
+
+	response, err := s.client.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{
+		Bucket: aws.String(bucket),
+	})
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchLifecycleConfiguration" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", S3ErrGetLifecycle, err)
+	}
+
+	rules := make([]LifecycleRule, 0, len(response.Rules))
+	for _, rule := range response.Rules {
+		rules = append(rules, lifecycleRule(rule))
+	}
+
+	return rules, nil
+}
+
+func (r LifecycleRule) build() (types.LifecycleRule, error) {
+	if len(r.Transitions) == 0 && r.ExpirationDays == 0 && r.NoncurrentExpirationDays == 0 && r.AbortIncompleteUploadDays == 0 {
+		return types.LifecycleRule{}, fmt.Errorf("%w: rule %q has no action", S3ErrSetLifecycle, r.ID)
+	}
+
+	rule := types.LifecycleRule{
+		Status: types.ExpirationStatusEnabled,
+		Filter: r.filter(),
+	}
+	if r.ID != "" {
+		rule.ID = aws.String(r.ID)
+	}
+	if r.Disabled {
+		rule.Status = types.ExpirationStatusDisabled
+	}
+
+	for _, transition := range r.Transitions {
+		rule.Transitions = append(rule.Transitions, types.Transition{
+			Days:         aws.Int32(transition.Days),
+			StorageClass: types.TransitionStorageClass(transition.StorageClass),
+		})
+	}
+	if r.ExpirationDays > 0 {
+		rule.Expiration = &types.LifecycleExpiration{Days: aws.Int32(r.ExpirationDays)}
+	}
+	if r.NoncurrentExpirationDays > 0 {
+		rule.NoncurrentVersionExpiration = &types.NoncurrentVersionExpiration{
+			NoncurrentDays: aws.Int32(r.NoncurrentExpirationDays),
+		}
+	}
+	if r.AbortIncompleteUploadDays > 0 {
+		rule.AbortIncompleteMultipartUpload = &types.AbortIncompleteMultipartUpload{
+			DaysAfterInitiation: aws.Int32(r.AbortIncompleteUploadDays),
+		}
+	}
+
+	return rule, nil
+}
+
+// filter returns the filter of the rule. S3 only takes a prefix and tags
+// together, or several tags, under an And operator.
+func (r LifecycleRule) filter() *types.LifecycleRuleFilter {
+	tags := make([]types.Tag, 0, len(r.Tags))
+	for _, name := range slices.Sorted(maps.Keys(r.Tags)) {
+		tags = append(tags, types.Tag{Key: aws.String(name), Value: aws.String(r.Tags[name])})
+	}
+
+	switch {
+	case len(tags) == 0:
+		return &types.LifecycleRuleFilter{Prefix: aws.String(r.Prefix)}
+	case len(tags) == 1 && r.Prefix == "":
+		return &types.LifecycleRuleFilter{Tag: &tags[0]}
+	default:
+		and := &types.LifecycleRuleAndOperator{Tags: tags}
+		if r.Prefix != "" {
+			and.Prefix = aws.String(r.Prefix)
+		}
+		return &types.LifecycleRuleFilter{And: and}
+	}
+}
+
+func lifecycleRule(rule types.LifecycleRule) LifecycleRule {
+	r := LifecycleRule{
+		ID:       aws.ToString(rule.ID),
+		Disabled: rule.Status == types.ExpirationStatusDisabled,
+	}
+
+	if filter := rule.Filter; filter != nil {
+		r.Prefix = aws.ToString(filter.Prefix)
+		tags := []types.Tag{}
+		if filter.Tag != nil {
+			tags = append(tags, *filter.Tag)
+		}
+		if filter.And != nil {
+			r.Prefix = aws.ToString(filter.And.Prefix)
+			tags = append(tags, filter.And.Tags...)
+		}
+		for _, tag := range tags {
+			if r.Tags == nil {
+				r.Tags = map[string]string{}
+			}
+			r.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+	}
+
+	for _, transition := range rule.Transitions {
+		r.Transitions = append(r.Transitions, LifecycleTransition{
+			Days:         aws.ToInt32(transition.Days),
+			StorageClass: StorageClass(transition.StorageClass),
+		})
+	}
+	if rule.Expiration != nil {
+		r.ExpirationDays = aws.ToInt32(rule.Expiration.Days)
+	}
+	if rule.NoncurrentVersionExpiration != nil {
+		r.NoncurrentExpirationDays = aws.ToInt32(rule.NoncurrentVersionExpiration.NoncurrentDays)
+	}
+	if rule.AbortIncompleteMultipartUpload != nil {
+		r.AbortIncompleteUploadDays = aws.ToInt32(rule.AbortIncompleteMultipartUpload.DaysAfterInitiation)
+	}
+
+	return r
+}
@@ -0,0 +1,171 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+var defaultListLimit int32 = 1000
+
+type (
+	ListOptions struct {
+		// Optional, e.g., "/" to list the objects and "folders" right under
+		// the prefix instead of every object below it
+		Delimiter string
+		PageSize  int32 // Max objects per request, defaults to 1000
+		// Optional, only yields the objects having all of these tags. The tags
+		// of each listed object are fetched with a request of their own, so
+		// narrow the listing down with the prefix first.
+		Tags map[string]string
+	}
+
+	ListPageOptions struct {
+		Bucket    string
+		Prefix    string // Optional, e.g., "logs/2025/"
+		Delimiter string // Optional, e.g., "/"
+		Limit     int32  // Max objects and prefixes per page, defaults to 1000
+		Cursor    string // NextCursor of the previous page
+	}
+
+	ListPageResult struct {
+		Objects    []ObjectInfo
+		Prefixes   []string // Common prefixes when listing with a delimiter
+		NextCursor string   // Empty when there are no more pages
+	}
+)
+
+var S3ErrListObjects = errors.New("failed to list objects")
+
+// List iterates over the objects under the prefix in key order, requesting
+// pages as the loop advances, so breaking out of it stops the listing. With
+// a delimiter, the common prefixes are yielded between the objects with
+// IsPrefix set:
+//
+//	for object, err := range s3.List(ctx, "logs", "2025/", aws.ListOptions{Delimiter: "/"}) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(object.Key, object.IsPrefix)
+//	}
+func (s *s3Service) List(ctx context.Context, bucket, prefix string, opts ...ListOptions) iter.Seq2[ObjectInfo, error] {
+	return func(yield func(ObjectInfo, error) bool) {
+		if bucket == "" {
+			yield(ObjectInfo{}, S3ErrBucketNotSet)
+			return
+		}
+
+		var o ListOptions
+		if len(opts) > 0 {
+			o = opts[0]
+		}
+
+		paginator := s3.NewListObjectsV2Paginator(s.client, listInput(bucket, prefix, o.Delimiter, o.PageSize))
+		for paginator.HasMorePages() {
+			response, err := paginator.NextPage(ctx)
+			if err != nil {
+				yield(ObjectInfo{}, fmt.Errorf("%w: %w", S3ErrListObjects, err))
+				return
+			}
+
+			for _, object := range listedObjects(response) {
+				if len(o.Tags) > 0 && !object.IsPrefix {
+					tagged, err := s.hasTags(ctx, bucket, object.Key, o.Tags)
+					if err != nil {
+						yield(ObjectInfo{}, err)
+						return
+					}
+					if !tagged {
+						continue
+					}
+				}
+
+				if !yield(object, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ListPage returns a page of the objects under the prefix, in key order. Pass
+// NextCursor back as the cursor to read the next page.
+func (s *s3Service) ListPage(ctx context.Context, opts ListPageOptions) (*ListPageResult, error) {
+	if opts.Bucket == "" {
+		return nil, S3ErrBucketNotSet
+	}
+
+	input := listInput(opts.Bucket, opts.Prefix, opts.Delimiter, opts.Limit)
+	if opts.Cursor != "" {
+		input.ContinuationToken = aws.String(opts.Cursor)
+	}
+
+	response, err := s.client.ListObjectsV2(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", S3ErrListObjects, err)
+	}
+
+	result := &ListPageResult{NextCursor: aws.ToString(response.NextContinuationToken)}
+	for _, object := range response.Contents {
+		result.Objects = append(result.Objects, listedObject(object))
+	}
+	for _, prefix := range response.CommonPrefixes {
+		result.Prefixes = append(result.Prefixes, aws.ToString(prefix.Prefix))
+	}
+
+	return result, nil
+}
+
+func listInput(bucket, prefix, delimiter string, limit int32) *s3.ListObjectsV2Input {
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+
+	input := &s3.ListObjectsV2Input{
+		Bucket:  aws.String(bucket),
+		MaxKeys: aws.Int32(limit),
+	}
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+	if delimiter != "" {
+		input.Delimiter = aws.String(delimiter)
+	}
+
+	return input
+}
+
+// listedObjects merges the objects and common prefixes of a page, which are
+// both sorted, into key order.
+func listedObjects(response *s3.ListObjectsV2Output) []ObjectInfo {
+	objects := make([]ObjectInfo, 0, len(response.Contents)+len(response.CommonPrefixes))
+
+	contents, prefixes := response.Contents, response.CommonPrefixes
+	for len(contents) > 0 || len(prefixes) > 0 {
+		if len(prefixes) == 0 || (len(contents) > 0 && aws.ToString(contents[0].Key) < aws.ToString(prefixes[0].Prefix)) {
+			objects = append(objects, listedObject(contents[0]))
+			contents = contents[1:]
+			continue
+		}
+
+		objects = append(objects, ObjectInfo{Key: aws.ToString(prefixes[0].Prefix), IsPrefix: true})
+		prefixes = prefixes[1:]
+	}
+
+	return objects
+}
+
+func listedObject(object types.Object) ObjectInfo {
+	return ObjectInfo{
+		Key:          aws.ToString(object.Key),
+		Size:         aws.ToInt64(object.Size),
+		ETag:         aws.ToString(object.ETag),
+		LastModified: aws.ToTime(object.LastModified),
+		StorageClass: string(object.StorageClass),
+	}
+}
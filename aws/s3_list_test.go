@@ -0,0 +1,49 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func TestListedObjects(t *testing.T) {
+	objects := listedObjects(&s3.ListObjectsV2Output{
+		Contents: []types.Object{
+			{Key: aws.String("logs/a.txt"), Size: aws.Int64(1)},
+			{Key: aws.String("logs/c.txt"), Size: aws.Int64(2)},
+			{Key: aws.String("logs/z.txt"), Size: aws.Int64(3)},
+		},
+		CommonPrefixes: []types.CommonPrefix{
+			{Prefix: aws.String("logs/2024/")},
+			{Prefix: aws.String("logs/b/")},
+		},
+	})
+
+	want := []struct {
+		key      string
+		isPrefix bool
+	}{
+		{"logs/2024/", true},
+		{"logs/a.txt", false},
+		{"logs/b/", true},
+		{"logs/c.txt", false},
+		{"logs/z.txt", false},
+	}
+	if len(objects) != len(want) {
+		t.Fatalf("got %d objects, want %d", len(objects), len(want))
+	}
+	for i, w := range want {
+		if objects[i].Key != w.key || objects[i].IsPrefix != w.isPrefix {
+			t.Errorf("object %d = %q (prefix %v), want %q (prefix %v)", i, objects[i].Key, objects[i].IsPrefix, w.key, w.isPrefix)
+		}
+	}
+	if objects[1].Size != 1 {
+		t.Errorf("size = %d, want 1", objects[1].Size)
+	}
+
+	if objects := listedObjects(&s3.ListObjectsV2Output{}); len(objects) != 0 {
+		t.Errorf("got %d objects from an empty page", len(objects))
+	}
+}
@@ -0,0 +1,180 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type S3EventName string
+
+const (
+	S3ObjectCreated  S3EventName = "s3:ObjectCreated:*"
+	S3ObjectRemoved  S3EventName = "s3:ObjectRemoved:*"
+	S3ObjectRestored S3EventName = "s3:ObjectRestore:Completed"
+	S3ObjectTagging  S3EventName = "s3:ObjectTagging:*"
+)
+
+// BucketNotification sends the events of the objects matching its filters
+// to one target: an SQS queue, an SNS topic or a Lambda function. The target
+// must allow S3 to send to it, e.g., through the queue policy, or setting the
+// notifications fails.
+type BucketNotification struct {
+	ID     string        // Optional, S3 generates one when empty
+	Events []S3EventName // Defaults to S3ObjectCreated
+	Prefix string        // Optional, e.g., "uploads/"
+	Suffix string        // Optional, e.g., ".jpg"
+
+	QueueARN    string
+	TopicARN    string
+	FunctionARN string
+}
+
+var (
+	S3ErrGetNotifications = errors.New("failed to get bucket notifications")
+	S3ErrSetNotifications = errors.New("failed to set bucket notifications")
+)
+
+// SetNotifications replaces the event notifications of the bucket. An empty
+// list removes them all.
+func (s *s3Service) SetNotifications(ctx context.Context, bucket string, notifications []BucketNotification) error {
+	if bucket == "" {
+		return S3ErrBucketNotSet
+	}
+
+	configuration := &types.NotificationConfiguration{}
+	for _, notification := range notifications {
+		targets := 0
+		for _, arn := range []string{notification.QueueARN, notification.TopicARN, notification.FunctionARN} {
+			if arn != "" {
+				targets++
+			}
+		}
+		if targets != 1 {
+			return fmt.Errorf("%w: notification %q needs exactly one target", S3ErrSetNotifications, notification.ID)
+		}
+
+		events := notification.Events
+		if len(events) == 0 {
+			events = []S3EventName{S3ObjectCreated}
+		}
+		eventTypes := make([]types.Event, len(events))
+		for i, event := range events {
+			eventTypes[i] = types.Event(event)
+		}
+
+		var id *string
+		if notification.ID != "" {
+			id = aws.String(notification.ID)
+		}
+		filter := notification.filter()
+
+		switch {
+		case notification.QueueARN != "":
+			configuration.QueueConfigurations = append(configuration.QueueConfigurations, types.QueueConfiguration{
+				Id:       id,
+				QueueArn: aws.String(notification.QueueARN),
+				Events:   eventTypes,
+				Filter:   filter,
+			})
+		case notification.TopicARN != "":
+			configuration.TopicConfigurations = append(configuration.TopicConfigurations, types.TopicConfiguration{
+				Id:       id,
+				TopicArn: aws.String(notification.TopicARN),
+				Events:   eventTypes,
+				Filter:   filter,
+			})
+		default:
+			configuration.LambdaFunctionConfigurations = append(configuration.LambdaFunctionConfigurations, types.LambdaFunctionConfiguration{
+				Id:                id,
+				LambdaFunctionArn: aws.String(notification.FunctionARN),
+				Events:            eventTypes,
+				Filter:            filter,
+			})
+		}
+	}
+
+	_, err := s.client.PutBucketNotificationConfiguration(ctx, &s3.PutBucketNotificationConfigurationInput{
+		Bucket:                    aws.String(bucket),
+		NotificationConfiguration: configuration,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", S3ErrSetNotifications, err)
+	}
+
+	return nil
+}
+
+// Notifications returns the event notifications of the bucket.
+func (s *s3Service) Notifications(ctx context.Context, bucket string) ([]BucketNotification, error) {
+	if bucket == "" {
+		return nil, S3ErrBucketNotSet
+	}
+
+	response, err := s.client.GetBucketNotificationConfiguration(ctx, &s3.GetBucketNotificationConfigurationInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", S3ErrGetNotifications, err)
+	}
+
+	var notifications []BucketNotification
+	for _, queue := range response.QueueConfigurations {
+		notification := newBucketNotification(queue.Id, queue.Events, queue.Filter)
+		notification.QueueARN = aws.ToString(queue.QueueArn)
+		notifications = append(notifications, notification)
+	}
+	for _, topic := range response.TopicConfigurations {
+		notification := newBucketNotification(topic.Id, topic.Events, topic.Filter)
+		notification.TopicARN = aws.ToString(topic.TopicArn)
+		notifications = append(notifications, notification)
+	}
+	for _, function := range response.LambdaFunctionConfigurations {
+		notification := newBucketNotification(function.Id, function.Events, function.Filter)
+		notification.FunctionARN = aws.ToString(function.LambdaFunctionArn)
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, nil
+}
+
+func (n BucketNotification) filter() *types.NotificationConfigurationFilter {
+	var rules []types.FilterRule
+	if n.Prefix != "" {
+		rules = append(rules, types.FilterRule{Name: types.FilterRuleNamePrefix, Value: aws.String(n.Prefix)})
+	}
+	if n.Suffix != "" {
+		rules = append(rules, types.FilterRule{Name: types.FilterRuleNameSuffix, Value: aws.String(n.Suffix)})
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	return &types.NotificationConfigurationFilter{Key: &types.S3KeyFilter{FilterRules: rules}}
+}
+
+func newBucketNotification(id *string, events []types.Event, filter *types.NotificationConfigurationFilter) BucketNotification {
+	notification := BucketNotification{ID: aws.ToString(id)}
+	for _, event := range events {
+		notification.Events = append(notification.Events, S3EventName(event))
+	}
+
+	if filter != nil && filter.Key != nil {
+		for _, rule := range filter.Key.FilterRules {
+			// S3 returns the names capitalized, e.g., "Prefix"
+			switch strings.ToLower(string(rule.Name)) {
+			case string(types.FilterRuleNamePrefix):
+				notification.Prefix = aws.ToString(rule.Value)
+			case string(types.FilterRuleNameSuffix):
+				notification.Suffix = aws.ToString(rule.Value)
+			}
+		}
+	}
+
+	return notification
+}
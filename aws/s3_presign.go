@@ -0,0 +1,242 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	DefaultPresignExpiry = 15 * time.Minute
+	// MaxPresignExpiry is the longest expiry of a Signature V4 URL
+	MaxPresignExpiry = 7 * 24 * time.Hour
+)
+
+type (
+	PresignOptions struct {
+		Expires time.Duration // Optional, defaults to 15 minutes and can't be more than 7 days
+		// Optional. A PUT must send this Content-Type, while a GET is answered
+		// with it, e.g., "image/png".
+		ContentType string
+		// Optional exact size in bytes of the body of a PUT
+		ContentLength int64
+	}
+
+	// PresignedRequest is a request anyone can send until it expires, without
+	// AWS credentials. Header holds the headers that were signed and must be
+	// sent as they are, e.g., Content-Type.
+	PresignedRequest struct {
+		URL     string
+		Method  string
+		Header  http.Header
+		Expires time.Time
+	}
+
+	PresignPostOptions struct {
+		Expires time.Duration // Optional, defaults to 15 minutes and can't be more than 7 days
+		// Optional. Lets the form send any key starting with it instead of
+		// the given key, e.g., "uploads/".
+		KeyPrefix string
+		// Optional exact Content-Type of the upload, e.g., "application/pdf"
+		ContentType string
+		// Optional start of the Content-Type the form may send, e.g., "image/"
+		ContentTypePrefix string
+		// Optional bounds of the size in bytes of the upload. No limit when
+		// MaxContentLength is 0.
+		MinContentLength int64
+		MaxContentLength int64
+	}
+
+	// PresignedPost is an HTML form upload anyone can send until it expires,
+	// without AWS credentials: a multipart/form-data POST to URL with every
+	// field, followed by the file in a "file" field, which must come last.
+	// Uploads breaking the conditions of the options are rejected by S3.
+	PresignedPost struct {
+		URL     string
+		Fields  map[string]string
+		Expires time.Time
+	}
+)
+
+var S3ErrPresign = errors.New("failed to presign request")
+
+// PresignGet returns a URL to download the object, e.g., from a browser.
+func (s *s3Service) PresignGet(ctx context.Context, bucket, key string, opts ...PresignOptions) (*PresignedRequest, error) {
+	if err := checkObject(bucket, key); err != nil {
+		return nil, err
+	}
+
+	o, err := presignOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if o.ContentType != "" {
+		input.ResponseContentType = aws.String(o.ContentType)
+	}
+
+	signed, err := s3.NewPresignClient(s.client).PresignGetObject(ctx, input, s3.WithPresignExpires(o.Expires))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", S3ErrPresign, err)
+	}
+
+	return &PresignedRequest{
+		URL:     signed.URL,
+		Method:  signed.Method,
+		Header:  signed.SignedHeader,
+		Expires: time.Now().Add(o.Expires),
+	}, nil
+}
+
+// PresignPut returns a URL to upload the object, e.g., from a browser,
+// without routing the body through the backend. Uploads not matching the
+// content type and length of the options are rejected by S3.
+func (s *s3Service) PresignPut(ctx context.Context, bucket, key string, opts ...PresignOptions) (*PresignedRequest, error) {
+	if err := checkObject(bucket, key); err != nil {
+		return nil, err
+	}
+
+	o, err := presignOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if o.ContentLength < 0 {
+		return nil, fmt.Errorf("%w: negative content length", S3ErrPresign)
+	}
+
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if o.ContentType != "" {
+		input.ContentType = aws.String(o.ContentType)
+	}
+	if o.ContentLength > 0 {
+		input.ContentLength = aws.Int64(o.ContentLength)
+	}
+
+	signed, err := s3.NewPresignClient(s.client).PresignPutObject(ctx, input, s3.WithPresignExpires(o.Expires))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", S3ErrPresign, err)
+	}
+
+	return &PresignedRequest{
+		URL:     signed.URL,
+		Method:  signed.Method,
+		Header:  signed.SignedHeader,
+		Expires: time.Now().Add(o.Expires),
+	}, nil
+}
+
+// PresignPost returns a form to upload the object straight from a browser,
+// e.g., an <input type="file"> posted with the fields. Unlike PresignPut, the
+// policy can bound the size of the upload and let the browser pick the key
+// under a prefix. With KeyPrefix set, an empty key defaults to the prefix
+// followed by "${filename}", which S3 replaces with the name of the file.
+func (s *s3Service) PresignPost(ctx context.Context, bucket, key string, opts ...PresignPostOptions) (*PresignedPost, error) {
+	var o PresignPostOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if key == "" && o.KeyPrefix != "" {
+		key = o.KeyPrefix + "${filename}"
+	}
+	if err := checkObject(bucket, key); err != nil {
+		return nil, err
+	}
+
+	expiry, err := presignOptions([]PresignOptions{{Expires: o.Expires}})
+	if err != nil {
+		return nil, err
+	}
+	o.Expires = expiry.Expires
+
+	conditions, err := o.conditions(key)
+	if err != nil {
+		return nil, err
+	}
+
+	signed, err := s3.NewPresignClient(s.client).PresignPostObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}, func(options *s3.PresignPostOptions) {
+		options.Expires = o.Expires
+		options.Conditions = conditions
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", S3ErrPresign, err)
+	}
+
+	if o.ContentType != "" {
+		signed.Values["Content-Type"] = o.ContentType
+	}
+
+	return &PresignedPost{
+		URL:     signed.URL,
+		Fields:  signed.Values,
+		Expires: time.Now().Add(o.Expires),
+	}, nil
+}
+
+// conditions returns the conditions of the POST policy beyond the bucket and
+// signature fields, which the SDK adds. Without a key prefix the SDK limits
+// the form to the exact key.
+func (o PresignPostOptions) conditions(key string) ([]any, error) {
+	var conditions []any
+
+	if o.KeyPrefix != "" {
+		if !strings.HasPrefix(key, o.KeyPrefix) {
+			return nil, fmt.Errorf("%w: key %q doesn't start with %q", S3ErrPresign, key, o.KeyPrefix)
+		}
+		conditions = append(conditions, []any{"starts-with", "$key", o.KeyPrefix})
+	}
+
+	switch {
+	case o.ContentType != "" && o.ContentTypePrefix != "":
+		return nil, fmt.Errorf("%w: content type and content type prefix both set", S3ErrPresign)
+	case o.ContentType != "":
+		conditions = append(conditions, []any{"eq", "$Content-Type", o.ContentType})
+	case o.ContentTypePrefix != "":
+		conditions = append(conditions, []any{"starts-with", "$Content-Type", o.ContentTypePrefix})
+	}
+
+	if o.MinContentLength < 0 || o.MaxContentLength < 0 {
+		return nil, fmt.Errorf("%w: negative content length", S3ErrPresign)
+	}
+	if o.MaxContentLength > 0 {
+		if o.MinContentLength > o.MaxContentLength {
+			return nil, fmt.Errorf("%w: min content length above max", S3ErrPresign)
+		}
+		conditions = append(conditions, []any{"content-length-range", o.MinContentLength, o.MaxContentLength})
+	} else if o.MinContentLength > 0 {
+		return nil, fmt.Errorf("%w: min content length set without max", S3ErrPresign)
+	}
+
+	return conditions, nil
+}
+
+func presignOptions(opts []PresignOptions) (PresignOptions, error) {
+	var o PresignOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	if o.Expires == 0 {
+		o.Expires = DefaultPresignExpiry
+	}
+	if o.Expires < 0 || o.Expires > MaxPresignExpiry {
+		return o, fmt.Errorf("%w: expiry must be between 0 and %s", S3ErrPresign, MaxPresignExpiry)
+	}
+
+	return o, nil
+}
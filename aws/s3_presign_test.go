@@ -0,0 +1,77 @@
+package aws
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPresignPostConditions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts PresignPostOptions
+		key  string
+		want []any
+	}{
+		{"none", PresignPostOptions{}, "a.txt", nil},
+		{
+			"key prefix",
+			PresignPostOptions{KeyPrefix: "uploads/"},
+			"uploads/${filename}",
+			[]any{[]any{"starts-with", "$key", "uploads/"}},
+		},
+		{
+			"content type",
+			PresignPostOptions{ContentType: "image/png"},
+			"a.png",
+			[]any{[]any{"eq", "$Content-Type", "image/png"}},
+		},
+		{
+			"content type prefix",
+			PresignPostOptions{ContentTypePrefix: "image/"},
+			"a.png",
+			[]any{[]any{"starts-with", "$Content-Type", "image/"}},
+		},
+		{
+			"length range",
+			PresignPostOptions{MinContentLength: 1, MaxContentLength: 1024},
+			"a.txt",
+			[]any{[]any{"content-length-range", int64(1), int64(1024)}},
+		},
+		{
+			"max length only",
+			PresignPostOptions{MaxContentLength: 1024},
+			"a.txt",
+			[]any{[]any{"content-length-range", int64(0), int64(1024)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.opts.conditions(tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("conditions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	invalid := []struct {
+		name string
+		opts PresignPostOptions
+		key  string
+	}{
+		{"key outside prefix", PresignPostOptions{KeyPrefix: "uploads/"}, "other/a.txt"},
+		{"content type and prefix", PresignPostOptions{ContentType: "image/png", ContentTypePrefix: "image/"}, "a.png"},
+		{"min without max", PresignPostOptions{MinContentLength: 1}, "a.txt"},
+		{"min above max", PresignPostOptions{MinContentLength: 10, MaxContentLength: 5}, "a.txt"},
+		{"negative min", PresignPostOptions{MinContentLength: -1, MaxContentLength: 5}, "a.txt"},
+		{"negative max", PresignPostOptions{MaxContentLength: -1}, "a.txt"},
+	}
+	for _, tt := range invalid {
+		if _, err := tt.opts.conditions(tt.key); !errors.Is(err, S3ErrPresign) {
+			t.Errorf("%s: error = %v, want S3ErrPresign", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type SelectFormat string
+
+const (
+	SelectCSV       SelectFormat = "CSV"
+	SelectJSON      SelectFormat = "JSON"       // A single JSON document
+	SelectJSONLines SelectFormat = "JSON_LINES" // One JSON object per line
+	SelectParquet   SelectFormat = "PARQUET"
+)
+
+type SelectOptions struct {
+	Bucket string
+	Key    string
+	// e.g., "SELECT s.id, s.total FROM S3Object s WHERE s.status = 'PAID'"
+	SQL    string
+	Format SelectFormat
+	// CSV only. Names the columns after the first line, e.g., s.total,
+	// instead of by position, e.g., s._2.
+	CSVHeader    bool
+	CSVDelimiter string // CSV only, defaults to ","
+	// Optional, "GZIP" or "BZIP2" for CSV and JSON objects
+	Compression string
+}
+
+var (
+	S3ErrSelect        = errors.New("failed to select object content")
+	S3ErrSelectNotSet  = errors.New("select SQL not set")
+	S3ErrSelectPartial = errors.New("select result ended early")
+)
+
+// Select runs the SQL over the object in S3 and yields each result row as
+// a JSON object, read from the response as it streams in. Only the selected
+// rows are transferred, so filtering large objects costs little bandwidth.
+// S3 Select is only available to accounts that used it before July 2024.
+func (s *s3Service) Select(ctx context.Context, opts SelectOptions) iter.Seq2[json.RawMessage, error] {
+	return func(yield func(json.RawMessage, error) bool) {
+		input, err := selectInput(opts)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		response, err := s.client.SelectObjectContent(ctx, input)
+		if err != nil {
+			yield(nil, objectError(S3ErrSelect, err))
+			return
+		}
+		stream := response.GetStream()
+		defer stream.Close()
+
+		// Records events split the rows anywhere, so the end of a payload is
+		// kept until the rest of its row arrives
+		var pending []byte
+		ended := false
+		for event := range stream.Events() {
+			switch event := event.(type) {
+			case *types.SelectObjectContentEventStreamMemberRecords:
+				pending = append(pending, event.Value.Payload...)
+				for {
+					end := bytes.IndexByte(pending, '\n')
+					if end < 0 {
+						break
+					}
+					row := bytes.TrimSpace(pending[:end])
+					pending = pending[end+1:]
+					if len(row) == 0 {
+						continue
+					}
+					if !yield(json.RawMessage(bytes.Clone(row)), nil) {
+						return
+					}
+				}
+			case *types.SelectObjectContentEventStreamMemberEnd:
+				ended = true
+			}
+		}
+
+		if err := stream.Err(); err != nil {
+			yield(nil, fmt.Errorf("%w: %w", S3ErrSelect, err))
+			return
+		}
+		if !ended {
+			yield(nil, S3ErrSelectPartial)
+		}
+	}
+}
+
+// SelectAs runs Select and unmarshals each row into T, whose JSON field
+// names match the columns of the SQL, e.g.:
+//
+//	type Order struct {
+//		ID    string  `json:"id"`
+//		Total float64 `json:"total,string"` // CSV values are strings
+//	}
+//
+//	for order, err := range aws.SelectAs[Order](ctx, s3, opts) {
+//		if err != nil {
+//			return err
+//		}
+//		total += order.Total
+//	}
+func SelectAs[T any](ctx context.Context, s S3, opts SelectOptions) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for row, err := range s.Select(ctx, opts) {
+			var out T
+			if err != nil {
+				yield(out, err)
+				return
+			}
+
+			if err := json.Unmarshal(row, &out); err != nil {
+				yield(out, fmt.Errorf("%w: %w", S3ErrSelect, err))
+				return
+			}
+			if !yield(out, nil) {
+				return
+			}
+		}
+	}
+}
+
+func selectInput(opts SelectOptions) (*s3.SelectObjectContentInput, error) {
+	if err := checkObject(opts.Bucket, opts.Key); err != nil {
+		return nil, err
+	}
+	if opts.SQL == "" {
+		return nil, S3ErrSelectNotSet
+	}
+
+	serialization := &types.InputSerialization{}
+	if opts.Compression != "" {
+		serialization.CompressionType = types.CompressionType(opts.Compression)
+	}
+
+	switch opts.Format {
+	case SelectCSV:
+		header := types.FileHeaderInfoNone
+		if opts.CSVHeader {
+			header = types.FileHeaderInfoUse
+		}
+		delimiter := opts.CSVDelimiter
+		if delimiter == "" {
+			delimiter = ","
+		}
+		serialization.CSV = &types.CSVInput{
+			FileHeaderInfo: header,
+			FieldDelimiter: aws.String(delimiter),
+		}
+	case SelectJSON:
+		serialization.JSON = &types.JSONInput{Type: types.JSONTypeDocument}
+	case SelectJSONLines:
+		serialization.JSON = &types.JSONInput{Type: types.JSONTypeLines}
+	case SelectParquet:
+		if opts.Compression != "" {
+			return nil, fmt.Errorf("%w: parquet objects are compressed by column", S3ErrSelect)
+		}
+		serialization.Parquet = &types.ParquetInput{}
+	default:
+		return nil, fmt.Errorf("%w: unknown format %q", S3ErrSelect, opts.Format)
+	}
+
+	return &s3.SelectObjectContentInput{
+		Bucket:             aws.String(opts.Bucket),
+		Key:                aws.String(opts.Key),
+		Expression:         aws.String(opts.SQL),
+		ExpressionType:     types.ExpressionTypeSql,
+		InputSerialization: serialization,
+		OutputSerialization: &types.OutputSerialization{
+			JSON: &types.JSONOutput{RecordDelimiter: aws.String("\n")},
+		},
+	}, nil
+}
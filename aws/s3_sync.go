@@ -0,0 +1,359 @@
+package aws
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+)
+
+type SyncDirection string
+
+const (
+	SyncUpload   SyncDirection = "UPLOAD"   // Local directory to S3
+	SyncDownload SyncDirection = "DOWNLOAD" // S3 to local directory
+
+	defaultSyncConcurrency = 5
+)
+
+type (
+	SyncOptions struct {
+		Direction   SyncDirection // Defaults to SyncUpload
+		Concurrency int           // Max files transferred in parallel, defaults to 5
+		// Part size of multipart uploads, see UploadOptions. Objects uploaded
+		// in parts are compared by an ETag computed with this part size, so
+		// keep it the same across syncs. Defaults to TransferConfig.PartSize
+		// or 5 MiB.
+		PartSize int64
+	}
+
+	SyncResult struct {
+		Transferred int   // Files uploaded or downloaded
+		Skipped     int   // Files already the same on both sides
+		Bytes       int64 // Size of the transferred files
+	}
+
+	syncFile struct {
+		path string
+		key  string
+		size int64
+	}
+)
+
+var S3ErrSync = errors.New("failed to sync")
+
+// Sync copies the files of the local directory to the objects under the
+// prefix, or the other way around, transferring only files missing or
+// changed on the other side. Files are compared by size, then by ETag, which
+// is the MD5 of objects uploaded in a single part. Objects whose ETag isn't
+// an MD5, e.g., encrypted with SSE-KMS, are transferred again on every sync.
+// Files missing from the source are kept.
+func (s *s3Service) Sync(ctx context.Context, localDir, bucket, prefix string, opts ...SyncOptions) (*SyncResult, error) {
+	if bucket == "" {
+		return nil, S3ErrBucketNotSet
+	}
+
+	var o SyncOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.Direction == "" {
+		o.Direction = SyncUpload
+	}
+	if o.Direction != SyncUpload && o.Direction != SyncDownload {
+		return nil, fmt.Errorf("%w: unknown direction %q", S3ErrSync, o.Direction)
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = defaultSyncConcurrency
+	}
+	if o.PartSize == 0 {
+		o.PartSize = s.transfer.PartSize
+	}
+	if o.PartSize == 0 {
+		o.PartSize = manager.DefaultUploadPartSize
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	// Objects under the prefix, by key
+	objects := map[string]ObjectInfo{}
+	for object, err := range s.List(ctx, bucket, prefix) {
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasSuffix(object.Key, "/") {
+			continue // "folder" placeholder
+		}
+		objects[object.Key] = object
+	}
+
+	var files []syncFile
+	var err error
+	if o.Direction == SyncUpload {
+		files, err = localFiles(localDir, prefix)
+	} else {
+		files, err = remoteFiles(localDir, prefix, objects)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		once     sync.Once
+		firstErr error
+		result   = &SyncResult{}
+		jobs     = make(chan syncFile)
+	)
+
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	for range o.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for file := range jobs {
+				transferred, err := s.syncFile(ctx, bucket, file, objects, o)
+				if err != nil {
+					fail(err)
+					return
+				}
+
+				mu.Lock()
+				if transferred {
+					result.Transferred++
+					result.Bytes += file.size
+				} else {
+					result.Skipped++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+send:
+	for _, file := range files {
+		select {
+		case jobs <- file:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return result, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// syncFile transfers the file unless both sides are the same, and reports
+// whether it was transferred.
+func (s *s3Service) syncFile(ctx context.Context, bucket string, file syncFile, objects map[string]ObjectInfo, opts SyncOptions) (bool, error) {
+	object, exists := objects[file.key]
+	if opts.Direction == SyncDownload {
+		if _, err := os.Stat(file.path); err == nil {
+			exists = true
+		} else if errors.Is(err, fs.ErrNotExist) {
+			exists = false
+		} else {
+			return false, fmt.Errorf("%w: %w", S3ErrSync, err)
+		}
+	}
+
+	if exists {
+		same, err := sameFile(file.path, object, opts.PartSize)
+		if err != nil {
+			return false, err
+		}
+		if same {
+			return false, nil
+		}
+	}
+
+	if opts.Direction == SyncUpload {
+		return true, s.uploadFile(ctx, bucket, file, opts)
+	}
+	return true, s.downloadFile(ctx, bucket, file)
+}
+
+func (s *s3Service) uploadFile(ctx context.Context, bucket string, file syncFile, opts SyncOptions) error {
+	f, err := os.Open(file.path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", S3ErrSync, err)
+	}
+	defer f.Close()
+
+	_, err = s.Upload(ctx, bucket, file.key, f, UploadOptions{PartSize: opts.PartSize})
+	return err
+}
+
+// downloadFile writes the object to a temporary file next to the target,
+// renamed once complete, so a failed download never leaves a partial file.
+func (s *s3Service) downloadFile(ctx context.Context, bucket string, file syncFile) error {
+	dir := filepath.Dir(file.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("%w: %w", S3ErrSync, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(file.path)+".*")
+	if err != nil {
+		return fmt.Errorf("%w: %w", S3ErrSync, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := s.Download(ctx, bucket, file.key, tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("%w: %w", S3ErrSync, err)
+	}
+	if err := os.Rename(tmp.Name(), file.path); err != nil {
+		return fmt.Errorf("%w: %w", S3ErrSync, err)
+	}
+
+	return nil
+}
+
+// localFiles returns the regular files below the directory with their keys.
+func localFiles(localDir, prefix string) ([]syncFile, error) {
+	var files []syncFile
+	err := filepath.WalkDir(localDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(localDir, path)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, syncFile{
+			path: path,
+			key:  prefix + filepath.ToSlash(rel),
+			size: info.Size(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", S3ErrSync, err)
+	}
+
+	return files, nil
+}
+
+// remoteFiles returns the objects with their local paths. Keys that would
+// land outside the directory, e.g., "prefix/../../etc/passwd", are rejected.
+func remoteFiles(localDir, prefix string, objects map[string]ObjectInfo) ([]syncFile, error) {
+	files := make([]syncFile, 0, len(objects))
+	for key, object := range objects {
+		rel := filepath.FromSlash(strings.TrimPrefix(key, prefix))
+		if !filepath.IsLocal(rel) {
+			return nil, fmt.Errorf("%w: key %q is outside of the directory", S3ErrSync, key)
+		}
+
+		files = append(files, syncFile{
+			path: filepath.Join(localDir, rel),
+			key:  key,
+			size: object.Size,
+		})
+	}
+
+	return files, nil
+}
+
+// sameFile reports whether the local file has the size and ETag of the
+// object.
+func sameFile(path string, object ObjectInfo, partSize int64) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", S3ErrSync, err)
+	}
+	if info.Size() != object.Size {
+		return false, nil
+	}
+
+	etag := strings.Trim(object.ETag, `"`)
+	parts := 0
+	if i := strings.LastIndexByte(etag, '-'); i >= 0 {
+		if parts, err = strconv.Atoi(etag[i+1:]); err != nil {
+			return false, nil
+		}
+	}
+
+	local, err := fileETag(path, info.Size(), partSize, parts)
+	if err != nil {
+		return false, err
+	}
+
+	return local == etag, nil
+}
+
+// fileETag computes the ETag S3 gives the file: the MD5 of its content, or
+// for a multipart upload, the MD5 of the MD5 of each part followed by the
+// number of parts. It is empty when the part size doesn't give that many
+// parts.
+func fileETag(path string, size, partSize int64, parts int) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", S3ErrSync, err)
+	}
+	defer f.Close()
+
+	if parts == 0 {
+		hash := md5.New()
+		if _, err := io.Copy(hash, f); err != nil {
+			return "", fmt.Errorf("%w: %w", S3ErrSync, err)
+		}
+		return hex.EncodeToString(hash.Sum(nil)), nil
+	}
+
+	if int64(parts) != (size+partSize-1)/partSize {
+		return "", nil
+	}
+
+	digests := md5.New()
+	for range parts {
+		hash := md5.New()
+		if _, err := io.CopyN(hash, f, partSize); err != nil && !errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("%w: %w", S3ErrSync, err)
+		}
+		digests.Write(hash.Sum(nil))
+	}
+
+	return hex.EncodeToString(digests.Sum(nil)) + "-" + strconv.Itoa(parts), nil
+}
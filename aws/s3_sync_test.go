@@ -0,0 +1,112 @@
+package aws
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileETag(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 25) // 250 bytes
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sum := md5.Sum(content)
+	single := hex.EncodeToString(sum[:])
+
+	// Parts of 100 bytes: 100, 100 and 50
+	var digests []byte
+	for _, part := range [][]byte{content[:100], content[100:200], content[200:]} {
+		sum := md5.Sum(part)
+		digests = append(digests, sum[:]...)
+	}
+	sum = md5.Sum(digests)
+	multipart := hex.EncodeToString(sum[:]) + "-3"
+
+	tests := []struct {
+		name     string
+		partSize int64
+		parts    int
+		want     string
+	}{
+		{"single part", 100, 0, single},
+		{"multipart", 100, 3, multipart},
+		{"part count mismatch", 100, 2, ""},
+		{"part size mismatch", 50, 3, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fileETag(path, int64(len(content)), tt.partSize, tt.parts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("fileETag() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("same file", func(t *testing.T) {
+		objects := []struct {
+			name   string
+			object ObjectInfo
+			want   bool
+		}{
+			{"single part", ObjectInfo{Size: 250, ETag: `"` + single + `"`}, true},
+			{"multipart", ObjectInfo{Size: 250, ETag: `"` + multipart + `"`}, true},
+			{"other content", ObjectInfo{Size: 250, ETag: `"d41d8cd98f00b204e9800998ecf8427e"`}, false},
+			{"other size", ObjectInfo{Size: 251, ETag: `"` + single + `"`}, false},
+			{"other part size", ObjectInfo{Size: 250, ETag: `"` + hex.EncodeToString(sum[:]) + `-5"`}, false},
+			{"malformed part count", ObjectInfo{Size: 250, ETag: `"abc-x"`}, false},
+		}
+		for _, tt := range objects {
+			got, err := sameFile(path, tt.object, 100)
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			if got != tt.want {
+				t.Errorf("%s: sameFile() = %v, want %v", tt.name, got, tt.want)
+			}
+		}
+	})
+
+	if _, err := sameFile(filepath.Join(t.TempDir(), "missing"), ObjectInfo{}, 100); !errors.Is(err, S3ErrSync) {
+		t.Errorf("sameFile() error = %v, want S3ErrSync", err)
+	}
+}
+
+func TestRemoteFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	files, err := remoteFiles(dir, "backup/", map[string]ObjectInfo{
+		"backup/a.txt":     {Size: 1},
+		"backup/sub/b.txt": {Size: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths := map[string]string{}
+	for _, file := range files {
+		paths[file.key] = file.path
+	}
+	if got, want := paths["backup/a.txt"], filepath.Join(dir, "a.txt"); got != want {
+		t.Errorf("path = %q, want %q", got, want)
+	}
+	if got, want := paths["backup/sub/b.txt"], filepath.Join(dir, "sub", "b.txt"); got != want {
+		t.Errorf("path = %q, want %q", got, want)
+	}
+
+	for _, key := range []string{"backup/../../etc/passwd", "backup/sub/../../x", "backup//etc/passwd", "backup/"} {
+		_, err := remoteFiles(dir, "backup/", map[string]ObjectInfo{key: {}})
+		if !errors.Is(err, S3ErrSync) {
+			t.Errorf("remoteFiles(%q) error = %v, want S3ErrSync", key, err)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+var (
+	S3ErrGetTags     = errors.New("failed to get object tags")
+	S3ErrSetMetadata = errors.New("failed to set object metadata")
+	S3ErrSetTags     = errors.New("failed to set object tags")
+)
+
+// Tags returns the tags of the object.
+func (s *s3Service) Tags(ctx context.Context, bucket, key string) (map[string]string, error) {
+	if err := checkObject(bucket, key); err != nil {
+		return nil, err
+	}
+
+	response, err := s.client.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, objectError(S3ErrGetTags, err)
+	}
+
+	tags := make(map[string]string, len(response.TagSet))
+	for _, tag := range response.TagSet {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+
+	return tags, nil
+}
+
+// SetTags replaces the tags of the object, up to 10. An empty map removes
+// them all.
+func (s *s3Service) SetTags(ctx context.Context, bucket, key string, tags map[string]string) error {
+	if err := checkObject(bucket, key); err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		_, err := s.client.DeleteObjectTagging(ctx, &s3.DeleteObjectTaggingInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+		if err != nil {
+			return objectError(S3ErrSetTags, err)
+		}
+		return nil
+	}
+
+	tagSet := make([]types.Tag, 0, len(tags))
+	for name, value := range tags {
+		tagSet = append(tagSet, types.Tag{Key: aws.String(name), Value: aws.String(value)})
+	}
+
+	_, err := s.client.PutObjectTagging(ctx, &s3.PutObjectTaggingInput{
+		Bucket:  aws.String(bucket),
+		Key:     aws.String(key),
+		Tagging: &types.Tagging{TagSet: tagSet},
+	})
+	if err != nil {
+		return objectError(S3ErrSetTags, err)
+	}
+
+	return nil
+}
+
+// SetMetadata replaces the user metadata of the object. S3 objects are
+// immutable, so the object is copied onto itself, keeping its content type,
+// encryption, storage class and tags but resetting other headers such as
+// Cache-Control. Objects encrypted with customer keys (SSE-C) or DSSE-KMS
+// cannot be copied this way and return S3ErrSetMetadata.
+func (s *s3Service) SetMetadata(ctx context.Context, bucket, key string, metadata map[string]string) error {
+	if err := checkObject(bucket, key); err != nil {
+		return err
+	}
+
+	object, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return objectError(S3ErrHeadObject, err)
+	}
+	if object.SSECustomerAlgorithm != nil {
+		return fmt.Errorf("%w: object is encrypted with a customer key", S3ErrSetMetadata)
+	}
+
+	var encryption *ServerSideEncryption
+	switch algorithm := SSEAlgorithm(object.ServerSideEncryption); algorithm {
+	case "":
+	case SSES3, SSEKMS:
+		encryption = &ServerSideEncryption{
+			Algorithm: algorithm,
+			KMSKeyID:  aws.ToString(object.SSEKMSKeyId),
+			BucketKey: aws.ToBool(object.BucketKeyEnabled),
+		}
+	default:
+		return fmt.Errorf("%w: unsupported encryption %q", S3ErrSetMetadata, algorithm)
+	}
+
+	tags, err := s.Tags(ctx, bucket, key)
+	if err != nil {
+		return err
+	}
+
+	// Copy only replaces the metadata when it is set
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+
+	_, err = s.Copy(ctx, CopyObjectOptions{
+		SourceBucket: bucket,
+		SourceKey:    key,
+		Key:          key,
+		ContentType:  aws.ToString(object.ContentType),
+		Metadata:     metadata,
+		Encryption:   encryption,
+		StorageClass: StorageClass(object.StorageClass),
+		Tags:         tags,
+	})
+	return err
+}
+
+// hasTags reports whether the object has every tag, with the same values.
+func (s *s3Service) hasTags(ctx context.Context, bucket, key string, tags map[string]string) (bool, error) {
+	objectTags, err := s.Tags(ctx, bucket, key)
+	if err != nil {
+		return false, err
+	}
+
+	for name, value := range tags {
+		if objectValue, ok := objectTags[name]; !ok || objectValue != value {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// tagging encodes tags as the query string of the x-amz-tagging header.
+func tagging(tags map[string]string) *string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	values := url.Values{}
+	for name, value := range tags {
+		values.Set(name, value)
+	}
+	return aws.String(values.Encode())
+}
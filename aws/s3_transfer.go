@@ -0,0 +1,255 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+// throttleChunk is the most a throttled transfer reads at once, so the rate
+// stays smooth under a low bandwidth cap.
+const throttleChunk = 32 * 1024
+
+type (
+	// TransferConfig sets the defaults and limits of the uploads, downloads
+	// and copies of an S3 client, e.g., so bulk jobs leave network for the
+	// rest of the host.
+	TransferConfig struct {
+		// Default part size of uploads and multipart copies, see
+		// UploadOptions and CopyObjectOptions
+		PartSize int64
+		// Default max parts uploaded or copied in parallel, per transfer
+		Concurrency int
+		// Optional cap on the bytes uploaded and downloaded per second, shared
+		// by every transfer of the client. Copies stay within S3 and are not
+		// capped.
+		MaxBytesPerSecond int64
+	}
+
+	// throttledReader reads no faster than the limiter allows.
+	throttledReader struct {
+		ctx     context.Context
+		r       io.Reader
+		limiter *CapacityLimiter
+	}
+
+	UploadOptions struct {
+		ContentType  string            // Optional, e.g., "application/json"
+		CacheControl string            // Optional, e.g., "max-age=3600"
+		Metadata     map[string]string // Optional user metadata, sent as x-amz-meta-* headers
+		Tags         map[string]string // Optional, up to 10
+		// Optional, the default encryption of the bucket applies when not set
+		Encryption *ServerSideEncryption
+		// Size of the parts of multipart uploads, defaults to
+		// TransferConfig.PartSize or 5 MiB and can't be less than 5 MiB. Each
+		// part in flight is buffered in memory.
+		PartSize int64
+		// Max parts uploaded in parallel, defaults to
+		// TransferConfig.Concurrency or 5
+		Concurrency int
+	}
+
+	UploadResult struct {
+		Location  string // URL of the object
+		ETag      string
+		VersionID string // Empty unless the bucket is versioned
+		UploadID  string // Empty when the body fit in a single part
+	}
+)
+
+var (
+	S3ErrDownload = errors.New("failed to download object")
+	S3ErrRange    = errors.New("invalid byte range")
+	S3ErrUpload   = errors.New("failed to upload object")
+)
+
+// Upload streams the body to the object, splitting it into parts uploaded in
+// parallel when it is larger than a part, so bodies of any or unknown size,
+// e.g., a pipe, can be uploaded. A failed multipart upload is aborted, so no
+// orphaned parts are left behind.
+func (s *s3Service) Upload(ctx context.Context, bucket, key string, body io.Reader, opts ...UploadOptions) (*UploadResult, error) {
+	if err := checkObject(bucket, key); err != nil {
+		return nil, err
+	}
+
+	var o UploadOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.PartSize == 0 {
+		o.PartSize = s.transfer.PartSize
+	}
+	if o.Concurrency == 0 {
+		o.Concurrency = s.transfer.Concurrency
+	}
+	if o.PartSize != 0 && o.PartSize < manager.MinUploadPartSize {
+		return nil, fmt.Errorf("%w: part size must be at least %d bytes", S3ErrUpload, manager.MinUploadPartSize)
+	}
+
+	algorithm, keyID, bucketKey, err := o.Encryption.headers()
+	if err != nil {
+		return nil, err
+	}
+
+	input := &s3.PutObjectInput{
+		Bucket:               aws.String(bucket),
+		Key:                  aws.String(key),
+		Body:                 s.throttle(ctx, body),
+		Metadata:             o.Metadata,
+		Tagging:              tagging(o.Tags),
+		ServerSideEncryption: algorithm,
+		SSEKMSKeyId:          keyID,
+		BucketKeyEnabled:     bucketKey,
+	}
+	if o.ContentType != "" {
+		input.ContentType = aws.String(o.ContentType)
+	}
+	if o.CacheControl != "" {
+		input.CacheControl = aws.String(o.CacheControl)
+	}
+
+	uploader := manager.NewUploader(s.client, func(u *manager.Uploader) {
+		if o.PartSize > 0 {
+			u.PartSize = o.PartSize
+		}
+		if o.Concurrency > 0 {
+			u.Concurrency = o.Concurrency
+		}
+	})
+
+	response, err := uploader.Upload(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", S3ErrUpload, err)
+	}
+
+	return &UploadResult{
+		Location:  response.Location,
+		ETag:      aws.ToString(response.ETag),
+		VersionID: aws.ToString(response.VersionID),
+		UploadID:  response.UploadID,
+	}, nil
+}
+
+// Download streams the object to the writer and returns the number of bytes
+// written. The object is copied as it is read, so objects of any size only
+// need a small buffer.
+func (s *s3Service) Download(ctx context.Context, bucket, key string, w io.Writer) (int64, error) {
+	return s.download(ctx, bucket, key, w, nil)
+}
+
+// DownloadRange streams length bytes of the object from the offset to the
+// writer, or the rest of the object when length is 0. A negative offset with
+// a length of 0 streams the last -offset bytes. A range past the end of the
+// object is cut short to its end, while one starting past it fails with
+// S3ErrRange.
+func (s *s3Service) DownloadRange(ctx context.Context, bucket, key string, w io.Writer, offset, length int64) (int64, error) {
+	byteRange, err := rangeHeader(offset, length)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.download(ctx, bucket, key, w, byteRange)
+}
+
+func (s *s3Service) download(ctx context.Context, bucket, key string, w io.Writer, byteRange *string) (int64, error) {
+	if err := checkObject(bucket, key); err != nil {
+		return 0, err
+	}
+
+	response, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Range:  byteRange,
+	})
+	if err != nil {
+		return 0, rangeError(objectError(S3ErrDownload, err))
+	}
+	defer response.Body.Close()
+
+	written, err := io.Copy(w, s.throttle(ctx, response.Body))
+	if err != nil {
+		return written, fmt.Errorf("%w: %w", S3ErrDownload, err)
+	}
+
+	return written, nil
+}
+
+// rangeHeader returns the HTTP Range of length bytes from the offset, open
+// ended when length is 0, or of the last -offset bytes when offset is
+// negative.
+func rangeHeader(offset, length int64) (*string, error) {
+	if length < 0 || (offset < 0 && length != 0) {
+		return nil, fmt.Errorf("%w: offset %d, length %d", S3ErrRange, offset, length)
+	}
+
+	switch {
+	case offset < 0:
+		return aws.String(fmt.Sprintf("bytes=%d", offset)), nil
+	case length == 0:
+		return aws.String(fmt.Sprintf("bytes=%d-", offset)), nil
+	default:
+		return aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)), nil
+	}
+}
+
+// maxRangeBuffer is the largest buffer GetRange allocates before reading.
+const maxRangeBuffer = 1 << 20
+
+// GetRange reads length bytes of the object from the offset, e.g., the footer
+// of a parquet file or the tail of a log. Offsets and lengths are as in
+// DownloadRange, so GetRange(ctx, bucket, key, -8, 0) reads the last 8
+// bytes. The bytes are held in memory, use DownloadRange for large ranges.
+func (s *s3Service) GetRange(ctx context.Context, bucket, key string, offset, length int64) ([]byte, error) {
+	// The range may go past the end of the object, so only small ones are
+	// allocated up front
+	var b bytes.Buffer
+	if length > 0 {
+		b.Grow(int(min(length, maxRangeBuffer)))
+	}
+
+	if _, err := s.DownloadRange(ctx, bucket, key, &b, offset, length); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// rangeError wraps the error of a range starting past the end of the object
+// in S3ErrRange.
+func rangeError(err error) error {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "InvalidRange" {
+		return fmt.Errorf("%w: %w", S3ErrRange, err)
+	}
+	return err
+}
+
+// throttle caps the rate the reader is read at to the bandwidth of the
+// client, when it has one.
+func (s *s3Service) throttle(ctx context.Context, r io.Reader) io.Reader {
+	if s.bandwidth == nil {
+		return r
+	}
+	return &throttledReader{ctx: ctx, r: r, limiter: s.bandwidth}
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if err := t.limiter.Wait(t.ctx); err != nil {
+		return 0, err
+	}
+
+	if len(p) > throttleChunk {
+		p = p[:throttleChunk]
+	}
+	n, err := t.r.Read(p)
+	t.limiter.Consume(float64(n))
+
+	return n, err
+}
@@ -30,7 +30,7 @@ var awsCmd = &cobra.Command{
 		ddb := aws.NewDynamoDB(*c.AWS)
 
 		fmt.Println("Querying...")
-		items, err := ddb.Query(context.TODO(), aws.QueryOptions{
+		result, err := ddb.Query(context.TODO(), aws.QueryOptions{
 			Table: "table",
 			Index: "Status",
 			Partition: &aws.QueryKeyValue{
@@ -50,9 +50,12 @@ var awsCmd = &cobra.Command{
 			// 	Operator: aws.Equal,
 			// },
 		})
+		if err != nil {
+			log.Fatal(err)
+		}
 
 		// Marshal with indentation for readability
-		out, err := json.MarshalIndent(items, "", "  ")
+		out, err := json.MarshalIndent(result.Items, "", "  ")
 		if err != nil {
 			panic(err)
 		}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ricomonster/hephaestus/aws"
+	"github.com/ricomonster/hephaestus/config"
+)
+
+// generateCmd writes typed accessors for a table, e.g., from a go:generate
+// directive:
+//
+//	//go:generate go run github.com/ricomonster/hephaestus/cmd/cli generate --schema orders.yaml --out orders_table.go
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate typed Go accessors for a table schema or an existing table",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		schemaFile, _ := cmd.Flags().GetString("schema")
+		table, _ := cmd.Flags().GetString("table")
+		pkg, _ := cmd.Flags().GetString("package")
+		typeName, _ := cmd.Flags().GetString("type")
+		out, _ := cmd.Flags().GetString("out")
+
+		// go generate sets the package of the file holding the directive
+		if pkg == "" {
+			pkg = os.Getenv("GOPACKAGE")
+		}
+
+		var schema *aws.TableSchema
+		switch {
+		case schemaFile != "" && table != "":
+			log.Fatal("set either --schema or --table")
+		case schemaFile != "":
+			data, err := os.ReadFile(schemaFile)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if schema, err = aws.ParseTableSchema(data); err != nil {
+				log.Fatal(err)
+			}
+		case table != "":
+			c, err := config.Load(".env")
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			description, err := aws.NewDynamoDB(*c.AWS).DescribeTable(context.Background(), table)
+			if err != nil {
+				log.Fatal(err)
+			}
+			described := description.Schema()
+			schema = &described
+		default:
+			log.Fatal("set --schema or --table")
+		}
+
+		source, err := aws.GenerateAccessors(*schema, aws.GenerateOptions{Package: pkg, Type: typeName})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if out == "" {
+			fmt.Print(string(source))
+			return
+		}
+		if err := os.WriteFile(out, source, 0o644); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(generateCmd)
+
+	generateCmd.Flags().String("schema", "", "YAML table schema file to generate from")
+	generateCmd.Flags().String("table", "", "Existing table to describe and generate from")
+	generateCmd.Flags().String("package", "", "Package of the generated file, defaults to $GOPACKAGE")
+	generateCmd.Flags().String("type", "", "Name of the accessor type, defaults to the table name in CamelCase")
+	generateCmd.Flags().String("out", "", "File to write, stdout when empty")
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ricomonster/hephaestus/aws"
+	"github.com/ricomonster/hephaestus/config"
+)
+
+// seedCmd loads fixture files into their DynamoDB tables
+var seedCmd = &cobra.Command{
+	Use:   "seed [files...]",
+	Short: "Load JSON or YAML fixtures into DynamoDB tables",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := config.Load(".env")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fixtures, err := aws.LoadFixtures(args...)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		truncate, _ := cmd.Flags().GetBool("truncate")
+
+		ddb := aws.NewDynamoDB(*c.AWS)
+		result, err := ddb.Seed(context.Background(), fixtures, aws.SeedOptions{Truncate: truncate})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for table, written := range result.Written {
+			if truncate {
+				fmt.Printf("%s: deleted %d, wrote %d\n", table, result.Deleted[table], written)
+			} else {
+				fmt.Printf("%s: wrote %d\n", table, written)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(seedCmd)
+
+	seedCmd.Flags().Bool("truncate", false, "Delete the existing items of the tables first")
+}
@@ -46,10 +46,14 @@ func Load(file string) (*Config, error) {
 			Env: viper.GetString("APP_ENV"),
 		},
 		AWS: &aws.Config{
-			Profile: viper.GetString("AWS_PROFILE"),
-			Region:  viper.GetString("AWS_REGION"),
+			Profile:  viper.GetString("AWS_PROFILE"),
+			Region:   viper.GetString("AWS_REGION"),
+			Endpoint: viper.GetString("AWS_ENDPOINT"),
 		},
 	}
+	if viper.GetBool("AWS_DEBUG") {
+		c.AWS.Debug = aws.DebugJSON(os.Stderr)
+	}
 
 	return c, nil
 }
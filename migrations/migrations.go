@@ -0,0 +1,319 @@
+// Package migrations applies versioned changes to DynamoDB tables, e.g.,
+// creating tables, adding GSIs and backfilling attributes, and records the
+// applied versions in a state table.
+//
+//	migrator := migrations.New(ddb, []migrations.Migration{{
+//		Version: 1,
+//		Name:    "create users",
+//		Steps:   []migrations.Step{migrations.CreateTable(usersSchema)},
+//	}})
+//	err := migrator.Migrate(ctx)
+package migrations
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ricomonster/hephaestus/aws"
+)
+
+var defaultStateTable = "schema_migrations"
+
+// lockVersion is the state item held while migrations run.
+const lockVersion = 0
+
+type (
+	// Func changes the tables of a step.
+	Func func(ctx context.Context, ddb aws.DynamoDB) error
+
+	// Step is a single change. Down reverts Up and may be nil when the change
+	// cannot be reverted.
+	Step struct {
+		Up   Func
+		Down Func
+	}
+
+	// Migration is a versioned group of steps. Steps run in order on Up and in
+	// reverse order on Down.
+	Migration struct {
+		Version int64 // Unique and greater than zero
+		Name    string
+		Steps   []Step
+	}
+
+	Options struct {
+		StateTable string // Table recording the applied versions, defaults to "schema_migrations"
+	}
+
+	Status struct {
+		Version   int64
+		Name      string
+		Applied   bool
+		AppliedAt time.Time // Zero when not applied
+	}
+
+	Migrator struct {
+		ddb        aws.DynamoDB
+		migrations []Migration
+		stateTable string
+	}
+
+	state struct {
+		Version   int64     `dynamodbav:"version"`
+		Name      string    `dynamodbav:"name"`
+		AppliedAt time.Time `dynamodbav:"applied_at"`
+	}
+)
+
+var (
+	ErrDuplicateVersion = errors.New("duplicate migration version")
+	ErrInvalidVersion   = errors.New("migration version must be greater than zero")
+	ErrLocked           = errors.New("migrations are locked by another run")
+	ErrMigration        = errors.New("migration failed")
+	ErrNotReversible    = errors.New("migration cannot be reverted")
+)
+
+// New returns a migrator for the migrations, which can be in any order.
+func New(ddb aws.DynamoDB, migrations []Migration, opts ...Options) *Migrator {
+	var o Options
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.StateTable == "" {
+		o.StateTable = defaultStateTable
+	}
+
+	sorted := slices.Clone(migrations)
+	slices.SortFunc(sorted, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+
+	return &Migrator{ddb: ddb, migrations: sorted, stateTable: o.StateTable}
+}
+
+// Migrate applies the pending migrations in version order, creating the state
+// table when needed. It stops at the first failure, leaving the failed
+// migration pending.
+func (m *Migrator) Migrate(ctx context.Context) error {
+	if err := m.validate(); err != nil {
+		return err
+	}
+
+	return m.locked(ctx, func(applied map[int64]state) error {
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+
+			for i, step := range migration.Steps {
+				if step.Up == nil {
+					continue
+				}
+				if err := step.Up(ctx, m.ddb); err != nil {
+					return fmt.Errorf("%w: %d %s: step %d: %w", ErrMigration, migration.Version, migration.Name, i+1, err)
+				}
+			}
+
+			_, err := m.ddb.Put(ctx, m.stateTable, state{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now().UTC(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Rollback reverts the last applied migrations, newest first, up to steps of
+// them. A migration with a step that cannot be reverted is left as is, and
+// stops the rollback with ErrNotReversible.
+func (m *Migrator) Rollback(ctx context.Context, steps int) error {
+	if err := m.validate(); err != nil {
+		return err
+	}
+
+	return m.locked(ctx, func(applied map[int64]state) error {
+		for _, migration := range slices.Backward(m.migrations) {
+			if steps <= 0 {
+				return nil
+			}
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+
+			// Check every step first, so a migration is never partly reverted
+			for i, step := range migration.Steps {
+				if step.Down == nil {
+					return fmt.Errorf("%w: %d %s: step %d", ErrNotReversible, migration.Version, migration.Name, i+1)
+				}
+			}
+
+			for i, step := range slices.Backward(migration.Steps) {
+				if err := step.Down(ctx, m.ddb); err != nil {
+					return fmt.Errorf("%w: %d %s: step %d: %w", ErrMigration, migration.Version, migration.Name, i+1, err)
+				}
+			}
+
+			_, err := m.ddb.Delete(ctx, aws.DeleteOptions{
+				Table: m.stateTable,
+				Key:   aws.Key{"version": migration.Version},
+			})
+			if err != nil {
+				return err
+			}
+			steps--
+		}
+
+		return nil
+	})
+}
+
+// Status lists the migrations and whether they are applied.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	exists, err := m.ddb.TableExists(ctx, m.stateTable)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := map[int64]state{}
+	if exists {
+		applied, err = m.applied(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]Status, len(m.migrations))
+	for i, migration := range m.migrations {
+		s, ok := applied[migration.Version]
+		statuses[i] = Status{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Applied:   ok,
+			AppliedAt: s.AppliedAt,
+		}
+	}
+
+	return statuses, nil
+}
+
+// Unlock removes the lock left behind by a run that did not finish, e.g.,
+// after a crash.
+func (m *Migrator) Unlock(ctx context.Context) error {
+	_, err := m.ddb.Delete(ctx, aws.DeleteOptions{
+		Table: m.stateTable,
+		Key:   aws.Key{"version": lockVersion},
+	})
+	return err
+}
+
+func (m *Migrator) validate() error {
+	for i, migration := range m.migrations {
+		if migration.Version <= 0 {
+			return fmt.Errorf("%w: %s", ErrInvalidVersion, migration.Name)
+		}
+		if i > 0 && m.migrations[i-1].Version == migration.Version {
+			return fmt.Errorf("%w: %d", ErrDuplicateVersion, migration.Version)
+		}
+	}
+	return nil
+}
+
+// locked runs fn while holding the lock item of the state table, so
+// concurrent runs do not apply the same migrations twice.
+func (m *Migrator) locked(ctx context.Context, fn func(applied map[int64]state) error) error {
+	if err := m.ensureStateTable(ctx); err != nil {
+		return err
+	}
+
+	_, err := m.ddb.Put(ctx, m.stateTable, state{
+		Version:   lockVersion,
+		Name:      "lock",
+		AppliedAt: time.Now().UTC(),
+	}, aws.PutOptions{
+		Condition: &aws.Where{Conditions: []aws.WhereCondition{{
+			Field:    "version",
+			Operator: aws.AttributeNotExists,
+		}}},
+	})
+	if errors.Is(err, aws.DynamoDBErrConditionFailed) {
+		return ErrLocked
+	}
+	if err != nil {
+		return err
+	}
+	// Release even when the context is canceled
+	defer m.Unlock(context.WithoutCancel(ctx))
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	return fn(applied)
+}
+
+func (m *Migrator) ensureStateTable(ctx context.Context) error {
+	exists, err := m.ddb.TableExists(ctx, m.stateTable)
+	if err != nil || exists {
+		return err
+	}
+
+	_, err = m.ddb.CreateTable(ctx, aws.CreateTableOptions{
+		Table:        m.stateTable,
+		PartitionKey: aws.KeyAttribute{Name: "version", Type: aws.AttributeNumber},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = m.ddb.WaitForTableActive(ctx, m.stateTable)
+	return err
+}
+
+// applied returns the applied migrations by version.
+func (m *Migrator) applied(ctx context.Context) (map[int64]state, error) {
+	applied := map[int64]state{}
+
+	var cursor string
+	for {
+		result, err := m.ddb.Scan(ctx, aws.ScanOptions{
+			Table:  m.stateTable,
+			Cursor: cursor,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		states, err := aws.UnmarshalItems[state](result.Items)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range states {
+			if s.Version != lockVersion {
+				applied[s.Version] = s
+			}
+		}
+
+		if result.NextCursor == "" {
+			return applied, nil
+		}
+		cursor = result.NextCursor
+	}
+}
+
+func (s Status) String() string {
+	status := "pending"
+	if s.Applied {
+		status = "applied " + s.AppliedAt.Format(time.RFC3339)
+	}
+	return fmt.Sprintf("%d %s: %s", s.Version, s.Name, status)
+}
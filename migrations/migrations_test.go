@@ -0,0 +1,235 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/ricomonster/hephaestus/aws"
+)
+
+// fakeDynamoDB keeps the state table in memory. Methods the migrator doesn't
+// use panic through the nil embedded interface.
+type fakeDynamoDB struct {
+	aws.DynamoDB
+	exists bool
+	items  map[string]aws.Item // By version
+}
+
+func newFakeDynamoDB() *fakeDynamoDB {
+	return &fakeDynamoDB{items: map[string]aws.Item{}}
+}
+
+func (f *fakeDynamoDB) TableExists(context.Context, string) (bool, error) {
+	return f.exists, nil
+}
+
+func (f *fakeDynamoDB) CreateTable(context.Context, aws.CreateTableOptions) (*aws.TableDescription, error) {
+	f.exists = true
+	return &aws.TableDescription{}, nil
+}
+
+func (f *fakeDynamoDB) WaitForTableActive(context.Context, string) (*aws.TableDescription, error) {
+	return &aws.TableDescription{}, nil
+}
+
+func (f *fakeDynamoDB) Put(_ context.Context, _ string, item any, opts ...aws.PutOptions) (*aws.PutResult, error) {
+	av, err := aws.MarshalItem(item)
+	if err != nil {
+		return nil, err
+	}
+	s, err := aws.UnmarshalItem[state](av)
+	if err != nil {
+		return nil, err
+	}
+
+	version := fmt.Sprint(s.Version)
+	if _, ok := f.items[version]; ok && len(opts) > 0 && opts[0].Condition != nil {
+		return nil, aws.DynamoDBErrConditionFailed
+	}
+	f.items[version] = av
+	return &aws.PutResult{}, nil
+}
+
+func (f *fakeDynamoDB) Delete(_ context.Context, opts aws.DeleteOptions) (*aws.DeleteResult, error) {
+	delete(f.items, fmt.Sprint(opts.Key["version"]))
+	return &aws.DeleteResult{}, nil
+}
+
+func (f *fakeDynamoDB) Scan(context.Context, aws.ScanOptions) (*aws.ScanResult, error) {
+	return &aws.ScanResult{Items: slices.Collect(maps.Values(f.items))}, nil
+}
+
+func (f *fakeDynamoDB) versions() []string {
+	return slices.Sorted(maps.Keys(f.items))
+}
+
+// recordStep returns a step appending "up name" and "down name" to log.
+func recordStep(log *[]string, name string) Step {
+	return Step{
+		Up: func(context.Context, aws.DynamoDB) error {
+			*log = append(*log, "up "+name)
+			return nil
+		},
+		Down: func(context.Context, aws.DynamoDB) error {
+			*log = append(*log, "down "+name)
+			return nil
+		},
+	}
+}
+
+func TestMigrateAndRollback(t *testing.T) {
+	ctx := context.Background()
+	ddb := newFakeDynamoDB()
+
+	var log []string
+	migrator := New(ddb, []Migration{
+		{Version: 2, Name: "second", Steps: []Step{recordStep(&log, "2a"), recordStep(&log, "2b")}},
+		{Version: 1, Name: "first", Steps: []Step{recordStep(&log, "1")}},
+	})
+
+	if err := migrator.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"up 1", "up 2a", "up 2b"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("log = %q, want %q", log, want)
+	}
+	// The lock is released
+	if want := []string{"1", "2"}; !reflect.DeepEqual(ddb.versions(), want) {
+		t.Errorf("versions = %q, want %q", ddb.versions(), want)
+	}
+
+	// Applied migrations are not run again
+	log = nil
+	if err := migrator.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(log) != 0 {
+		t.Errorf("log = %q, want nothing", log)
+	}
+
+	if err := migrator.Rollback(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"down 2b", "down 2a"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("log = %q, want %q", log, want)
+	}
+	if want := []string{"1"}; !reflect.DeepEqual(ddb.versions(), want) {
+		t.Errorf("versions = %q, want %q", ddb.versions(), want)
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 || !statuses[0].Applied || statuses[1].Applied {
+		t.Errorf("statuses = %v", statuses)
+	}
+}
+
+func TestRollbackNotReversible(t *testing.T) {
+	ctx := context.Background()
+	ddb := newFakeDynamoDB()
+
+	var log []string
+	irreversible := recordStep(&log, "2b")
+	irreversible.Down = nil
+	migrator := New(ddb, []Migration{
+		{Version: 1, Name: "first", Steps: []Step{recordStep(&log, "1")}},
+		{Version: 2, Name: "second", Steps: []Step{recordStep(&log, "2a"), irreversible}},
+	})
+	if err := migrator.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	log = nil
+	if err := migrator.Rollback(ctx, 2); !errors.Is(err, ErrNotReversible) {
+		t.Fatalf("Rollback() error = %v, want ErrNotReversible", err)
+	}
+	// No step of the migration is reverted
+	if len(log) != 0 {
+		t.Errorf("log = %q, want nothing", log)
+	}
+	if want := []string{"1", "2"}; !reflect.DeepEqual(ddb.versions(), want) {
+		t.Errorf("versions = %q, want %q", ddb.versions(), want)
+	}
+}
+
+func TestMigrateFailure(t *testing.T) {
+	ctx := context.Background()
+	ddb := newFakeDynamoDB()
+
+	failure := errors.New("boom")
+	migrator := New(ddb, []Migration{
+		{Version: 1, Name: "first", Steps: []Step{{Up: func(context.Context, aws.DynamoDB) error { return nil }}}},
+		{Version: 2, Name: "second", Steps: []Step{{Up: func(context.Context, aws.DynamoDB) error { return failure }}}},
+	})
+
+	err := migrator.Migrate(ctx)
+	if !errors.Is(err, ErrMigration) || !errors.Is(err, failure) {
+		t.Fatalf("Migrate() error = %v, want ErrMigration wrapping the step error", err)
+	}
+	// The failed migration stays pending and the lock is released
+	if want := []string{"1"}; !reflect.DeepEqual(ddb.versions(), want) {
+		t.Errorf("versions = %q, want %q", ddb.versions(), want)
+	}
+}
+
+func TestLocked(t *testing.T) {
+	ctx := context.Background()
+	ddb := newFakeDynamoDB()
+
+	ran := false
+	migrator := New(ddb, []Migration{{
+		Version: 1,
+		Name:    "first",
+		Steps: []Step{{Up: func(context.Context, aws.DynamoDB) error {
+			ran = true
+			return nil
+		}}},
+	}})
+
+	// Another run holds the lock
+	if _, err := ddb.Put(ctx, defaultStateTable, state{Version: lockVersion, Name: "lock"}); err != nil {
+		t.Fatal(err)
+	}
+	ddb.exists = true
+
+	if err := migrator.Migrate(ctx); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Migrate() error = %v, want ErrLocked", err)
+	}
+	if err := migrator.Rollback(ctx, 1); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Rollback() error = %v, want ErrLocked", err)
+	}
+	if ran {
+		t.Error("migration ran while locked")
+	}
+
+	if err := migrator.Unlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !ran {
+		t.Error("migration didn't run after unlock")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	ctx := context.Background()
+
+	err := New(newFakeDynamoDB(), []Migration{{Version: 0, Name: "zero"}}).Migrate(ctx)
+	if !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("Migrate() error = %v, want ErrInvalidVersion", err)
+	}
+
+	err = New(newFakeDynamoDB(), []Migration{{Version: 1, Name: "a"}, {Version: 1, Name: "b"}}).Migrate(ctx)
+	if !errors.Is(err, ErrDuplicateVersion) {
+		t.Errorf("Migrate() error = %v, want ErrDuplicateVersion", err)
+	}
+}
@@ -0,0 +1,149 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+
+	"github.com/ricomonster/hephaestus/aws"
+)
+
+// BackfillFunc returns the update to apply to an item, or nil to leave it as
+// is.
+type BackfillFunc func(ctx context.Context, item aws.Item) (*aws.Update, error)
+
+// CreateTable creates the table of the schema and waits for it to be ACTIVE.
+// Down deletes the table.
+func CreateTable(schema aws.TableSchema) Step {
+	return Step{
+		Up: func(ctx context.Context, ddb aws.DynamoDB) error {
+			if _, err := ddb.CreateTableFromSchema(ctx, schema); err != nil {
+				return err
+			}
+			_, err := ddb.WaitForTableActive(ctx, schema.Table)
+			return err
+		},
+		Down: func(ctx context.Context, ddb aws.DynamoDB) error {
+			return ddb.DeleteTable(ctx, schema.Table)
+		},
+	}
+}
+
+// AddGlobalIndex adds a GSI to the table and waits for it to be ACTIVE, which
+// can take a while on large tables. Down deletes the index.
+func AddGlobalIndex(table string, index aws.GlobalIndex) Step {
+	return Step{
+		Up: func(ctx context.Context, ddb aws.DynamoDB) error {
+			_, err := ddb.UpdateTable(ctx, aws.UpdateTableOptions{
+				Table:         table,
+				CreateIndexes: []aws.GlobalIndex{index},
+				Wait:          true,
+			})
+			return err
+		},
+		Down: func(ctx context.Context, ddb aws.DynamoDB) error {
+			_, err := ddb.UpdateTable(ctx, aws.UpdateTableOptions{
+				Table:         table,
+				DeleteIndexes: []string{index.Name},
+				Wait:          true,
+			})
+			return err
+		},
+	}
+}
+
+// DeleteGlobalIndex removes a GSI from the table. It cannot be reverted since
+// the index definition is not known.
+func DeleteGlobalIndex(table, index string) Step {
+	return Step{
+		Up: func(ctx context.Context, ddb aws.DynamoDB) error {
+			_, err := ddb.UpdateTable(ctx, aws.UpdateTableOptions{
+				Table:         table,
+				DeleteIndexes: []string{index},
+				Wait:          true,
+			})
+			return err
+		},
+	}
+}
+
+// Backfill scans the table and applies the update returned by fn to each
+// item. Items deleted during the scan are skipped rather than recreated.
+// Updates are not reverted, so Down is nil unless set by the caller.
+func Backfill(table string, fn BackfillFunc) Step {
+	return Step{
+		Up: func(ctx context.Context, ddb aws.DynamoDB) error {
+			description, err := ddb.DescribeTable(ctx, table)
+			if err != nil {
+				return err
+			}
+
+			_, err = ddb.ParallelScan(ctx, aws.ParallelScanOptions{
+				ScanOptions: aws.ScanOptions{Table: table},
+				OnItems: func(_ int32, items []aws.Item) error {
+					for _, item := range items {
+						update, err := fn(ctx, item)
+						if err != nil {
+							return err
+						}
+						if update == nil || len(update.Actions) == 0 {
+							continue
+						}
+
+						key, err := itemKey(item, description)
+						if err != nil {
+							return err
+						}
+
+						_, err = ddb.Update(ctx, aws.UpdateOptions{
+							Table:  table,
+							Key:    key,
+							Update: update,
+							Condition: &aws.Where{Conditions: []aws.WhereCondition{{
+								Field:    description.PartitionKey.Name,
+								Operator: aws.AttributeExists,
+							}}},
+						})
+						if errors.Is(err, aws.DynamoDBErrConditionFailed) {
+							continue
+						}
+						if err != nil {
+							return err
+						}
+					}
+					return nil
+				},
+			})
+			return err
+		},
+	}
+}
+
+// itemKey returns the key of the item, keeping numbers exact.
+func itemKey(item aws.Item, description *aws.TableDescription) (aws.Key, error) {
+	names := []string{description.PartitionKey.Name}
+	if description.SortKey != nil {
+		names = append(names, description.SortKey.Name)
+	}
+
+	key := aws.Key{}
+	for _, name := range names {
+		av, ok := item[name]
+		if !ok {
+			return nil, fmt.Errorf("item has no %s key", name)
+		}
+
+		var value any
+		err := attributevalue.UnmarshalWithOptions(av, &value, func(o *attributevalue.DecoderOptions) {
+			o.UseNumber = true
+		})
+		if err != nil {
+			return nil, err
+		}
+		key[name] = value
+	}
+
+	return key, nil
+}